@@ -0,0 +1,582 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// availableCategories mirrors the specialities used across the other
+// generators so tasks categorized here join cleanly with agent data.
+var availableCategories = []string{
+	"ApplicationLogic",
+	"ServerSideLogic",
+	"BugFixes",
+	"UI/UX",
+	"SystemWideQualityAndReliability",
+	"NewFeaturesOrEnhancements",
+	"ReliabilityImprovements",
+}
+
+var categorySkills = map[string][]string{
+	"ApplicationLogic":                {"state-management", "routing", "forms"},
+	"ServerSideLogic":                 {"api-design", "databases", "auth"},
+	"BugFixes":                        {"debugging", "regression-testing"},
+	"UI/UX":                           {"layout", "interaction-design", "accessibility"},
+	"SystemWideQualityAndReliability": {"refactoring", "performance"},
+	"NewFeaturesOrEnhancements":       {"feature-design", "api-design"},
+	"ReliabilityImprovements":         {"logging", "monitoring", "testing"},
+}
+
+var categoryKeywords = map[string][]string{
+	"ApplicationLogic":                {"form", "route", "navigation", "state"},
+	"ServerSideLogic":                 {"api", "endpoint", "database", "query", "auth"},
+	"BugFixes":                        {"bug", "error", "crash", "doesn't", "incorrect"},
+	"UI/UX":                           {"layout", "style", "color", "overlay", "tooltip", "ui", "ux"},
+	"SystemWideQualityAndReliability": {"refactor", "performance", "optimi"},
+	"NewFeaturesOrEnhancements":       {"add", "feature", "enhancement", "support"},
+	"ReliabilityImprovements":         {"log", "monitor", "test", "limit"},
+}
+
+var strongSignals = map[string][]string{
+	"BugFixes": {"doesn't work", "reverts to", "should not"},
+	"UI/UX":    {"background color", "displays the", "overlay"},
+}
+
+// Task is a single row extracted and cleaned from the task corpus CSV.
+type Task struct {
+	ID         string   `json:"id"`
+	Variant    string   `json:"variant"`
+	Price      float64  `json:"price"`
+	PriceLimit float64  `json:"price_limit"`
+	Desc       string   `json:"desc"`
+	Categories []string `json:"categories"`
+	Skills     []string `json:"skills"`
+}
+
+// defaultContentField is the dict key extractTaskContent looks for when the
+// caller doesn't override it via -content-field.
+const defaultContentField = "content"
+
+// contentFieldRegexes builds the fallback regexes for field, matching both
+// single- and double-quoted Python-repr dict syntax, e.g.
+// "'content': 'text'" or "\"content\": \"text\"".
+func contentFieldRegexes(field string) []*regexp.Regexp {
+	quoted := regexp.QuoteMeta(field)
+	return []*regexp.Regexp{
+		regexp.MustCompile(`'` + quoted + `':\s*'((?:[^'\\]|\\.)*)'`),
+		regexp.MustCompile(`"` + quoted + `":\s*"((?:[^"\\]|\\.)*)"`),
+	}
+}
+
+// extractContentFieldJSON attempts to parse raw as a genuine JSON object or
+// array of objects (as opposed to the Python-repr syntax the regex fallback
+// handles) and returns the string value of field if found.
+func extractContentFieldJSON(raw, field string) (string, bool) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return "", false
+	}
+
+	var asList []map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmed), &asList); err == nil {
+		for _, m := range asList {
+			if s, ok := m[field].(string); ok {
+				return s, true
+			}
+		}
+		return "", false
+	}
+
+	var asObj map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmed), &asObj); err == nil {
+		if s, ok := asObj[field].(string); ok {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// extractTaskContent pulls the human-readable issue description out of the
+// CSV's prompt column, e.g. "[{'content': 'You are an expert ...'}]". It
+// tries a real JSON parse first, since that handles nested quotes and
+// multiline content correctly, then falls back to regexes built for field,
+// then to the raw string when field isn't found anywhere.
+func extractTaskContent(raw, field string) string {
+	if content, ok := extractContentFieldJSON(raw, field); ok {
+		return cleanDescription(content)
+	}
+	for _, re := range contentFieldRegexes(field) {
+		if m := re.FindStringSubmatch(raw); m != nil {
+			return cleanDescription(m[1])
+		}
+	}
+	return cleanDescription(raw)
+}
+
+func cleanDescription(s string) string {
+	s = strings.ReplaceAll(s, `\n`, " ")
+	s = strings.ReplaceAll(s, `\r`, " ")
+	s = strings.ReplaceAll(s, `\'`, "'")
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "\r", " ")
+	return strings.TrimSpace(s)
+}
+
+// findClosestCategories returns the categories whose keywords best match desc,
+// ranked by number of keyword hits, most relevant first.
+func findClosestCategories(desc string) []string {
+	lower := strings.ToLower(desc)
+	type scored struct {
+		category string
+		score    int
+	}
+	var scores []scored
+	for _, category := range availableCategories {
+		score := 0
+		for _, kw := range categoryKeywords[category] {
+			if strings.Contains(lower, kw) {
+				score++
+			}
+		}
+		if score > 0 {
+			scores = append(scores, scored{category, score})
+		}
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].score > scores[j].score })
+	categories := make([]string, 0, len(scores))
+	for _, s := range scores {
+		categories = append(categories, s.category)
+	}
+	return categories
+}
+
+// hasStrongKeywordMatch reports whether desc contains one of category's
+// high-confidence phrases, as opposed to a loose single-keyword match.
+func hasStrongKeywordMatch(desc, category string) bool {
+	lower := strings.ToLower(desc)
+	for _, signal := range strongSignals[category] {
+		if strings.Contains(lower, signal) {
+			return true
+		}
+	}
+	return false
+}
+
+// categoryDefinition describes one category's taxonomy entry as loaded from
+// a -categories JSON file: its id (used as the internal key, matching an
+// availableCategories entry), a human-readable name, the skills it grants,
+// the loose keywords findClosestCategories scores on, and the optional
+// high-confidence phrases hasStrongKeywordMatch looks for.
+type categoryDefinition struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name,omitempty"`
+	Skills        []string `json:"skills"`
+	Keywords      []string `json:"keywords"`
+	StrongSignals []string `json:"strong_signals,omitempty"`
+}
+
+// loadCategoryDefinitions replaces availableCategories, categorySkills,
+// categoryKeywords, and strongSignals with the taxonomy read from path, a
+// JSON array of categoryDefinition. Called only when -categories is
+// non-empty; the built-in defaults declared above are left untouched
+// otherwise.
+func loadCategoryDefinitions(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading categories file %s: %w", path, err)
+	}
+	var defs []categoryDefinition
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return fmt.Errorf("parsing categories file %s: %w", path, err)
+	}
+
+	categories := make([]string, 0, len(defs))
+	skills := make(map[string][]string, len(defs))
+	keywords := make(map[string][]string, len(defs))
+	signals := make(map[string][]string, len(defs))
+	for _, d := range defs {
+		if d.ID == "" {
+			return fmt.Errorf("category definition missing required %q field", "id")
+		}
+		categories = append(categories, d.ID)
+		skills[d.ID] = d.Skills
+		keywords[d.ID] = d.Keywords
+		if len(d.StrongSignals) > 0 {
+			signals[d.ID] = d.StrongSignals
+		}
+	}
+
+	availableCategories = categories
+	categorySkills = skills
+	categoryKeywords = keywords
+	strongSignals = signals
+	return nil
+}
+
+func getSkillsForTask(categories []string) []string {
+	seen := make(map[string]bool)
+	var skills []string
+	for _, c := range categories {
+		for _, s := range categorySkills[c] {
+			if !seen[s] {
+				seen[s] = true
+				skills = append(skills, s)
+			}
+		}
+	}
+	return skills
+}
+
+// parseMagnitude parses a single number that may carry a "$" prefix,
+// thousands-separator commas, and a trailing "k"/"K" or "m"/"M" magnitude
+// suffix (multiplying by 1e3 or 1e6 respectively).
+func parseMagnitude(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "$")
+	s = strings.ReplaceAll(s, ",", "")
+
+	multiplier := 1.0
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'k', 'K':
+			multiplier = 1e3
+			s = s[:n-1]
+		case 'm', 'M':
+			multiplier = 1e6
+			s = s[:n-1]
+		}
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, err
+	}
+	return v * multiplier, nil
+}
+
+// parsePrice parses a price string that may be a plain number, carry a "$"
+// prefix, thousands-separator commas, a "k"/"M" magnitude suffix, or be a
+// "low-high" range - parsed as the midpoint of its two (independently
+// magnitude-suffixed) bounds. A value that can't be parsed by any of these
+// falls back to def, with the unparseable value logged so a bad CSV row
+// doesn't silently turn into 0.
+func parsePrice(s string, def float64) float64 {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def
+	}
+	if before, after, ok := strings.Cut(s, "-"); ok {
+		if lo, errLo := parseMagnitude(before); errLo == nil {
+			if hi, errHi := parseMagnitude(after); errHi == nil {
+				return (lo + hi) / 2
+			}
+		}
+	}
+	v, err := parseMagnitude(s)
+	if err != nil {
+		log.Printf("[data_gen] Unparseable price %q, falling back to %v", s, def)
+		return def
+	}
+	return v
+}
+
+// maxResponseBytes caps how much of a remote -input response openInput will
+// buffer, set from -max-response-bytes in main. data_gen.go has no LLM
+// caller of its own, but a misbehaving remote CSV source is the same
+// unbounded-buffering risk, so it gets the same cap.
+var maxResponseBytes int64 = 1 << 20 // 1MB
+
+// readLimitedBody reads up to maxBytes+1 bytes from body so callers can
+// detect truncation: a returned slice longer than maxBytes means the true
+// response exceeded the cap and was cut short.
+func readLimitedBody(body io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("response body exceeded the %d byte limit", maxBytes)
+	}
+	return data, nil
+}
+
+// openInput returns a reader over path, which may be a local file path or an
+// http(s):// URL. Remote sources are fetched with timeout as the client
+// timeout; a non-200 response, or one exceeding maxResponseBytes, is
+// reported as an error.
+func openInput(path string, timeout time.Duration) (io.ReadCloser, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return os.Open(path)
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(path)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", path, resp.Status)
+	}
+	body, err := readLimitedBody(resp.Body, maxResponseBytes)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", path, err)
+	}
+	return io.NopCloser(bytes.NewReader(body)), nil
+}
+
+// diagnoseNoTasks builds a diagnostic message for when no rows matched the
+// "swe_manager" variant filter, so a column-mapping mistake (e.g. the
+// variant column landing somewhere unexpected) doesn't look identical to
+// genuinely empty data: it names the detected header, which column was used
+// for variant matching, and the counts of each variant value actually seen.
+func diagnoseNoTasks(header []string, variantCol int, order []string, counts map[string]int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "no tasks matched variant %q; detected columns: %s", "swe_manager", strings.Join(header, ", "))
+	if variantCol == -1 {
+		b.WriteString(`; no "variant" column was found in the header`)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "; variant column %q (index %d) saw: ", header[variantCol], variantCol)
+	parts := make([]string, 0, len(order))
+	for _, v := range order {
+		parts = append(parts, fmt.Sprintf("%q=%d", v, counts[v]))
+	}
+	b.WriteString(strings.Join(parts, ", "))
+	return b.String()
+}
+
+// readTasksFromCSV reads the SWE-manager task corpus, extracting the cleaned
+// description and skipping those shorter than minDescLen characters. path may
+// be a local file path or an http(s):// URL, in which case timeout bounds the
+// fetch. contentField names the dict key extractTaskContent looks for in the
+// prompt column.
+func readTasksFromCSV(path string, minDescLen int, timeout time.Duration, contentField string, delimiter rune) ([]Task, int, error) {
+	f, err := openInput(path, timeout)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, 0, fmt.Errorf("input CSV is empty")
+		}
+		return nil, 0, err
+	}
+	col := func(name string) int {
+		for i, h := range header {
+			if h == name {
+				return i
+			}
+		}
+		return -1
+	}
+	idCol, variantCol, priceCol, priceLimitCol, promptCol := col("question_id"), col("variant"), col("price"), col("price_limit"), col("prompt")
+	requiredCols := 0
+	for _, c := range []int{idCol, variantCol, priceCol, priceLimitCol, promptCol} {
+		if c+1 > requiredCols {
+			requiredCols = c + 1
+		}
+	}
+
+	var tasks []Task
+	skippedShort := 0
+	skippedMalformed := 0
+	variantCounts := make(map[string]int)
+	var variantOrder []string
+	for rowNum := 2; ; rowNum++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if len(record) < requiredCols {
+			skippedMalformed++
+			log.Printf("[data_gen] Skipping malformed row %d: expected at least %d column(s), got %d", rowNum, requiredCols, len(record))
+			continue
+		}
+		if variantCol != -1 {
+			v := record[variantCol]
+			if _, ok := variantCounts[v]; !ok {
+				variantOrder = append(variantOrder, v)
+			}
+			variantCounts[v]++
+			if v != "swe_manager" {
+				continue
+			}
+		}
+		desc := ""
+		if promptCol != -1 {
+			desc = extractTaskContent(record[promptCol], contentField)
+		}
+		if len(desc) < minDescLen {
+			skippedShort++
+			continue
+		}
+		task := Task{Desc: desc}
+		if idCol != -1 {
+			task.ID = record[idCol]
+		}
+		if variantCol != -1 {
+			task.Variant = record[variantCol]
+		}
+		if priceCol != -1 {
+			task.Price = parsePrice(record[priceCol], 0)
+		}
+		if priceLimitCol != -1 {
+			task.PriceLimit = parsePrice(record[priceLimitCol], 0)
+		}
+		task.Categories = findClosestCategories(task.Desc)
+		task.Skills = getSkillsForTask(task.Categories)
+		tasks = append(tasks, task)
+	}
+	if skippedShort > 0 {
+		log.Printf("[data_gen] Skipped %d task(s) with description shorter than %d characters", skippedShort, minDescLen)
+	}
+	if skippedMalformed > 0 {
+		log.Printf("[data_gen] Skipped %d malformed row(s) with too few columns", skippedMalformed)
+	}
+	if len(tasks) == 0 {
+		return nil, skippedShort, fmt.Errorf("%s", diagnoseNoTasks(header, variantCol, variantOrder, variantCounts))
+	}
+	return tasks, skippedShort, nil
+}
+
+// writeJSONAtomic marshals v as indented JSON and writes it to path via a
+// temp-file-then-rename so a crash or write failure mid-write can't leave a
+// truncated file at path for a later tool to choke on: path either has its
+// old complete contents or its new complete contents, never a partial write.
+func writeJSONAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// verbosef logs a per-task progress line via log.Printf unless quiet
+// suppresses it; startup config, errors, and the final summary always log.
+func verbosef(quiet bool, format string, args ...interface{}) {
+	if quiet {
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// configurableFlagNames lists the -config-loadable flag names for this
+// program, i.e. every flag except -config itself. -summary-only is omitted
+// since it is only an alias for -quiet.
+var configurableFlagNames = []string{"input", "output", "min-desc-len", "input-timeout", "max-response-bytes", "content-field", "delimiter", "categories", "quiet"}
+
+// applyConfigFile reads a JSON object at path mapping flag names (as they
+// appear on the command line, without the leading "-") to values, and
+// applies them via flag.Set to any flag in configurableFlagNames that was
+// not already set explicitly on the command line - so an explicit
+// command-line flag always overrides the config file, regardless of the
+// order the two are given in. Only JSON is supported: no YAML library is
+// vendored in this module.
+func applyConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file %s: %w", path, err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for _, name := range configurableFlagNames {
+		value, ok := raw[name]
+		if !ok || explicit[name] {
+			continue
+		}
+		if err := flag.Set(name, fmt.Sprintf("%v", value)); err != nil {
+			return fmt.Errorf("applying config value for %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func main() {
+	inputFile := flag.String("input", "./data/data.csv", "Input CSV file")
+	outputDir := flag.String("output", "./data/categorized", "Output directory for categorized task JSON files")
+	minDescLen := flag.Int("min-desc-len", 0, "Skip tasks whose cleaned description is shorter than this many characters")
+	inputTimeout := flag.Duration("input-timeout", 30*time.Second, "Timeout for fetching -input when it is an http(s):// URL")
+	maxRespBytes := flag.Int64("max-response-bytes", maxResponseBytes, "Maximum bytes of a remote -input response to buffer before treating it as a failure")
+	contentField := flag.String("content-field", defaultContentField, "Dict key to extract the task description from in the prompt column")
+	delimiter := flag.String("delimiter", ",", "Single-character field delimiter for -input, e.g. \";\" or a tab")
+	categoriesPath := flag.String("categories", "", "Path to a JSON file defining a custom category taxonomy (id, name, skills, keywords, strong_signals); falls back to the built-in defaults when empty")
+	configPath := flag.String("config", "", "Path to a JSON config file mapping flag names to values; explicit command-line flags override it")
+	var quiet bool
+	flag.BoolVar(&quiet, "quiet", false, "Suppress per-task log lines, printing only startup config and the final summary")
+	flag.BoolVar(&quiet, "summary-only", false, "Alias for -quiet")
+	flag.Parse()
+
+	if *configPath != "" {
+		if err := applyConfigFile(*configPath); err != nil {
+			log.Fatalf("[data_gen] Failed to apply -config: %v", err)
+		}
+	}
+	maxResponseBytes = *maxRespBytes
+
+	delimiterRunes := []rune(*delimiter)
+	if len(delimiterRunes) != 1 {
+		log.Fatalf("[data_gen] Invalid -delimiter %q: must be exactly one character", *delimiter)
+	}
+
+	if *categoriesPath != "" {
+		if err := loadCategoryDefinitions(*categoriesPath); err != nil {
+			log.Fatalf("[data_gen] Failed to load -categories: %v", err)
+		}
+	}
+
+	log.Printf("[data_gen] Starting with input=%s output=%s min-desc-len=%d input-timeout=%s max-response-bytes=%d content-field=%q delimiter=%q categories=%q quiet=%v config=%q",
+		*inputFile, *outputDir, *minDescLen, *inputTimeout, *maxRespBytes, *contentField, *delimiter, *categoriesPath, quiet, *configPath)
+
+	tasks, skipped, err := readTasksFromCSV(*inputFile, *minDescLen, *inputTimeout, *contentField, delimiterRunes[0])
+	if err != nil {
+		log.Fatalf("[data_gen] Failed to read CSV: %v", err)
+	}
+
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Fatalf("[data_gen] Failed to create output directory: %v", err)
+	}
+	for _, task := range tasks {
+		outfile := fmt.Sprintf("%s/%s.json", *outputDir, task.ID)
+		if err := writeJSONAtomic(outfile, task); err != nil {
+			log.Printf("[data_gen] Failed to write %s: %v", outfile, err)
+			continue
+		}
+		verbosef(quiet, "[data_gen] Wrote %s", outfile)
+	}
+	log.Printf("[data_gen] Categorized %d task(s), skipped %d short description(s), written to %s", len(tasks), skipped, *outputDir)
+}
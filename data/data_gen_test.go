@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestCSV(t *testing.T, rows [][]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.csv")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create test CSV: %v", err)
+	}
+	defer f.Close()
+	for _, row := range rows {
+		for i, field := range row {
+			if i > 0 {
+				f.WriteString(",")
+			}
+			f.WriteString(`"` + field + `"`)
+		}
+		f.WriteString("\n")
+	}
+	return path
+}
+
+func TestReadTasksFromCSVFiltersShortDescriptions(t *testing.T) {
+	path := writeTestCSV(t, [][]string{
+		{"question_id", "variant", "price", "price_limit", "prompt"},
+		{"t1", "swe_manager", "100", "200", "ok"},
+		{"t2", "swe_manager", "100", "200", "This is a sufficiently long description of the bug."},
+	})
+
+	tasks, skipped, err := readTasksFromCSV(path, 20, 5*time.Second, defaultContentField, ',')
+	if err != nil {
+		t.Fatalf("readTasksFromCSV failed: %v", err)
+	}
+	if skipped != 1 {
+		t.Fatalf("expected 1 skipped task, got %d", skipped)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "t2" {
+		t.Fatalf("expected only t2 to survive filtering, got %+v", tasks)
+	}
+}
+
+func TestReadTasksFromCSVHandlesSemicolonDelimiterAndQuotedCommas(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.csv")
+	content := "question_id;variant;price;price_limit;prompt\n" +
+		`t1;swe_manager;100;200;"A description, with an embedded comma that should stay in one field."` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+
+	tasks, skipped, err := readTasksFromCSV(path, 0, 5*time.Second, defaultContentField, ';')
+	if err != nil {
+		t.Fatalf("readTasksFromCSV failed: %v", err)
+	}
+	if skipped != 0 {
+		t.Fatalf("expected no skipped tasks, got %d", skipped)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "t1" {
+		t.Fatalf("expected one task t1, got %+v", tasks)
+	}
+	if !strings.Contains(tasks[0].Desc, "embedded comma") {
+		t.Fatalf("expected the quoted comma to stay inside the prompt field, got desc %q", tasks[0].Desc)
+	}
+}
+
+func TestReadTasksFromCSVSkipsRaggedRowsWithAWarning(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.csv")
+	content := "question_id,variant,price,price_limit,prompt\n" +
+		"t1,swe_manager,100,200\n" + // missing the prompt column
+		`t2,swe_manager,100,200,"This is a sufficiently long description of the bug."` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+
+	tasks, _, err := readTasksFromCSV(path, 0, 5*time.Second, defaultContentField, ',')
+	if err != nil {
+		t.Fatalf("readTasksFromCSV failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "t2" {
+		t.Fatalf("expected the ragged row to be skipped and only t2 to survive, got %+v", tasks)
+	}
+}
+
+func TestParsePriceHandlesMagnitudeSuffixesAndRanges(t *testing.T) {
+	cases := []struct {
+		input string
+		want  float64
+	}{
+		{"1.5k", 1500},
+		{"2M", 2000000},
+		{"1000-2000", 1500},
+		{"$1,000", 1000},
+		{"100", 100},
+	}
+	for _, c := range cases {
+		if got := parsePrice(c.input, -1); got != c.want {
+			t.Errorf("parsePrice(%q) = %v, want %v", c.input, got, c.want)
+		}
+	}
+}
+
+func TestParsePriceFallsBackToDefaultOnGarbageInput(t *testing.T) {
+	if got := parsePrice("not-a-price-at-all", 42); got != 42 {
+		t.Fatalf("expected garbage input to fall back to the default 42, got %v", got)
+	}
+}
+
+func TestReadTasksFromCSVOverHTTP(t *testing.T) {
+	const csv = "question_id,variant,price,price_limit,prompt\n" +
+		"t1,swe_manager,100,200,\"This is a sufficiently long description of the bug.\"\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(csv))
+	}))
+	defer server.Close()
+
+	tasks, skipped, err := readTasksFromCSV(server.URL, 0, 5*time.Second, defaultContentField, ',')
+	if err != nil {
+		t.Fatalf("readTasksFromCSV over HTTP failed: %v", err)
+	}
+	if skipped != 0 {
+		t.Fatalf("expected no skipped tasks, got %d", skipped)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "t1" {
+		t.Fatalf("expected t1 to be read over HTTP, got %+v", tasks)
+	}
+}
+
+func TestOpenInputFailsOnOversizedRemoteResponse(t *testing.T) {
+	orig := maxResponseBytes
+	maxResponseBytes = 16
+	defer func() { maxResponseBytes = orig }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 1000)))
+	}))
+	defer server.Close()
+
+	if _, err := openInput(server.URL, 5*time.Second); err == nil {
+		t.Fatal("expected openInput to fail on an oversized response, got nil")
+	}
+}
+
+func TestReadTasksFromCSVOverHTTPNon200(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if _, _, err := readTasksFromCSV(server.URL, 0, 5*time.Second, defaultContentField, ','); err == nil {
+		t.Fatal("expected an error for a non-200 response, got nil")
+	}
+}
+
+func TestReadTasksFromCSVReportsDiagnosticsWhenVariantColumnIsUnexpected(t *testing.T) {
+	// "variant" is the last column here instead of the usual second column,
+	// and its values never match "swe_manager", so every row is filtered out.
+	path := writeTestCSV(t, [][]string{
+		{"question_id", "prompt", "price", "price_limit", "variant"},
+		{"t1", "This is a sufficiently long description of the bug.", "100", "200", "other_variant"},
+	})
+
+	_, _, err := readTasksFromCSV(path, 0, 5*time.Second, defaultContentField, ',')
+	if err == nil {
+		t.Fatal("expected an error when no tasks match the variant filter, got nil")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, `question_id, prompt, price, price_limit, variant`) {
+		t.Fatalf("expected error to list the detected columns, got: %v", msg)
+	}
+	if !strings.Contains(msg, `"variant" (index 4)`) {
+		t.Fatalf("expected error to name the variant column and its actual index, got: %v", msg)
+	}
+	if !strings.Contains(msg, `"other_variant"=1`) {
+		t.Fatalf("expected error to report the variant values actually seen, got: %v", msg)
+	}
+}
+
+func TestVerbosefSuppressesPerTaskLinesInQuietModeButSummaryStaysVisible(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(orig)
+
+	verbosef(true, "[data_gen] Wrote %s", "task1.json")
+	log.Printf("[data_gen] Categorized %d task(s), skipped %d short description(s)", 1, 0)
+
+	output := buf.String()
+	if strings.Contains(output, "Wrote task1.json") {
+		t.Fatalf("expected the per-task line to be suppressed in quiet mode, got: %s", output)
+	}
+	if !strings.Contains(output, "Categorized 1 task(s), skipped 0 short description(s)") {
+		t.Fatalf("expected the summary line to remain present in quiet mode, got: %s", output)
+	}
+}
+
+func TestVerbosefLogsWhenNotQuiet(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(orig)
+
+	verbosef(false, "[data_gen] Wrote %s", "task1.json")
+	if !strings.Contains(buf.String(), "Wrote task1.json") {
+		t.Fatalf("expected the per-task line to be logged when not quiet, got: %s", buf.String())
+	}
+}
+
+func TestWriteJSONAtomicWritesValidJSONAndLeavesNoTempFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task1.json")
+	if err := writeJSONAtomic(path, Task{ID: "task1", Desc: "fix bug"}); err != nil {
+		t.Fatalf("writeJSONAtomic failed: %v", err)
+	}
+
+	var decoded Task
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read written file: %v", err)
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode written file: %v", err)
+	}
+	if decoded.ID != "task1" || decoded.Desc != "fix bug" {
+		t.Fatalf("expected task1/fix bug, got %+v", decoded)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected no leftover .tmp file, got err=%v", err)
+	}
+}
+
+func TestApplyConfigFileAppliesValuesAndCommandLineFlagsOverride(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configJSON := `{"input": "./config-input.csv", "min-desc-len": 25, "quiet": true}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	oldCommandLine := flag.CommandLine
+	flag.CommandLine = flag.NewFlagSet("data_gen_test", flag.ContinueOnError)
+	defer func() { flag.CommandLine = oldCommandLine }()
+
+	inputFile := flag.String("input", "./data/data.csv", "")
+	minDescLen := flag.Int("min-desc-len", 0, "")
+	var quiet bool
+	flag.BoolVar(&quiet, "quiet", false, "")
+
+	// Simulate an explicit command-line flag alongside -config: it should win
+	// over the config file's value for the same setting.
+	if err := flag.CommandLine.Parse([]string{"-min-desc-len=50"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	if err := applyConfigFile(configPath); err != nil {
+		t.Fatalf("applyConfigFile returned error: %v", err)
+	}
+
+	if *inputFile != "./config-input.csv" {
+		t.Errorf("expected config value to be applied to -input, got %q", *inputFile)
+	}
+	if *minDescLen != 50 {
+		t.Errorf("expected explicit -min-desc-len=50 to override config value 25, got %d", *minDescLen)
+	}
+	if !quiet {
+		t.Errorf("expected config value to be applied to -quiet")
+	}
+}
+
+func TestLoadCategoryDefinitionsReplacesBuiltinTaxonomy(t *testing.T) {
+	origCategories, origSkills, origKeywords, origSignals := availableCategories, categorySkills, categoryKeywords, strongSignals
+	defer func() {
+		availableCategories, categorySkills, categoryKeywords, strongSignals = origCategories, origSkills, origKeywords, origSignals
+	}()
+
+	path := filepath.Join(t.TempDir(), "categories.json")
+	customJSON := `[
+		{"id": "Infra", "name": "Infrastructure", "skills": ["terraform", "networking"], "keywords": ["deploy", "cluster"], "strong_signals": ["cluster is down"]}
+	]`
+	if err := os.WriteFile(path, []byte(customJSON), 0644); err != nil {
+		t.Fatalf("failed to write test categories file: %v", err)
+	}
+
+	if err := loadCategoryDefinitions(path); err != nil {
+		t.Fatalf("loadCategoryDefinitions returned error: %v", err)
+	}
+
+	if got := findClosestCategories("please deploy the new cluster"); len(got) != 1 || got[0] != "Infra" {
+		t.Fatalf("expected the loaded taxonomy to classify into Infra, got %v", got)
+	}
+	if !hasStrongKeywordMatch("the cluster is down again", "Infra") {
+		t.Fatal("expected the loaded strong signal to match")
+	}
+	if got := getSkillsForTask([]string{"Infra"}); len(got) != 2 || got[0] != "terraform" || got[1] != "networking" {
+		t.Fatalf("expected the loaded skills for Infra, got %v", got)
+	}
+}
+
+func TestLoadCategoryDefinitionsRejectsEntryMissingID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "categories.json")
+	if err := os.WriteFile(path, []byte(`[{"skills": ["x"], "keywords": ["y"]}]`), 0644); err != nil {
+		t.Fatalf("failed to write test categories file: %v", err)
+	}
+	if err := loadCategoryDefinitions(path); err == nil {
+		t.Fatal("expected an error for a category definition missing its id")
+	}
+}
+
+func TestExtractTaskContent(t *testing.T) {
+	raw := `[{'content': 'Fix the login button'}]`
+	if got := extractTaskContent(raw, defaultContentField); got != "Fix the login button" {
+		t.Fatalf("expected extracted content, got %q", got)
+	}
+}
+
+func TestExtractTaskContentHandlesNestedQuotesViaRegexFallback(t *testing.T) {
+	raw := `[{'content': 'The user\'s "profile" page is broken'}]`
+	got := extractTaskContent(raw, defaultContentField)
+	if got != `The user's "profile" page is broken` {
+		t.Fatalf("expected nested quotes to survive extraction, got %q", got)
+	}
+}
+
+func TestExtractTaskContentPrefersGenuineJSONParse(t *testing.T) {
+	raw := `[{"role": "user", "content": "Fix the \"submit\" button on multiple\nlines"}]`
+	got := extractTaskContent(raw, defaultContentField)
+	if got != `Fix the "submit" button on multiple lines` {
+		t.Fatalf("expected JSON-parsed multiline content, got %q", got)
+	}
+}
+
+func TestExtractTaskContentFallsBackToRawStringForPlainText(t *testing.T) {
+	raw := "Just a plain description with no content field"
+	if got := extractTaskContent(raw, defaultContentField); got != raw {
+		t.Fatalf("expected plain text to pass through unchanged, got %q", got)
+	}
+}
+
+func TestExtractTaskContentRespectsConfigurableFieldName(t *testing.T) {
+	raw := `[{'message': 'Fix the login button'}]`
+	if got := extractTaskContent(raw, "message"); got != "Fix the login button" {
+		t.Fatalf("expected extraction to use the configured field name, got %q", got)
+	}
+	if got := extractTaskContent(raw, defaultContentField); got != raw {
+		t.Fatalf("expected default field name to miss and fall back to raw, got %q", got)
+	}
+}
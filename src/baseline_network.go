@@ -1,14 +1,20 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -18,12 +24,14 @@ type Speciality struct {
 }
 
 type AgentFile struct {
-	AgentID      string        `json:"agent_id"`
-	DisplayName  string        `json:"display_name"`
-	Group        string        `json:"group"`
-	Avatar       string        `json:"avatar"`
-	Specialities []Speciality  `json:"specialities"`
-	Tasks        []TaskSummary `json:"tasks"`
+	AgentID            string        `json:"agent_id"`
+	DisplayName        string        `json:"display_name"`
+	Group              string        `json:"group"`
+	Avatar             string        `json:"avatar"`
+	Specialities       []Speciality  `json:"specialities"`
+	Tasks              []TaskSummary `json:"tasks"`
+	MaxConcurrentTasks int           `json:"max_concurrent_tasks,omitempty"`
+	Budget             float64       `json:"budget,omitempty"`
 }
 
 type TaskSummary struct {
@@ -64,33 +72,291 @@ type Edge struct {
 	Reasoning string  `json:"reasoning,omitempty"`
 }
 
-// Load all agents from JSON files
+// lineAndColumn converts a byte offset into data into a 1-indexed line and
+// column, for reporting JSON errors with human-readable location context.
+func lineAndColumn(data []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// unmarshalJSONWithContext wraps json.Unmarshal errors with filename and, for
+// offset-carrying errors, line/column context, so callers don't have to
+// puzzle out a bare "invalid character ... at offset N".
+func unmarshalJSONWithContext(filename string, data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	line, col := lineAndColumn(data, offset)
+	return fmt.Errorf("%s:%d:%d: %w", filename, line, col, err)
+}
+
+// skipBadAgentFiles controls whether loadAgents skips and logs a malformed
+// agent file instead of aborting the whole load. Set from -skip-bad in
+// main().
+var skipBadAgentFiles = false
+
+// agentDupPolicy controls how loadAgents resolves two files declaring the
+// same agent_id. Set from -on-dup in main(); "error" is the default so a
+// copy-paste mistake fails loudly instead of silently double-counting.
+var agentDupPolicy = "error"
+
+// resolveAgentDuplicates applies agentDupPolicy to agents (in the order
+// loadAgents encountered their source files), returning the deduplicated,
+// still-unsorted list. Each conflicting agent_id is logged once regardless
+// of policy, so "first"/"last" runs still surface that a conflict occurred.
+func resolveAgentDuplicates(policy string, paths []string, agents []AgentFile) ([]AgentFile, error) {
+	indices := make(map[string][]int, len(agents))
+	for i, agent := range agents {
+		indices[agent.AgentID] = append(indices[agent.AgentID], i)
+	}
+
+	keep := make(map[int]bool, len(agents))
+	for id, idxs := range indices {
+		if len(idxs) == 1 {
+			keep[idxs[0]] = true
+			continue
+		}
+		conflictPaths := make([]string, len(idxs))
+		for i, idx := range idxs {
+			conflictPaths[i] = paths[idx]
+		}
+		log.Printf("[loadAgents] Duplicate agent_id %q declared in %v (-on-dup=%s)", id, conflictPaths, policy)
+		switch policy {
+		case "error":
+			return nil, fmt.Errorf("duplicate agent_id %q declared in %v", id, conflictPaths)
+		case "first":
+			keep[idxs[0]] = true
+		case "last":
+			keep[idxs[len(idxs)-1]] = true
+		default:
+			return nil, fmt.Errorf("unknown -on-dup policy %q, want \"error\", \"first\", or \"last\"", policy)
+		}
+	}
+
+	deduped := make([]AgentFile, 0, len(keep))
+	for i, agent := range agents {
+		if keep[i] {
+			deduped = append(deduped, agent)
+		}
+	}
+	return deduped, nil
+}
+
+// loadAgentWorkers bounds how many agent files loadAgents reads concurrently.
+const loadAgentWorkers = 8
+
+// agentLoadResult is one loadAgents worker's outcome for a single file.
+type agentLoadResult struct {
+	path  string
+	agent AgentFile
+	err   error
+}
+
+// Load all agents from JSON files, using a bounded pool of workers since
+// agent directories can hold thousands of files and each read is
+// I/O-bound. A malformed file aborts the whole load unless skipBadAgentFiles
+// is set, in which case it's logged and skipped instead. Regardless of the
+// order files complete in, the result is sorted by AgentID (which need not
+// match its filename) so processing order is deterministic.
 func loadAgents(folderPath string) ([]AgentFile, error) {
 	files, err := os.ReadDir(folderPath)
 	if err != nil {
 		return nil, err
 	}
 
-	var agents []AgentFile
+	var paths []string
 	for _, file := range files {
 		if strings.HasSuffix(file.Name(), ".json") {
-			path := fmt.Sprintf("%s/%s", folderPath, file.Name())
-			data, err := os.ReadFile(path)
-			if err != nil {
-				return nil, err
+			paths = append(paths, fmt.Sprintf("%s/%s", folderPath, file.Name()))
+		}
+	}
+
+	jobs := make(chan string)
+	results := make(chan agentLoadResult)
+
+	workers := loadAgentWorkers
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+	for w := 0; w < workers; w++ {
+		go func() {
+			for path := range jobs {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					results <- agentLoadResult{path: path, err: err}
+					continue
+				}
+				var agent AgentFile
+				if err := unmarshalJSONWithContext(path, data, &agent); err != nil {
+					results <- agentLoadResult{path: path, err: err}
+					continue
+				}
+				results <- agentLoadResult{path: path, agent: agent}
 			}
-			var agent AgentFile
-			if err := json.Unmarshal(data, &agent); err != nil {
-				return nil, err
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, path := range paths {
+			jobs <- path
+		}
+	}()
+
+	// Drain results for every path before returning, even once a fatal
+	// error is known: the worker pool's goroutines block forever on
+	// results <- ... (and the feeder blocks on jobs <-) if the consumer
+	// stops early, leaking one goroutine per undelivered path.
+	byPath := make(map[string]AgentFile, len(paths))
+	var firstErr error
+	for range paths {
+		res := <-results
+		if res.err != nil {
+			if skipBadAgentFiles {
+				log.Printf("[loadAgents] Skipping unreadable agent file %s: %v", res.path, res.err)
+				continue
+			}
+			if firstErr == nil {
+				firstErr = res.err
 			}
+			continue
+		}
+		byPath[res.path] = res.agent
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Rebuild in -input's directory-listing order (not arrival order, which
+	// is nondeterministic across the worker pool) so resolveAgentDuplicates'
+	// "first"/"last" policies are reproducible across runs.
+	orderedPaths := make([]string, 0, len(byPath))
+	agents := make([]AgentFile, 0, len(byPath))
+	for _, path := range paths {
+		if agent, ok := byPath[path]; ok {
+			orderedPaths = append(orderedPaths, path)
 			agents = append(agents, agent)
 		}
 	}
+
+	agents, err = resolveAgentDuplicates(agentDupPolicy, orderedPaths, agents)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(agents, func(i, j int) bool { return agents[i].AgentID < agents[j].AgentID })
 	return agents, nil
 }
 
-// Build network using pairwise agent interactions
-func buildNetworkFromPairs(agents []AgentFile, ollamaURL string) ([]Node, []Edge) {
+// agentLabel maps an AgentFile onto the display name shown for its Node,
+// bridging tools that populate DisplayName (swe_manager_task_distribution.go)
+// with older or hand-built agent files that only carry AgentID.
+func agentLabel(agent AgentFile) string {
+	if agent.DisplayName != "" {
+		return agent.DisplayName
+	}
+	return agent.AgentID
+}
+
+// agentPair is one unordered pair of agents to run through the LLM in
+// buildNetworkFromPairs.
+type agentPair struct {
+	a, b AgentFile
+}
+
+// checkpointPairKey identifies an agent pair in the checkpoint file. Agents
+// are always visited in loadAgents' sorted order, so a==agentA/b==agentB
+// consistently between a run and its resume.
+func checkpointPairKey(a, b AgentFile) string {
+	return a.AgentID + "-" + b.AgentID
+}
+
+// checkpointEntry is one line of a .checkpoint.jsonl file: the nodes/edges
+// parsed from a single completed agent pair's LLM response.
+type checkpointEntry struct {
+	Pair  string `json:"pair"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// loadCheckpoint reads a .checkpoint.jsonl file, one checkpointEntry per
+// line, into a map keyed by Pair. A missing file is not an error - it just
+// means there's nothing to resume from yet.
+func loadCheckpoint(path string) (map[string]checkpointEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries := make(map[string]checkpointEntry)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), int(maxResponseBytes))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry checkpointEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("parsing checkpoint line: %w", err)
+		}
+		entries[entry.Pair] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// appendCheckpoint appends entry as one JSON line to the checkpoint file at
+// path, creating it if necessary.
+func appendCheckpoint(path string, entry checkpointEntry) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Build network using pairwise agent interactions. Pairs are dispatched to
+// at most concurrency LLM calls at once (concurrency < 1 behaves as 1, i.e.
+// sequential); safeCallOllama's own retry/backoff still applies per call.
+// nodeSet/edgeSet dedup across pairs is guarded by mu since goroutines
+// populate nodes/edges concurrently; the caller (saveNetwork, via
+// sortNetwork) is responsible for making the final output byte-identical
+// regardless of the order pairs happened to complete in.
+//
+// When checkpointPath is non-empty, any already-completed pairs it names
+// are loaded and skipped (so an interrupted run can resume without
+// re-querying the LLM), and each newly-completed pair is appended to it as
+// it finishes. checkpointPath is expected to be "" (no checkpointing) when
+// the caller's -resume flag isn't set.
+func buildNetworkFromPairs(agents []AgentFile, ollamaURL, model string, concurrency int, checkpointPath string) ([]Node, []Edge, error) {
 	nodes := []Node{}
 	edges := []Edge{}
 	nodeSet := make(map[string]Node)
@@ -101,7 +367,7 @@ func buildNetworkFromPairs(agents []AgentFile, ollamaURL string) ([]Node, []Edge
 		n := Node{
 			ID:           agent.AgentID,
 			Type:         "agent",
-			Label:        agent.DisplayName,
+			Label:        agentLabel(agent),
 			Group:        agent.Group,
 			Avatar:       agent.Avatar,
 			Specialities: agent.Specialities,
@@ -110,20 +376,67 @@ func buildNetworkFromPairs(agents []AgentFile, ollamaURL string) ([]Node, []Edge
 		nodeSet[agent.AgentID] = n
 	}
 
-	// Agent pairs
+	completed := make(map[string]bool)
+	if checkpointPath != "" {
+		checkpoint, err := loadCheckpoint(checkpointPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading checkpoint %s: %w", checkpointPath, err)
+		}
+		for key, entry := range checkpoint {
+			completed[key] = true
+			for _, n := range entry.Nodes {
+				if _, exists := nodeSet[n.ID]; !exists {
+					nodes = append(nodes, n)
+					nodeSet[n.ID] = n
+				}
+			}
+			for _, e := range entry.Edges {
+				edgeKey := fmt.Sprintf("%s->%s:%s", e.Source, e.Target, e.Type)
+				if !edgeSet[edgeKey] {
+					edges = append(edges, e)
+					edgeSet[edgeKey] = true
+				}
+			}
+		}
+		if len(completed) > 0 {
+			log.Printf("Resuming from checkpoint %s: %d agent pair(s) already processed", checkpointPath, len(completed))
+		}
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var pairs []agentPair
 	for i := 0; i < len(agents); i++ {
 		for j := i + 1; j < len(agents); j++ {
-			agentA := agents[i]
-			agentB := agents[j]
+			if completed[checkpointPairKey(agents[i], agents[j])] {
+				continue
+			}
+			pairs = append(pairs, agentPair{a: agents[i], b: agents[j]})
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, p := range pairs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(agentA, agentB AgentFile) {
+			defer wg.Done()
+			defer func() { <-sem }()
 
 			prompt := createAgentPairPrompt(agentA, agentB)
-			response, err := safeCallOllama(prompt, ollamaURL)
+			response, err := safeCallOllama(prompt, ollamaURL, model)
 			if err != nil {
 				log.Printf("Skipping agent pair %s-%s due to LLM error: %v", agentA.AgentID, agentB.AgentID, err)
-				continue
+				return
 			}
-
 			newEdges, newNodes := parseLLMPairResponse(response)
+
+			mu.Lock()
+			defer mu.Unlock()
 			for _, n := range newNodes {
 				if _, exists := nodeSet[n.ID]; !exists {
 					nodes = append(nodes, n)
@@ -137,10 +450,17 @@ func buildNetworkFromPairs(agents []AgentFile, ollamaURL string) ([]Node, []Edge
 					edgeSet[key] = true
 				}
 			}
-		}
+			if checkpointPath != "" {
+				entry := checkpointEntry{Pair: checkpointPairKey(agentA, agentB), Nodes: newNodes, Edges: newEdges}
+				if err := appendCheckpoint(checkpointPath, entry); err != nil {
+					log.Printf("Failed to checkpoint agent pair %s-%s: %v", agentA.AgentID, agentB.AgentID, err)
+				}
+			}
+		}(p.a, p.b)
 	}
+	wg.Wait()
 
-	return nodes, edges
+	return nodes, edges, nil
 }
 
 // Create prompt for 2 agents
@@ -183,10 +503,71 @@ No extra text, only JSON!
 }
 
 // Parse LLM JSON response
+// extractJSONObject strips a leading/trailing markdown code fence (with or
+// without a "json" language tag) and any prose surrounding it, then returns
+// the outermost balanced {...} object found in s. Models frequently answer
+// with ```json ... ``` blocks or a leading sentence instead of bare JSON;
+// this normalizes both cases into the raw object text before it's unmarshaled.
+func extractJSONObject(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```")
+		s = strings.TrimPrefix(s, "json")
+		s = strings.TrimPrefix(s, "JSON")
+		if idx := strings.LastIndex(s, "```"); idx != -1 {
+			s = s[:idx]
+		}
+		s = strings.TrimSpace(s)
+	}
+
+	start := strings.IndexByte(s, '{')
+	if start == -1 {
+		return "", fmt.Errorf("no JSON object found in response")
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[start : i+1], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("unbalanced JSON object in response")
+}
+
 func parseLLMPairResponse(response string) ([]Edge, []Node) {
 	// First, log the raw LLM response
 	log.Println("LLM Raw Response: ", response)
 
+	jsonBody, err := extractJSONObject(response)
+	if err != nil {
+		log.Printf("Failed to extract JSON object from LLM response: %v", err)
+		log.Println("Response body (raw):", response)
+		return nil, nil
+	}
+
 	// First, unmarshal the raw string into a structure
 	var result struct {
 		Tasks []struct {
@@ -202,7 +583,7 @@ func parseLLMPairResponse(response string) ([]Edge, []Node) {
 	}
 
 	// First unmarshal the outer structure
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
+	if err := json.Unmarshal([]byte(jsonBody), &result); err != nil {
 		log.Printf("Failed to parse outer LLM JSON: %v", err)
 		log.Println("Response body (raw):", response) // Log the full response for debugging
 		return nil, nil
@@ -224,12 +605,12 @@ func parseLLMPairResponse(response string) ([]Edge, []Node) {
 }
 
 // LLM call wrapper with retry
-func safeCallOllama(prompt, ollamaURL string) (string, error) {
+func safeCallOllama(prompt, ollamaURL, model string) (string, error) {
 	const maxRetries = 3
 	var lastErr error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		response, err := callOllamaLLM(prompt, ollamaURL)
+		response, err := callOllamaLLM(prompt, ollamaURL, model)
 		if err == nil && strings.TrimSpace(response) != "" {
 			return response, nil
 		}
@@ -240,10 +621,30 @@ func safeCallOllama(prompt, ollamaURL string) (string, error) {
 	return "", fmt.Errorf("LLM call failed after %d attempts: %w", maxRetries, lastErr)
 }
 
+// maxResponseBytes caps how much of an LLM response body callOllamaLLM will
+// buffer, set from -max-response-bytes in main. A misbehaving model
+// returning more than this is treated as a failure rather than buffered in
+// full, so it flows into safeCallOllama's existing retry logic.
+var maxResponseBytes int64 = 1 << 20 // 1MB
+
+// readLimitedBody reads up to maxBytes+1 bytes from body so callers can
+// detect truncation: a returned slice longer than maxBytes means the true
+// response exceeded the cap and was cut short.
+func readLimitedBody(body io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("response body exceeded the %d byte limit", maxBytes)
+	}
+	return data, nil
+}
+
 // Actual call to Ollama server
-func callOllamaLLM(prompt, ollamaURL string) (string, error) {
+func callOllamaLLM(prompt, ollamaURL, model string) (string, error) {
 	payload := map[string]interface{}{
-		"model":  "cogito:8b",
+		"model":  model,
 		"prompt": prompt,
 		"stream": false,
 	}
@@ -265,7 +666,10 @@ func callOllamaLLM(prompt, ollamaURL string) (string, error) {
 	}
 	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	body, err := readLimitedBody(resp.Body, maxResponseBytes)
+	if err != nil {
+		return "", fmt.Errorf("reading Ollama response: %w", err)
+	}
 	log.Printf("Raw Response Body: %s", body) // Log the raw response
 
 	var response struct {
@@ -275,35 +679,501 @@ func callOllamaLLM(prompt, ollamaURL string) (string, error) {
 		return "", err
 	}
 
-	// Check if the response is in the expected format
-	if !strings.HasPrefix(strings.TrimSpace(response.Response), "{") {
+	// Check if the response is in the expected format, tolerating markdown
+	// code fences and leading prose around the JSON object.
+	jsonBody, err := extractJSONObject(response.Response)
+	if err != nil {
 		return "", fmt.Errorf("Unexpected LLM output: %s", response.Response)
 	}
 
-	return response.Response, nil
+	return jsonBody, nil
 }
 
-// Save network
-func saveNetwork(outputPath string, network Network) error {
-	data, err := json.MarshalIndent(network, "", "  ")
+// sortNetwork orders n's nodes by ID and edges by (source, target, type) in
+// place, so the same set of nodes and edges always serializes to the same
+// bytes regardless of the order the LLM pair-generation pass produced them in.
+func sortNetwork(n Network) {
+	sort.Slice(n.Nodes, func(i, j int) bool { return n.Nodes[i].ID < n.Nodes[j].ID })
+	sort.Slice(n.Edges, func(i, j int) bool {
+		a, b := n.Edges[i], n.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Type < b.Type
+	})
+}
+
+// writeJSONAtomic marshals v as indented JSON and writes it to path via a
+// temp-file-then-rename so a crash or write failure mid-write can't leave a
+// truncated file at path for a later tool to choke on: path either has its
+// old complete contents or its new complete contents, never a partial write.
+func writeJSONAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// rotatingWriter is an io.Writer backing -log-file: it appends to path,
+// and once a write would push the file past maxSize (0 disables this),
+// it rotates the current file to path+".1" (overwriting any prior backup)
+// before continuing, so a single unattended run can't grow the log file
+// without bound.
+type rotatingWriter struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+}
+
+func newRotatingWriter(path string, maxSize int64) (*rotatingWriter, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &rotatingWriter{path: path, maxSize: maxSize, file: f, size: info.Size()}, nil
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.maxSize > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	backupPath := w.path + ".1"
+	os.Remove(backupPath)
+	if err := os.Rename(w.path, backupPath); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(outputPath, data, 0644)
+	w.file = f
+	w.size = 0
+	return nil
+}
+
+func (w *rotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// setupLogOutput points the standard logger at path (creating a
+// rotatingWriter capped at maxSize bytes, 0 meaning unbounded), and, when
+// teeStderr is true, keeps also logging to stderr so a foreground run
+// stays visible. The caller is responsible for closing the returned
+// writer once logging is done.
+func setupLogOutput(path string, teeStderr bool, maxSize int64) (*rotatingWriter, error) {
+	w, err := newRotatingWriter(path, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if teeStderr {
+		log.SetOutput(io.MultiWriter(os.Stderr, w))
+	} else {
+		log.SetOutput(w)
+	}
+	return w, nil
+}
+
+// applyBidDropout randomly drops a fraction of "bid" edges from edges to
+// simulate partial observability of the auction market, while leaving
+// "auction" and "assigned" edges - including the true winning assignment -
+// untouched. dropout is clamped to [0, 1]; rng determines which bids are
+// dropped, so a seeded rng makes the result reproducible.
+func applyBidDropout(edges []Edge, dropout float64, rng *rand.Rand) []Edge {
+	if dropout <= 0 {
+		return edges
+	}
+	if dropout > 1 {
+		dropout = 1
+	}
+	kept := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		if e.Type == "bid" && rng.Float64() < dropout {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// synthesizeBidEdges fills in "bid" edges the LLM omitted from its JSON
+// response, so bid-based metrics (bid variance, bidders-per-task,
+// specialist bid ratios) aren't structurally zero just because the LLM
+// left "bid" out while still including "auction"/"assigned". For every
+// "assigned" edge (task->agent) missing a matching winning "bid" edge,
+// one is synthesized at the task's price midpoint. When includeCompeting
+// is true, other agents with an "auction" edge to the same task but no
+// bid of their own get a losing bid synthesized too, priced below the
+// winner's; rng determines the losing discount, so a seeded rng makes the
+// result reproducible.
+func synthesizeBidEdges(nodes []Node, edges []Edge, includeCompeting bool, rng *rand.Rand) []Edge {
+	taskMidpoint := make(map[string]float64)
+	for _, n := range nodes {
+		if n.Type == "issue" && n.PriceMax > 0 {
+			taskMidpoint[n.ID] = (n.PriceMin + n.PriceMax) / 2
+		}
+	}
+
+	hasBid := make(map[string]bool)
+	winningAgentForTask := make(map[string]string)
+	auctionAgentsForTask := make(map[string][]string)
+	for _, e := range edges {
+		switch e.Type {
+		case "bid":
+			hasBid[e.Source+"->"+e.Target] = true
+		case "assigned":
+			winningAgentForTask[e.Source] = e.Target
+		case "auction":
+			auctionAgentsForTask[e.Target] = append(auctionAgentsForTask[e.Target], e.Source)
+		}
+	}
+
+	synthesized := make([]Edge, 0)
+	for taskID, agentID := range winningAgentForTask {
+		if hasBid[agentID+"->"+taskID] {
+			continue
+		}
+		value := taskMidpoint[taskID]
+		synthesized = append(synthesized, Edge{
+			Source:    agentID,
+			Target:    taskID,
+			Type:      "bid",
+			BidValue:  value,
+			Reasoning: "synthesized: winning bid inferred from the task's assignment",
+		})
+		hasBid[agentID+"->"+taskID] = true
+
+		if !includeCompeting {
+			continue
+		}
+		for _, other := range auctionAgentsForTask[taskID] {
+			if other == agentID || hasBid[other+"->"+taskID] {
+				continue
+			}
+			losing := value * (0.6 + rng.Float64()*0.35)
+			synthesized = append(synthesized, Edge{
+				Source:    other,
+				Target:    taskID,
+				Type:      "bid",
+				BidValue:  losing,
+				Reasoning: "synthesized: losing bid inferred from competing auction exposure",
+			})
+			hasBid[other+"->"+taskID] = true
+		}
+	}
+	return append(edges, synthesized...)
+}
+
+// sortedBidsByValueThenAgentID returns a copy of bids sorted lowest bid
+// first, ties broken by agent ID (Source) so clearing is deterministic
+// regardless of the edges' original order.
+func sortedBidsByValueThenAgentID(bids []Edge) []Edge {
+	sorted := append([]Edge(nil), bids...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].BidValue != sorted[j].BidValue {
+			return sorted[i].BidValue < sorted[j].BidValue
+		}
+		return sorted[i].Source < sorted[j].Source
+	})
+	return sorted
+}
+
+// clearAuction clears taskID's bids as a sealed-bid second-price (Vickrey)
+// auction: the lowest bidder wins but the clearing price is the
+// second-lowest bid value, so no bidder benefits from shading their bid
+// above their true valuation. A task with a single bid clears at that bid.
+// Ties are broken by agent ID for reproducibility. bids must be non-empty.
+func clearAuction(taskID string, bids []Edge) (winner Edge, clearingPrice float64) {
+	sorted := sortedBidsByValueThenAgentID(bids)
+	winner = sorted[0]
+	clearingPrice = winner.BidValue
+	if len(sorted) > 1 {
+		clearingPrice = sorted[1].BidValue
+	}
+	return winner, clearingPrice
+}
+
+// clearFirstPriceAuction clears taskID's bids as a sealed-bid first-price
+// auction: the lowest bidder wins and pays its own bid. Ties are broken by
+// agent ID for reproducibility. bids must be non-empty.
+func clearFirstPriceAuction(taskID string, bids []Edge) (winner Edge, clearingPrice float64) {
+	winner = sortedBidsByValueThenAgentID(bids)[0]
+	return winner, winner.BidValue
+}
+
+// bidsByTask groups edges' "bid" edges (Source=agent, Target=task) by task
+// ID, in the same Source=agent/Target=task convention used throughout this
+// file.
+func bidsByTask(edges []Edge) map[string][]Edge {
+	byTask := make(map[string][]Edge)
+	for _, e := range edges {
+		if e.Type == "bid" {
+			byTask[e.Target] = append(byTask[e.Target], e)
+		}
+	}
+	return byTask
+}
+
+// taskReserves maps every issue node with a declared PriceMax to that
+// reserve price, for filtering out bids the client would refuse to pay in
+// clearAuctions.
+func taskReserves(nodes []Node) map[string]float64 {
+	reserves := make(map[string]float64)
+	for _, n := range nodes {
+		if n.Type == "issue" && n.PriceMax > 0 {
+			reserves[n.ID] = n.PriceMax
+		}
+	}
+	return reserves
+}
+
+// agentConstraint holds an agent's optional MaxConcurrentTasks/Budget caps,
+// as looked up by clearAuctions. A zero field means "unlimited", so an
+// AgentFile that never set these fields imposes no constraint.
+type agentConstraint struct {
+	maxConcurrentTasks int
+	budget             float64
+}
+
+// agentConstraintsFor indexes agents' MaxConcurrentTasks/Budget by agent ID,
+// omitting agents that left both at their zero "unlimited" value.
+func agentConstraintsFor(agents []AgentFile) map[string]agentConstraint {
+	constraints := make(map[string]agentConstraint)
+	for _, a := range agents {
+		if a.MaxConcurrentTasks > 0 || a.Budget > 0 {
+			constraints[a.AgentID] = agentConstraint{maxConcurrentTasks: a.MaxConcurrentTasks, budget: a.Budget}
+		}
+	}
+	return constraints
+}
+
+// removeBidder returns bids with every bid from agentID filtered out, for
+// re-clearing a task after its would-be winner is disqualified by a budget
+// check that could only be evaluated post-clearing (see clearAuctions).
+func removeBidder(bids []Edge, agentID string) []Edge {
+	remaining := make([]Edge, 0, len(bids))
+	for _, b := range bids {
+		if b.Source != agentID {
+			remaining = append(remaining, b)
+		}
+	}
+	return remaining
+}
+
+// clearAuctions replaces the LLM-proposed "assigned" edge for every task
+// that has at least one "bid" edge with a deterministically computed winner
+// and clearing price, per mode ("vickrey" or "firstprice"). Tasks with no
+// bids (or an unrecognized mode) are left exactly as the LLM proposed them.
+//
+// A task with a declared reserve price (PriceMax) whose every bid exceeds
+// it clears with no winner at all - its "assigned" edge is dropped rather
+// than replaced - and the number of such tasks is logged as a summary.
+//
+// Tasks are cleared in ID order so agents' MaxConcurrentTasks/Budget caps
+// (from agents) are enforced against a running count of tasks already won
+// and value already won in this same pass: a bid from an agent already at
+// capacity is excluded before clearing. Budget is different: a bidder whose
+// own bid value alone would already exceed its remaining budget is excluded
+// up front, but that's only a necessary, not sufficient, check - under
+// "vickrey" the actual clearing price is the second-lowest bid, which can
+// run well above the winner's own bid. So after clearing, the winner is
+// re-checked against its budget using the real clearing price; if it would
+// still bust the budget, that bidder is dropped and the task is re-cleared
+// among the remaining eligible bidders, repeating until a winner fits its
+// budget or none are left. A task with no eligible bidder left goes
+// unassigned, logged individually since it reflects genuine bidder
+// saturation rather than the market simply clearing low.
+func clearAuctions(agents []AgentFile, nodes []Node, edges []Edge, mode string) []Edge {
+	byTask := bidsByTask(edges)
+	reserves := taskReserves(nodes)
+	constraints := agentConstraintsFor(agents)
+
+	kept := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		if e.Type == "assigned" {
+			if _, hasBids := byTask[e.Source]; hasBids {
+				continue // superseded by the auction clearing below
+			}
+		}
+		kept = append(kept, e)
+	}
+
+	taskIDs := make([]string, 0, len(byTask))
+	for taskID := range byTask {
+		taskIDs = append(taskIDs, taskID)
+	}
+	sort.Strings(taskIDs)
+
+	tasksWon := make(map[string]int)
+	valueWon := make(map[string]float64)
+	rejectedByReserve := 0
+taskLoop:
+	for _, taskID := range taskIDs {
+		bids := byTask[taskID]
+		if reserve, ok := reserves[taskID]; ok {
+			eligible := make([]Edge, 0, len(bids))
+			for _, b := range bids {
+				if b.BidValue <= reserve {
+					eligible = append(eligible, b)
+				}
+			}
+			if len(eligible) == 0 {
+				rejectedByReserve++
+				continue
+			}
+			bids = eligible
+		}
+
+		eligible := make([]Edge, 0, len(bids))
+		for _, b := range bids {
+			c, constrained := constraints[b.Source]
+			if !constrained {
+				eligible = append(eligible, b)
+				continue
+			}
+			if c.maxConcurrentTasks > 0 && tasksWon[b.Source] >= c.maxConcurrentTasks {
+				continue
+			}
+			if c.budget > 0 && valueWon[b.Source]+b.BidValue > c.budget {
+				continue
+			}
+			eligible = append(eligible, b)
+		}
+		if len(eligible) == 0 {
+			log.Printf("[clearAuctions] task %s went unassigned: every bidder is at capacity or over budget", taskID)
+			continue
+		}
+
+		var clear func(string, []Edge) (Edge, float64)
+		switch mode {
+		case "vickrey":
+			clear = clearAuction
+		case "firstprice":
+			clear = clearFirstPriceAuction
+		default:
+			continue taskLoop
+		}
+
+		winner, clearingPrice := clear(taskID, eligible)
+		for {
+			c, constrained := constraints[winner.Source]
+			if !constrained || c.budget <= 0 || valueWon[winner.Source]+clearingPrice <= c.budget {
+				break
+			}
+			eligible = removeBidder(eligible, winner.Source)
+			if len(eligible) == 0 {
+				log.Printf("[clearAuctions] task %s went unassigned: every bidder is at capacity or over budget", taskID)
+				continue taskLoop
+			}
+			winner, clearingPrice = clear(taskID, eligible)
+		}
+
+		tasksWon[winner.Source]++
+		valueWon[winner.Source] += clearingPrice
+		kept = append(kept, Edge{Source: taskID, Target: winner.Source, Type: "assigned", BidValue: clearingPrice})
+	}
+	if rejectedByReserve > 0 {
+		log.Printf("[clearAuctions] %d task(s) went unassigned: every bid exceeded the task's reserve price", rejectedByReserve)
+	}
+	return kept
+}
+
+// Save network
+func saveNetwork(outputPath string, network Network) error {
+	sortNetwork(network)
+	return writeJSONAtomic(outputPath, network)
 }
 
 // Main
 func main() {
-	agentsPath := "./data/agents" // Folder where agents are stored
-	ollamaURL := "http://localhost:11434/api/generate"
-	outputPath := "./data/baseline_network.json"
+	bidDropout := flag.Float64("bid-dropout", 0, "Fraction (0-1) of \"bid\" edges to randomly omit from the output, simulating partial observability of the auction market; assignments are unaffected")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "Random seed for -bid-dropout")
+	maxRespBytes := flag.Int64("max-response-bytes", maxResponseBytes, "Maximum bytes of an LLM response body to buffer before treating it as a failure")
+	skipBad := flag.Bool("skip-bad", false, "Skip and log malformed agent files instead of aborting the whole load")
+	onDup := flag.String("on-dup", "error", "How to resolve two agent files declaring the same agent_id: \"error\", \"first\", or \"last\"")
+	synthesizeBids := flag.Bool("synthesize-bids", false, "Backfill a \"bid\" edge for every \"assigned\" edge the LLM left without one, so bid-based metrics aren't structurally zero")
+	synthesizeCompetingBids := flag.Bool("synthesize-competing-bids", false, "With -synthesize-bids, also backfill losing bids for other agents auctioned on the same task")
+	logFile := flag.String("log-file", "", "Optional path to tee (or redirect) logs to, in addition to stderr unless -log-stderr=false")
+	logStderr := flag.Bool("log-stderr", true, "When -log-file is set, also log to stderr; set false to log to the file exclusively")
+	logMaxSize := flag.Int64("log-max-size", 0, "Rotate -log-file once it exceeds this many bytes, keeping one backup at <path>.1; 0 disables rotation")
+	auction := flag.String("auction", "", "Clear each task's bids as a deterministic sealed-bid auction, replacing the LLM's proposed assignment: \"vickrey\" (lowest bidder wins, pays the second-lowest bid) or \"firstprice\" (lowest bidder wins at their own bid); empty leaves the LLM's assignments untouched")
+	concurrency := flag.Int("concurrency", 1, "Number of agent pairs to run through the LLM concurrently; 1 means sequential")
+	resume := flag.Bool("resume", false, "Checkpoint each completed agent pair to .checkpoint.jsonl next to -out and, if that file already exists, skip pairs it already covers; the checkpoint is deleted once generation completes successfully")
+	model := flag.String("model", "cogito:8b", "Ollama model name to request for each agent-pair prompt")
+	agentsPathFlag := flag.String("agents", "./data/agents", "Folder where agent JSON files are stored")
+	ollamaURLFlag := flag.String("ollama_url", "http://localhost:11434/api/generate", "Ollama /api/generate endpoint to call for each agent-pair prompt")
+	outputPathFlag := flag.String("out", "./data/baseline_network.json", "Path to write the generated network JSON to")
+	flag.Parse()
+	maxResponseBytes = *maxRespBytes
+	skipBadAgentFiles = *skipBad
+	agentDupPolicy = *onDup
+	if *auction != "" && *auction != "vickrey" && *auction != "firstprice" {
+		log.Fatalf("Invalid -auction %q: must be \"vickrey\" or \"firstprice\"", *auction)
+	}
+
+	if *logFile != "" {
+		logWriter, err := setupLogOutput(*logFile, *logStderr, *logMaxSize)
+		if err != nil {
+			log.Fatalf("Failed to open -log-file %s: %v", *logFile, err)
+		}
+		defer logWriter.Close()
+	}
+
+	agentsPath := *agentsPathFlag
+	ollamaURL := *ollamaURLFlag
+	outputPath := *outputPathFlag
 
 	agents, err := loadAgents(agentsPath)
 	if err != nil {
 		log.Fatalf("Error loading agents: %v", err)
 	}
 
-	nodes, edges := buildNetworkFromPairs(agents, ollamaURL)
+	checkpointPath := ""
+	if *resume {
+		checkpointPath = filepath.Join(filepath.Dir(outputPath), ".checkpoint.jsonl")
+	}
+
+	nodes, edges, err := buildNetworkFromPairs(agents, ollamaURL, *model, *concurrency, checkpointPath)
+	if err != nil {
+		log.Fatalf("Error building network: %v", err)
+	}
+	rng := rand.New(rand.NewSource(*seed))
+	if *synthesizeBids {
+		edges = synthesizeBidEdges(nodes, edges, *synthesizeCompetingBids, rng)
+	}
+	if *auction != "" {
+		edges = clearAuctions(agents, nodes, edges, *auction)
+	}
+	edges = applyBidDropout(edges, *bidDropout, rng)
 
 	network := Network{Nodes: nodes, Edges: edges}
 
@@ -311,5 +1181,11 @@ func main() {
 		log.Fatalf("Error saving network: %v", err)
 	}
 
+	if checkpointPath != "" {
+		if err := os.Remove(checkpointPath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove checkpoint file %s: %v", checkpointPath, err)
+		}
+	}
+
 	log.Println("✅ Network generated and saved to", outputPath)
 }
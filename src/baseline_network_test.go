@@ -0,0 +1,996 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadAgentsReportsFileAndLineOnBrokenJSON(t *testing.T) {
+	dir := t.TempDir()
+	broken := "{\n  \"agent_id\": \"agent1\",\n  \"display_name\": \n}"
+	if err := os.WriteFile(filepath.Join(dir, "agent1.json"), []byte(broken), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+
+	_, err := loadAgents(dir)
+	if err == nil {
+		t.Fatal("expected an error for malformed agent JSON, got nil")
+	}
+	if !strings.Contains(err.Error(), "agent1.json:") {
+		t.Fatalf("expected error to name the file, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), ":4:") {
+		t.Fatalf("expected error to name line 4, got: %v", err)
+	}
+}
+
+func TestLoadAgentsSortsByAgentIDRegardlessOfFilename(t *testing.T) {
+	dir := t.TempDir()
+	write := func(filename, agentID string) {
+		data := `{"agent_id": "` + agentID + `"}`
+		if err := os.WriteFile(filepath.Join(dir, filename), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write test fixture: %v", err)
+		}
+	}
+	write("z.json", "agent1")
+	write("a.json", "agent2")
+
+	agents, err := loadAgents(dir)
+	if err != nil {
+		t.Fatalf("loadAgents failed: %v", err)
+	}
+	if len(agents) != 2 || agents[0].AgentID != "agent1" || agents[1].AgentID != "agent2" {
+		t.Fatalf("expected agents sorted by agent_id [agent1, agent2], got %+v", agents)
+	}
+}
+
+func TestLoadAgentsHandlesManyFilesConcurrentlyWithStableOrder(t *testing.T) {
+	dir := t.TempDir()
+	const numAgents = 200
+	for i := 0; i < numAgents; i++ {
+		agentID := fmt.Sprintf("agent%03d", i)
+		data := `{"agent_id": "` + agentID + `"}`
+		if err := os.WriteFile(filepath.Join(dir, agentID+".json"), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write test fixture: %v", err)
+		}
+	}
+
+	agents, err := loadAgents(dir)
+	if err != nil {
+		t.Fatalf("loadAgents failed: %v", err)
+	}
+	if len(agents) != numAgents {
+		t.Fatalf("expected %d agents, got %d", numAgents, len(agents))
+	}
+	for i, a := range agents {
+		want := fmt.Sprintf("agent%03d", i)
+		if a.AgentID != want {
+			t.Fatalf("expected agents sorted by AgentID, position %d has %q, want %q", i, a.AgentID, want)
+		}
+	}
+}
+
+func TestLoadAgentsErrorsOnDuplicateAgentIDByDefault(t *testing.T) {
+	dir := t.TempDir()
+	write := func(filename, agentID string) {
+		if err := os.WriteFile(filepath.Join(dir, filename), []byte(`{"agent_id": "`+agentID+`"}`), 0644); err != nil {
+			t.Fatalf("failed to write test fixture: %v", err)
+		}
+	}
+	write("a_first.json", "dup1")
+	write("b_second.json", "dup1")
+
+	_, err := loadAgents(dir)
+	if err == nil {
+		t.Fatal("expected loadAgents to error on a duplicate agent_id by default")
+	}
+	if !strings.Contains(err.Error(), "dup1") {
+		t.Fatalf("expected error to name the conflicting agent_id, got: %v", err)
+	}
+}
+
+func TestLoadAgentsOnDupFirstKeepsEarliestFileAndDoesNotDoubleCount(t *testing.T) {
+	orig := agentDupPolicy
+	agentDupPolicy = "first"
+	defer func() { agentDupPolicy = orig }()
+
+	dir := t.TempDir()
+	write := func(filename, agentID, displayName string) {
+		data := `{"agent_id": "` + agentID + `", "display_name": "` + displayName + `"}`
+		if err := os.WriteFile(filepath.Join(dir, filename), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write test fixture: %v", err)
+		}
+	}
+	write("a_first.json", "dup1", "First Copy")
+	write("b_second.json", "dup1", "Second Copy")
+	write("c_unique.json", "agent2", "Unique")
+
+	agents, err := loadAgents(dir)
+	if err != nil {
+		t.Fatalf("loadAgents failed: %v", err)
+	}
+	if len(agents) != 2 {
+		t.Fatalf("expected duplicate agent_id to be counted once, got %d agents: %+v", len(agents), agents)
+	}
+	var dup AgentFile
+	for _, a := range agents {
+		if a.AgentID == "dup1" {
+			dup = a
+		}
+	}
+	if dup.DisplayName != "First Copy" {
+		t.Fatalf("expected -on-dup=first to keep the earliest file's contents, got %q", dup.DisplayName)
+	}
+}
+
+func TestLoadAgentsOnDupLastKeepsLatestFileAndDoesNotDoubleCount(t *testing.T) {
+	orig := agentDupPolicy
+	agentDupPolicy = "last"
+	defer func() { agentDupPolicy = orig }()
+
+	dir := t.TempDir()
+	write := func(filename, agentID, displayName string) {
+		data := `{"agent_id": "` + agentID + `", "display_name": "` + displayName + `"}`
+		if err := os.WriteFile(filepath.Join(dir, filename), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write test fixture: %v", err)
+		}
+	}
+	write("a_first.json", "dup1", "First Copy")
+	write("b_second.json", "dup1", "Second Copy")
+
+	agents, err := loadAgents(dir)
+	if err != nil {
+		t.Fatalf("loadAgents failed: %v", err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("expected duplicate agent_id to be counted once, got %d agents: %+v", len(agents), agents)
+	}
+	if agents[0].DisplayName != "Second Copy" {
+		t.Fatalf("expected -on-dup=last to keep the latest file's contents, got %q", agents[0].DisplayName)
+	}
+}
+
+func TestLoadAgentsSkipsBadFilesWhenSkipBadIsSet(t *testing.T) {
+	orig := skipBadAgentFiles
+	skipBadAgentFiles = true
+	defer func() { skipBadAgentFiles = orig }()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "good.json"), []byte(`{"agent_id": "agent1"}`), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{not valid json`), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+
+	agents, err := loadAgents(dir)
+	if err != nil {
+		t.Fatalf("expected loadAgents to skip the bad file rather than fail, got: %v", err)
+	}
+	if len(agents) != 1 || agents[0].AgentID != "agent1" {
+		t.Fatalf("expected only the good agent to load, got %+v", agents)
+	}
+}
+
+// TestLoadAgentsDoesNotLeakWorkerGoroutinesOnMultipleBadFiles reproduces a
+// goroutine leak: with skipBadAgentFiles false, loadAgents used to return on
+// the first error without draining the results channel, stranding every
+// worker still blocked sending on results (and the feeder goroutine still
+// blocked sending on jobs) for the remaining, not-yet-consumed paths.
+func TestLoadAgentsDoesNotLeakWorkerGoroutinesOnMultipleBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("bad%d.json", i))
+		if err := os.WriteFile(name, []byte(`{not valid json`), 0644); err != nil {
+			t.Fatalf("failed to write test fixture: %v", err)
+		}
+	}
+
+	before := runtime.NumGoroutine()
+
+	if _, err := loadAgents(dir); err == nil {
+		t.Fatalf("expected loadAgents to fail on malformed JSON")
+	}
+
+	// Worker/feeder goroutines exit promptly once they're not leaked; give
+	// them a moment to unwind before comparing goroutine counts.
+	var after int
+	for i := 0; i < 50; i++ {
+		after = runtime.NumGoroutine()
+		if after <= before {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after > before {
+		t.Fatalf("loadAgents leaked goroutines: had %d before, %d after", before, after)
+	}
+}
+
+func TestAgentLabelPrefersDisplayNameFallsBackToID(t *testing.T) {
+	if got := agentLabel(AgentFile{AgentID: "agent1", DisplayName: "BugFixes Specialist #1"}); got != "BugFixes Specialist #1" {
+		t.Fatalf("expected DisplayName to be preferred, got %q", got)
+	}
+	if got := agentLabel(AgentFile{AgentID: "agent1"}); got != "agent1" {
+		t.Fatalf("expected AgentID fallback when DisplayName is empty, got %q", got)
+	}
+}
+
+// TestAgentRetainsConsistentIDAndNameThroughGenerationNetworkBuildAndVizLoad
+// simulates the full pipeline: an agent file as produced by
+// swe_manager_task_distribution.go's generation step, converted into a
+// network Node by buildNetworkFromPairs, then serialized and reloaded the way
+// viz.go loads a network JSON off disk - confirming ID and Label survive
+// every hop unchanged.
+func TestAgentRetainsConsistentIDAndNameThroughGenerationNetworkBuildAndVizLoad(t *testing.T) {
+	generated := AgentFile{AgentID: "agent7", DisplayName: "ServerSideLogic Specialist #7"}
+
+	nodes, _, err := buildNetworkFromPairs([]AgentFile{generated}, "", "cogito:8b", 1, "")
+	if err != nil {
+		t.Fatalf("buildNetworkFromPairs failed: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("expected exactly one node, got %d", len(nodes))
+	}
+	built := nodes[0]
+	if built.ID != generated.AgentID || built.Label != generated.DisplayName {
+		t.Fatalf("expected ID %q and Label %q to survive buildNetworkFromPairs, got ID %q Label %q",
+			generated.AgentID, generated.DisplayName, built.ID, built.Label)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "network.json")
+	if err := saveNetwork(path, Network{Nodes: nodes}); err != nil {
+		t.Fatalf("saveNetwork failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read saved network: %v", err)
+	}
+	var loaded Network
+	if err := unmarshalJSONWithContext(path, data, &loaded); err != nil {
+		t.Fatalf("failed to load network: %v", err)
+	}
+	if len(loaded.Nodes) != 1 || loaded.Nodes[0].ID != generated.AgentID || loaded.Nodes[0].Label != generated.DisplayName {
+		t.Fatalf("expected ID and Label to survive a save/load round trip, got %+v", loaded.Nodes)
+	}
+}
+
+func TestWriteJSONAtomicLeavesOriginalIntactOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "network.json")
+	if err := os.WriteFile(path, []byte(`{"nodes":[],"edges":[]}`), 0644); err != nil {
+		t.Fatalf("failed to write original file: %v", err)
+	}
+
+	// Occupy path+".tmp" with a directory so the temp-file write step fails
+	// (can't open a directory for writing) before any rename can happen,
+	// simulating a write failure partway through - this works even running
+	// as root, unlike a permission-based failure.
+	if err := os.Mkdir(path+".tmp", 0755); err != nil {
+		t.Fatalf("failed to create blocking directory: %v", err)
+	}
+
+	err := writeJSONAtomic(path, Network{Nodes: []Node{{ID: "new"}}})
+	if err == nil {
+		t.Fatal("expected writeJSONAtomic to fail when its temp path is occupied")
+	}
+
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatalf("failed to read original file: %v", readErr)
+	}
+	if string(data) != `{"nodes":[],"edges":[]}` {
+		t.Fatalf("expected original file left intact, got %q", data)
+	}
+}
+
+func TestWriteJSONAtomicReplacesFileOnSuccess(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "network.json")
+	if err := os.WriteFile(path, []byte(`old`), 0644); err != nil {
+		t.Fatalf("failed to write original file: %v", err)
+	}
+
+	if err := writeJSONAtomic(path, Network{Nodes: []Node{{ID: "agent1"}}}); err != nil {
+		t.Fatalf("writeJSONAtomic failed: %v", err)
+	}
+
+	var decoded Network
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read written file: %v", err)
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode written file: %v", err)
+	}
+	if len(decoded.Nodes) != 1 || decoded.Nodes[0].ID != "agent1" {
+		t.Fatalf("expected the new content to replace the old, got %+v", decoded)
+	}
+}
+
+func TestReadLimitedBodyErrorsWhenResponseExceedsCap(t *testing.T) {
+	oversized := strings.NewReader(strings.Repeat("x", 2048))
+	_, err := readLimitedBody(oversized, 1024)
+	if err == nil {
+		t.Fatal("expected an error when the body exceeds the byte cap, got nil")
+	}
+}
+
+func TestReadLimitedBodyPassesThroughUnderCap(t *testing.T) {
+	small := strings.NewReader("hello")
+	data, err := readLimitedBody(small, 1024)
+	if err != nil {
+		t.Fatalf("readLimitedBody failed: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("expected \"hello\", got %q", data)
+	}
+}
+
+func TestCallOllamaLLMFailsOnOversizedResponse(t *testing.T) {
+	orig := maxResponseBytes
+	maxResponseBytes = 16
+	defer func() { maxResponseBytes = orig }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": "` + strings.Repeat("x", 1000) + `"}`))
+	}))
+	defer server.Close()
+
+	if _, err := callOllamaLLM("prompt", server.URL, "cogito:8b"); err == nil {
+		t.Fatal("expected callOllamaLLM to fail on an oversized response, got nil")
+	}
+}
+
+func TestCallOllamaLLMSendsTheRequestedModel(t *testing.T) {
+	var gotModel string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Model string `json:"model"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode request payload: %v", err)
+		}
+		gotModel = payload.Model
+		w.Write([]byte(`{"response": "{}"}`))
+	}))
+	defer server.Close()
+
+	if _, err := callOllamaLLM("prompt", server.URL, "llama3:70b"); err != nil {
+		t.Fatalf("callOllamaLLM failed: %v", err)
+	}
+	if gotModel != "llama3:70b" {
+		t.Fatalf("expected the request to carry model %q, got %q", "llama3:70b", gotModel)
+	}
+}
+
+func TestApplyBidDropoutDropsRoughlyTheConfiguredFractionButKeepsAssignments(t *testing.T) {
+	edges := make([]Edge, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		edges = append(edges, Edge{Source: "agentA", Target: "task1", Type: "bid"})
+	}
+	edges = append(edges, Edge{Source: "task1", Target: "agentA", Type: "assigned"})
+
+	rng := rand.New(rand.NewSource(1))
+	kept := applyBidDropout(edges, 0.3, rng)
+
+	var bids, assigned int
+	for _, e := range kept {
+		switch e.Type {
+		case "bid":
+			bids++
+		case "assigned":
+			assigned++
+		}
+	}
+	if assigned != 1 {
+		t.Fatalf("expected the assigned edge to survive dropout, got %d", assigned)
+	}
+	if bids < 600 || bids > 800 {
+		t.Fatalf("expected roughly 70%% of 1000 bids to survive a 0.3 dropout, got %d", bids)
+	}
+}
+
+func TestApplyBidDropoutZeroLeavesEdgesUnchanged(t *testing.T) {
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid"},
+		{Source: "task1", Target: "agentA", Type: "assigned"},
+	}
+	kept := applyBidDropout(edges, 0, rand.New(rand.NewSource(1)))
+	if len(kept) != len(edges) {
+		t.Fatalf("expected no edges dropped at dropout=0, got %d of %d", len(kept), len(edges))
+	}
+}
+
+func buildShuffledNetwork(order []int) Network {
+	nodes := []Node{{ID: "b"}, {ID: "a"}, {ID: "c"}}
+	edges := []Edge{
+		{Source: "b", Target: "c", Type: "bid"},
+		{Source: "a", Target: "b", Type: "assigned"},
+		{Source: "a", Target: "b", Type: "auction"},
+	}
+	shuffledNodes := make([]Node, len(nodes))
+	for i, j := range order {
+		shuffledNodes[i] = nodes[j]
+	}
+	shuffledEdges := make([]Edge, len(edges))
+	for i, j := range order {
+		shuffledEdges[i] = edges[j]
+	}
+	return Network{Nodes: shuffledNodes, Edges: shuffledEdges}
+}
+
+func TestSaveNetworkProducesByteIdenticalOutputRegardlessOfInputOrder(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.json")
+	pathB := filepath.Join(dir, "b.json")
+
+	if err := saveNetwork(pathA, buildShuffledNetwork([]int{0, 1, 2})); err != nil {
+		t.Fatalf("saveNetwork failed: %v", err)
+	}
+	if err := saveNetwork(pathB, buildShuffledNetwork([]int{2, 0, 1})); err != nil {
+		t.Fatalf("saveNetwork failed: %v", err)
+	}
+
+	dataA, err := os.ReadFile(pathA)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", pathA, err)
+	}
+	dataB, err := os.ReadFile(pathB)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", pathB, err)
+	}
+	if string(dataA) != string(dataB) {
+		t.Fatalf("expected byte-identical output regardless of input order:\nA:\n%s\nB:\n%s", dataA, dataB)
+	}
+
+	var decoded Network
+	if err := json.Unmarshal(dataA, &decoded); err != nil {
+		t.Fatalf("failed to decode saved network: %v", err)
+	}
+	if len(decoded.Nodes) != 3 || len(decoded.Edges) != 3 {
+		t.Fatalf("expected all nodes and edges preserved, got %+v", decoded)
+	}
+}
+
+func TestSynthesizeBidEdgesBackfillsAWinningBidWhenTheLLMOmittedOne(t *testing.T) {
+	nodes := []Node{
+		{ID: "task1", Type: "issue", PriceMin: 100, PriceMax: 200},
+	}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "auction"},
+		{Source: "task1", Target: "agentA", Type: "assigned"},
+	}
+
+	got := synthesizeBidEdges(nodes, edges, false, rand.New(rand.NewSource(1)))
+
+	var bids []Edge
+	for _, e := range got {
+		if e.Type == "bid" {
+			bids = append(bids, e)
+		}
+	}
+	if len(bids) != 1 {
+		t.Fatalf("expected exactly one synthesized bid edge, got %d: %+v", len(bids), bids)
+	}
+	if bids[0].Source != "agentA" || bids[0].Target != "task1" {
+		t.Fatalf("expected the winning agent's bid, got %+v", bids[0])
+	}
+	if bids[0].BidValue != 150 {
+		t.Fatalf("expected the bid value to be the task's price midpoint (150), got %v", bids[0].BidValue)
+	}
+}
+
+func TestSynthesizeBidEdgesLeavesAnExistingWinningBidUntouched(t *testing.T) {
+	nodes := []Node{{ID: "task1", Type: "issue", PriceMin: 100, PriceMax: 200}}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 175},
+		{Source: "task1", Target: "agentA", Type: "assigned"},
+	}
+
+	got := synthesizeBidEdges(nodes, edges, false, rand.New(rand.NewSource(1)))
+
+	var bids []Edge
+	for _, e := range got {
+		if e.Type == "bid" {
+			bids = append(bids, e)
+		}
+	}
+	if len(bids) != 1 || bids[0].BidValue != 175 {
+		t.Fatalf("expected the original bid edge to survive unchanged, got %+v", bids)
+	}
+}
+
+func TestSynthesizeBidEdgesWithCompetingBacksfillsLosingBidsBelowTheWinner(t *testing.T) {
+	nodes := []Node{{ID: "task1", Type: "issue", PriceMin: 100, PriceMax: 200}}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "auction"},
+		{Source: "agentB", Target: "task1", Type: "auction"},
+		{Source: "task1", Target: "agentA", Type: "assigned"},
+	}
+
+	got := synthesizeBidEdges(nodes, edges, true, rand.New(rand.NewSource(1)))
+
+	bidsBySource := make(map[string]Edge)
+	for _, e := range got {
+		if e.Type == "bid" {
+			bidsBySource[e.Source] = e
+		}
+	}
+	if len(bidsBySource) != 2 {
+		t.Fatalf("expected a bid from both the winner and the competitor, got %+v", bidsBySource)
+	}
+	if bidsBySource["agentB"].BidValue >= bidsBySource["agentA"].BidValue {
+		t.Fatalf("expected the losing bid to be priced below the winner's, got %+v", bidsBySource)
+	}
+}
+
+func TestSynthesizeBidEdgesWithoutCompetingLeavesNonWinningAuctionsUnbid(t *testing.T) {
+	nodes := []Node{{ID: "task1", Type: "issue", PriceMin: 100, PriceMax: 200}}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "auction"},
+		{Source: "agentB", Target: "task1", Type: "auction"},
+		{Source: "task1", Target: "agentA", Type: "assigned"},
+	}
+
+	got := synthesizeBidEdges(nodes, edges, false, rand.New(rand.NewSource(1)))
+
+	for _, e := range got {
+		if e.Type == "bid" && e.Source == "agentB" {
+			t.Fatalf("expected no synthesized bid for the non-winning agent without -synthesize-competing-bids, got %+v", e)
+		}
+	}
+}
+
+func TestBuildNetworkFromPairsDedupsConcurrentlyWithoutRaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `{"response": "{\"tasks\": [{\"id\": \"sharedTask\", \"desc\": \"d\", \"speciality\": {\"name\": \"BugFixes\"}, \"price_min\": 10, \"price_max\": 20}], \"edges\": [{\"source\": \"sharedTask\", \"target\": \"agent0\", \"type\": \"assigned\"}]}"}`
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	agents := make([]AgentFile, 8)
+	for i := range agents {
+		agents[i] = AgentFile{AgentID: fmt.Sprintf("agent%d", i)}
+	}
+
+	nodes, edges, err := buildNetworkFromPairs(agents, server.URL, "cogito:8b", 4, "")
+	if err != nil {
+		t.Fatalf("buildNetworkFromPairs failed: %v", err)
+	}
+
+	taskNodes := 0
+	for _, n := range nodes {
+		if n.Type == "issue" {
+			taskNodes++
+		}
+	}
+	if taskNodes != 1 {
+		t.Fatalf("expected every pair's identical task to dedup into a single node, got %d task node(s)", taskNodes)
+	}
+	if len(edges) != 1 {
+		t.Fatalf("expected every pair's identical edge to dedup into a single edge, got %d: %+v", len(edges), edges)
+	}
+}
+
+func TestBuildNetworkFromPairsWritesACheckpointLinePerCompletedPair(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": "{\"tasks\": [], \"edges\": []}"}`))
+	}))
+	defer server.Close()
+
+	agents := []AgentFile{{AgentID: "agent0"}, {AgentID: "agent1"}, {AgentID: "agent2"}}
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+
+	if _, _, err := buildNetworkFromPairs(agents, server.URL, "cogito:8b", 1, checkpointPath); err != nil {
+		t.Fatalf("buildNetworkFromPairs failed: %v", err)
+	}
+
+	entries, err := loadCheckpoint(checkpointPath)
+	if err != nil {
+		t.Fatalf("loadCheckpoint failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected one checkpoint entry per agent pair (3 pairs among 3 agents), got %d", len(entries))
+	}
+}
+
+func TestBuildNetworkFromPairsResumeSkipsAlreadyCheckpointedPairs(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"response": "{\"tasks\": [], \"edges\": []}"}`))
+	}))
+	defer server.Close()
+
+	agents := []AgentFile{{AgentID: "agent0"}, {AgentID: "agent1"}}
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+
+	if err := appendCheckpoint(checkpointPath, checkpointEntry{
+		Pair:  checkpointPairKey(agents[0], agents[1]),
+		Nodes: []Node{{ID: "restoredTask", Type: "issue"}},
+		Edges: []Edge{{Source: "restoredTask", Target: "agent0", Type: "assigned"}},
+	}); err != nil {
+		t.Fatalf("appendCheckpoint failed: %v", err)
+	}
+
+	nodes, edges, err := buildNetworkFromPairs(agents, server.URL, "cogito:8b", 1, checkpointPath)
+	if err != nil {
+		t.Fatalf("buildNetworkFromPairs failed: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 0 {
+		t.Fatalf("expected the already-checkpointed pair to be skipped, but the LLM was called %d time(s)", calls)
+	}
+
+	foundNode := false
+	for _, n := range nodes {
+		if n.ID == "restoredTask" {
+			foundNode = true
+		}
+	}
+	if !foundNode {
+		t.Fatalf("expected the checkpointed pair's node to be reused, got %+v", nodes)
+	}
+	foundEdge := false
+	for _, e := range edges {
+		if e.Source == "restoredTask" && e.Target == "agent0" {
+			foundEdge = true
+		}
+	}
+	if !foundEdge {
+		t.Fatalf("expected the checkpointed pair's edge to be reused, got %+v", edges)
+	}
+}
+
+func TestExtractJSONObjectStripsMarkdownFence(t *testing.T) {
+	input := "```json\n{\"tasks\": [], \"edges\": []}\n```"
+	got, err := extractJSONObject(input)
+	if err != nil {
+		t.Fatalf("extractJSONObject failed: %v", err)
+	}
+	if got != `{"tasks": [], "edges": []}` {
+		t.Fatalf("expected the fenced JSON object to be extracted, got %q", got)
+	}
+}
+
+func TestExtractJSONObjectSkipsLeadingProseAndTrailingText(t *testing.T) {
+	input := `Sure, here is the JSON you asked for: {"tasks": [], "edges": []} Let me know if you need anything else!`
+	got, err := extractJSONObject(input)
+	if err != nil {
+		t.Fatalf("extractJSONObject failed: %v", err)
+	}
+	if got != `{"tasks": [], "edges": []}` {
+		t.Fatalf("expected the outermost balanced object to be extracted, got %q", got)
+	}
+}
+
+func TestExtractJSONObjectIgnoresBracesInsideStringValues(t *testing.T) {
+	input := `{"desc": "handle the {edge case} correctly"}`
+	got, err := extractJSONObject(input)
+	if err != nil {
+		t.Fatalf("extractJSONObject failed: %v", err)
+	}
+	if got != input {
+		t.Fatalf("expected braces inside a string literal to be ignored, got %q", got)
+	}
+}
+
+func TestExtractJSONObjectErrorsWhenNoObjectPresent(t *testing.T) {
+	if _, err := extractJSONObject("not json at all"); err == nil {
+		t.Fatal("expected an error when no JSON object is present")
+	}
+}
+
+func TestParseLLMPairResponseHandlesMarkdownFencedResponse(t *testing.T) {
+	response := "```json\n{\"tasks\": [{\"id\": \"task1\", \"desc\": \"d\", \"speciality\": {\"name\": \"BugFixes\"}, \"price_min\": 1, \"price_max\": 2}], \"edges\": []}\n```"
+	edges, nodes := parseLLMPairResponse(response)
+	if len(nodes) != 1 || nodes[0].ID != "task1" {
+		t.Fatalf("expected the fenced response to parse into one task node, got %+v", nodes)
+	}
+	if len(edges) != 0 {
+		t.Fatalf("expected no edges, got %+v", edges)
+	}
+}
+
+func TestClearAuctionPaysTheSecondLowestBid(t *testing.T) {
+	bids := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 100},
+		{Source: "agentB", Target: "task1", Type: "bid", BidValue: 80},
+		{Source: "agentC", Target: "task1", Type: "bid", BidValue: 90},
+	}
+
+	winner, clearingPrice := clearAuction("task1", bids)
+
+	if winner.Source != "agentB" {
+		t.Fatalf("expected the lowest bidder agentB to win, got %+v", winner)
+	}
+	if clearingPrice != 90 {
+		t.Fatalf("expected the clearing price to be the second-lowest bid (90), got %v", clearingPrice)
+	}
+}
+
+func TestClearAuctionSingleBidClearsAtThatBid(t *testing.T) {
+	bids := []Edge{{Source: "agentA", Target: "task1", Type: "bid", BidValue: 100}}
+
+	winner, clearingPrice := clearAuction("task1", bids)
+
+	if winner.Source != "agentA" || clearingPrice != 100 {
+		t.Fatalf("expected the sole bidder to win and clear at its own bid, got winner=%+v price=%v", winner, clearingPrice)
+	}
+}
+
+func TestClearAuctionBreaksTiesByAgentID(t *testing.T) {
+	bids := []Edge{
+		{Source: "agentZ", Target: "task1", Type: "bid", BidValue: 50},
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 50},
+	}
+
+	winner, _ := clearAuction("task1", bids)
+
+	if winner.Source != "agentA" {
+		t.Fatalf("expected the tie to be broken by lowest agent ID (agentA), got %+v", winner)
+	}
+}
+
+func TestClearFirstPriceAuctionWinnerPaysItsOwnBid(t *testing.T) {
+	bids := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 100},
+		{Source: "agentB", Target: "task1", Type: "bid", BidValue: 80},
+	}
+
+	winner, clearingPrice := clearFirstPriceAuction("task1", bids)
+
+	if winner.Source != "agentB" || clearingPrice != 80 {
+		t.Fatalf("expected agentB to win and pay its own bid of 80, got winner=%+v price=%v", winner, clearingPrice)
+	}
+}
+
+func TestClearAuctionsReplacesLLMAssignmentWithVickreyClearing(t *testing.T) {
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 100},
+		{Source: "agentB", Target: "task1", Type: "bid", BidValue: 80},
+		{Source: "task1", Target: "agentA", Type: "assigned"}, // LLM's (incorrect) pick, should be discarded
+	}
+
+	got := clearAuctions(nil, nil, edges, "vickrey")
+
+	var assigned []Edge
+	for _, e := range got {
+		if e.Type == "assigned" {
+			assigned = append(assigned, e)
+		}
+	}
+	if len(assigned) != 1 {
+		t.Fatalf("expected exactly one assigned edge for task1, got %+v", assigned)
+	}
+	if assigned[0].Target != "agentB" || assigned[0].BidValue != 100 {
+		t.Fatalf("expected agentB to win task1 clearing at 100 (the second-lowest bid), got %+v", assigned[0])
+	}
+}
+
+func TestClearAuctionsLeavesTasksWithoutBidsUntouched(t *testing.T) {
+	edges := []Edge{
+		{Source: "task1", Target: "agentA", Type: "assigned"},
+	}
+
+	got := clearAuctions(nil, nil, edges, "vickrey")
+
+	if len(got) != 1 || got[0] != edges[0] {
+		t.Fatalf("expected a bidless task's assignment to pass through unchanged, got %+v", got)
+	}
+}
+
+func TestClearAuctionsRejectsTaskWhenEveryBidExceedsReserve(t *testing.T) {
+	nodes := []Node{{ID: "task1", Type: "issue", PriceMax: 50}}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 60},
+		{Source: "agentB", Target: "task1", Type: "bid", BidValue: 70},
+		{Source: "task1", Target: "agentA", Type: "assigned"},
+	}
+
+	got := clearAuctions(nil, nodes, edges, "firstprice")
+
+	for _, e := range got {
+		if e.Type == "assigned" {
+			t.Fatalf("expected task1 to remain unassigned since every bid exceeds its reserve, got %+v", e)
+		}
+	}
+}
+
+func TestClearAuctionsFirstPriceHonorsReserveAmongEligibleBids(t *testing.T) {
+	nodes := []Node{{ID: "task1", Type: "issue", PriceMax: 90}}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 60},
+		{Source: "agentB", Target: "task1", Type: "bid", BidValue: 120},
+	}
+
+	got := clearAuctions(nil, nodes, edges, "firstprice")
+
+	var assigned *Edge
+	for i, e := range got {
+		if e.Type == "assigned" {
+			assigned = &got[i]
+		}
+	}
+	if assigned == nil || assigned.Target != "agentA" || assigned.BidValue != 60 {
+		t.Fatalf("expected agentA to win at its own bid of 60, the only bid within reserve, got %+v", assigned)
+	}
+}
+
+func TestClearAuctionsSkipsAgentAtConcurrentTaskCapacity(t *testing.T) {
+	agents := []AgentFile{{AgentID: "agentA", MaxConcurrentTasks: 1}}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 10},
+		{Source: "agentB", Target: "task1", Type: "bid", BidValue: 20},
+		{Source: "agentA", Target: "task2", Type: "bid", BidValue: 10},
+	}
+
+	got := clearAuctions(agents, nil, edges, "firstprice")
+
+	winners := make(map[string]string)
+	for _, e := range got {
+		if e.Type == "assigned" {
+			winners[e.Source] = e.Target
+		}
+	}
+	if winners["task1"] != "agentA" {
+		t.Fatalf("expected agentA to win its first task, got %+v", winners)
+	}
+	if _, wonSecond := winners["task2"]; wonSecond {
+		t.Fatalf("expected agentA to be skipped for task2 once at its 1-task capacity, got %+v", winners)
+	}
+}
+
+func TestClearAuctionsSkipsAgentWhoseCumulativeWonValueWouldExceedBudget(t *testing.T) {
+	agents := []AgentFile{{AgentID: "agentA", Budget: 15}}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 10},
+		{Source: "agentB", Target: "task2", Type: "bid", BidValue: 100},
+		{Source: "agentA", Target: "task2", Type: "bid", BidValue: 10},
+	}
+
+	got := clearAuctions(agents, nil, edges, "firstprice")
+
+	winners := make(map[string]string)
+	for _, e := range got {
+		if e.Type == "assigned" {
+			winners[e.Source] = e.Target
+		}
+	}
+	if winners["task1"] != "agentA" {
+		t.Fatalf("expected agentA to win task1 within its budget, got %+v", winners)
+	}
+	if winners["task2"] != "agentB" {
+		t.Fatalf("expected agentA to be skipped for task2 (10+10=20 > budget 15), reassigning to agentB, got %+v", winners)
+	}
+}
+
+func TestClearAuctionsVickreyRejectsWinnerWhoseClearingPriceExceedsBudget(t *testing.T) {
+	// agentA bids 1, well within its budget of 10, but the competing bid of
+	// 1000 makes the Vickrey clearing price 1000 - far more than agentA's
+	// own bid ever suggested. agentA must be disqualified post-clearing and
+	// the task re-cleared among the remaining bidders instead of blowing
+	// through its budget.
+	agents := []AgentFile{{AgentID: "agentA", Budget: 10}}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 1},
+		{Source: "agentB", Target: "task1", Type: "bid", BidValue: 1000},
+	}
+
+	got := clearAuctions(agents, nil, edges, "vickrey")
+
+	for _, e := range got {
+		if e.Type == "assigned" && e.Source == "task1" {
+			if e.Target == "agentA" {
+				t.Fatalf("expected agentA to be disqualified (clearing price 1000 > budget 10), got %+v", e)
+			}
+			if e.Target != "agentB" {
+				t.Fatalf("expected task1 to fall through to agentB, got %+v", e)
+			}
+			if e.BidValue != 1000 {
+				t.Fatalf("expected agentB to clear at its own bid (no other bidders left), got %+v", e)
+			}
+		}
+	}
+}
+
+func TestClearAuctionsLeavesTaskUnassignedWhenAllBiddersAreSaturated(t *testing.T) {
+	agents := []AgentFile{{AgentID: "agentA", MaxConcurrentTasks: 1}}
+	edges := []Edge{
+		{Source: "agentA", Target: "task1", Type: "bid", BidValue: 10},
+		{Source: "agentA", Target: "task2", Type: "bid", BidValue: 10},
+	}
+
+	got := clearAuctions(agents, nil, edges, "firstprice")
+
+	for _, e := range got {
+		if e.Type == "assigned" && e.Source == "task2" {
+			t.Fatalf("expected task2 to go unassigned since its only bidder is already saturated, got %+v", e)
+		}
+	}
+}
+
+func TestRotatingWriterWritesToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.log")
+	w, err := newRotatingWriter(path, 0)
+	if err != nil {
+		t.Fatalf("newRotatingWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if string(data) != "hello\n" {
+		t.Fatalf("expected \"hello\\n\" written to the log file, got %q", data)
+	}
+}
+
+func TestRotatingWriterRotatesPastMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.log")
+	w, err := newRotatingWriter(path, 10)
+	if err != nil {
+		t.Fatalf("newRotatingWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("first write failed: %v", err)
+	}
+	if _, err := w.Write([]byte("overflow\n")); err != nil {
+		t.Fatalf("second write failed: %v", err)
+	}
+
+	backup, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatalf("expected a rotated backup file: %v", err)
+	}
+	if string(backup) != "0123456789" {
+		t.Fatalf("expected the backup to hold the pre-rotation content, got %q", backup)
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the post-rotation log file: %v", err)
+	}
+	if string(current) != "overflow\n" {
+		t.Fatalf("expected the post-rotation write in the fresh log file, got %q", current)
+	}
+}
+
+func TestSetupLogOutputTeesToStderrByDefaultButCanBeExclusive(t *testing.T) {
+	origOutput := log.Writer()
+	defer log.SetOutput(origOutput)
+
+	path := filepath.Join(t.TempDir(), "run.log")
+	w, err := setupLogOutput(path, false, 0)
+	if err != nil {
+		t.Fatalf("setupLogOutput failed: %v", err)
+	}
+	defer w.Close()
+
+	log.Print("test message")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "test message") {
+		t.Fatalf("expected the log file to contain the logged message, got %q", data)
+	}
+}
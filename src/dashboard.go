@@ -1,12 +1,24 @@
 package main
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"flag"
+	"fmt"
 	"html/template"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gorilla/websocket"
 )
 
 type Speciality struct {
@@ -37,6 +49,7 @@ type Edge struct {
 	Type      string  `json:"type"`
 	BidValue  float64 `json:"bid_value,omitempty"`
 	Reasoning string  `json:"reasoning,omitempty"`
+	Timestamp string  `json:"timestamp,omitempty"` // RFC3339; absent on untimestamped data
 }
 
 type Network struct {
@@ -45,10 +58,49 @@ type Network struct {
 }
 
 var (
-	mu      sync.Mutex
-	network Network
+	mu          sync.Mutex
+	network     Network
+	degreeCache map[string]int
 )
 
+// currencySymbol prefixes monetary values (bid prices, won value) in the
+// dashboard HTML. Set from -currency in main(); defaults to "$" for tests
+// and other entry points that skip flag parsing.
+var currencySymbol = "$"
+
+// computeDegreesFor returns the degree of every node in n, counting each edge
+// endpoint once regardless of edge type.
+func computeDegreesFor(n Network) map[string]int {
+	degrees := make(map[string]int, len(n.Nodes))
+	for _, node := range n.Nodes {
+		degrees[node.ID] = 0
+	}
+	for _, e := range n.Edges {
+		if _, ok := degrees[e.Source]; ok {
+			degrees[e.Source]++
+		}
+		if _, ok := degrees[e.Target]; ok {
+			degrees[e.Target]++
+		}
+	}
+	return degrees
+}
+
+// computeDegrees returns the degree of every node in network, counting each
+// edge endpoint once regardless of edge type. Callers must hold mu.
+func computeDegrees() map[string]int {
+	return computeDegreesFor(network)
+}
+
+// cachedDegrees returns the memoized degree map, computing it on first use.
+// Callers must hold mu.
+func cachedDegrees() map[string]int {
+	if degreeCache == nil {
+		degreeCache = computeDegrees()
+	}
+	return degreeCache
+}
+
 func serveDashboard(w http.ResponseWriter, r *http.Request) {
 	mu.Lock()
 	networkJSON, err := json.Marshal(network)
@@ -59,27 +111,2849 @@ func serveDashboard(w http.ResponseWriter, r *http.Request) {
 	page := dashboardHTML
 	tmpl := template.Must(template.New("dashboard").Parse(page))
 	err = tmpl.Execute(w, map[string]interface{}{
-		"Network": template.JS(networkJSON),
+		"Network":        template.JS(networkJSON),
+		"CurrencySymbol": template.JSStr(currencySymbol),
 	})
 	if err != nil {
 		log.Printf("[serveDashboard] Failed to execute template: %v", err)
 	}
 }
 
-func main() {
-	raw, err := os.ReadFile("data/baseline_network.json")
+// nodesByID indexes the current network's nodes for lookups by ID.
+// Callers must hold mu while the returned map is in use if network can change concurrently.
+func nodesByID() map[string]Node {
+	byID := make(map[string]Node, len(network.Nodes))
+	for _, n := range network.Nodes {
+		byID[n.ID] = n
+	}
+	return byID
+}
+
+// bidsByTask groups "bid" edges by their target task ID.
+func bidsByTask() map[string][]Edge {
+	byTask := make(map[string][]Edge)
+	for _, e := range network.Edges {
+		if e.Type == "bid" {
+			byTask[e.Target] = append(byTask[e.Target], e)
+		}
+	}
+	return byTask
+}
+
+// isSpecialist reports whether agentID declares speciality among its Specialities.
+func isSpecialist(byID map[string]Node, agentID, speciality string) bool {
+	if speciality == "" {
+		return false
+	}
+	agent, ok := byID[agentID]
+	if !ok {
+		return false
+	}
+	for _, s := range agent.Specialities {
+		if s.Name == speciality {
+			return true
+		}
+	}
+	return false
+}
+
+// taskSpeciality returns the speciality name declared on a task node, or "".
+func taskSpeciality(byID map[string]Node, taskID string) string {
+	n, ok := byID[taskID]
+	if !ok || n.Speciality == nil {
+		return ""
+	}
+	return n.Speciality.Name
+}
+
+// sortedBids returns bids ordered by BidValue ascending, tie-broken by agent ID
+// so winner selection is deterministic.
+func sortedBids(bids []Edge) []Edge {
+	sorted := make([]Edge, len(bids))
+	copy(sorted, bids)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].BidValue != sorted[j].BidValue {
+			return sorted[i].BidValue < sorted[j].BidValue
+		}
+		return sorted[i].Source < sorted[j].Source
+	})
+	return sorted
+}
+
+// resolveLowestBid picks the lowest-priced bid for a task.
+func resolveLowestBid(bids []Edge) Edge {
+	return sortedBids(bids)[0]
+}
+
+// resolveSpecialistPreferred picks a specialist among bids within tolerance of the
+// lowest bid, falling back to the lowest bid when no specialist qualifies.
+func resolveSpecialistPreferred(byID map[string]Node, taskID string, bids []Edge, tolerance float64) Edge {
+	sorted := sortedBids(bids)
+	lowest := sorted[0]
+	speciality := taskSpeciality(byID, taskID)
+	threshold := lowest.BidValue * (1 + tolerance)
+	for _, b := range sorted {
+		if b.BidValue > threshold {
+			break
+		}
+		if isSpecialist(byID, b.Source, speciality) {
+			return b
+		}
+	}
+	return lowest
+}
+
+// winResolutionSummary is the outcome of clearing every task's bids under one policy.
+type winResolutionSummary struct {
+	TotalCost         float64 `json:"total_cost"`
+	SpecialistWinRate float64 `json:"specialist_win_rate"`
+}
+
+// specialistWinRate reports the fraction of winners, among those in winnerByTask,
+// that are specialists for the task they won.
+func specialistWinRate(byID map[string]Node, winnerByTask map[string]Edge) float64 {
+	if len(winnerByTask) == 0 {
+		return 0
+	}
+	specialists := 0
+	for taskID, winner := range winnerByTask {
+		if isSpecialist(byID, winner.Source, taskSpeciality(byID, taskID)) {
+			specialists++
+		}
+	}
+	return float64(specialists) / float64(len(winnerByTask))
+}
+
+func summarizeWinners(byID map[string]Node, winners map[string]Edge) winResolutionSummary {
+	var total float64
+	for _, w := range winners {
+		total += w.BidValue
+	}
+	return winResolutionSummary{
+		TotalCost:         total,
+		SpecialistWinRate: specialistWinRate(byID, winners),
+	}
+}
+
+// handleSpecialistPreference compares pure lowest-bid resolution against a
+// specialist-preferred policy that, within `tolerance` of the lowest bid,
+// favors a bidder who specializes in the task's speciality.
+func handleSpecialistPreference(w http.ResponseWriter, r *http.Request) {
+	tolerance := 0.05
+	if v := r.URL.Query().Get("tolerance"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, "invalid tolerance", http.StatusBadRequest)
+			return
+		}
+		tolerance = parsed
+	}
+
+	mu.Lock()
+	byID := nodesByID()
+	byTask := bidsByTask()
+	mu.Unlock()
+
+	lowestWinners := make(map[string]Edge, len(byTask))
+	preferredWinners := make(map[string]Edge, len(byTask))
+	for taskID, bids := range byTask {
+		if len(bids) == 0 {
+			continue
+		}
+		lowestWinners[taskID] = resolveLowestBid(bids)
+		preferredWinners[taskID] = resolveSpecialistPreferred(byID, taskID, bids, tolerance)
+	}
+
+	resp := struct {
+		Tolerance           float64              `json:"tolerance"`
+		LowestBid           winResolutionSummary `json:"lowest_bid"`
+		SpecialistPreferred winResolutionSummary `json:"specialist_preferred"`
+	}{
+		Tolerance:           tolerance,
+		LowestBid:           summarizeWinners(byID, lowestWinners),
+		SpecialistPreferred: summarizeWinners(byID, preferredWinners),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleSpecialistPreference] Failed to encode response: %v", err)
+	}
+}
+
+// AgentMetrics summarizes one agent's bidding performance across the network.
+type AgentMetrics struct {
+	AgentID       string  `json:"agent_id"`
+	BidsSubmitted int     `json:"bids_submitted"`
+	BidsWon       int     `json:"bids_won"`
+	WinRate       float64 `json:"win_rate"`
+}
+
+// MarketMetrics aggregates per-agent performance into market-wide figures.
+type MarketMetrics struct {
+	Agents              []AgentMetrics `json:"agents"`
+	WinRateDistribution []float64      `json:"win_rate_distribution"`
+	SkillCoverage       float64        `json:"skill_coverage"`
+}
+
+// computeSkillCoverage returns the fraction of distinct skills demanded by
+// "issue" nodes (via Speciality.Name) that are supplied by at least one
+// "agent" node (via Specialities[].Name). A value below 1 means some
+// demanded skills have no qualified agent. Returns 1 when no skills are
+// demanded, since there is no gap to report.
+func computeSkillCoverage(n Network) float64 {
+	demanded := make(map[string]bool)
+	supplied := make(map[string]bool)
+	for _, node := range n.Nodes {
+		if node.Type == "issue" && node.Speciality != nil && node.Speciality.Name != "" {
+			demanded[node.Speciality.Name] = true
+		}
+		if node.Type == "agent" {
+			for _, s := range node.Specialities {
+				supplied[s.Name] = true
+			}
+		}
+	}
+	if len(demanded) == 0 {
+		return 1
+	}
+	covered := 0
+	for skill := range demanded {
+		if supplied[skill] {
+			covered++
+		}
+	}
+	return float64(covered) / float64(len(demanded))
+}
+
+// normalizeLinkDirection reorients n's "bid" and "assigned" edges to their
+// canonical direction based on endpoint node roles - "assigned" edges run
+// task -> agent, "bid" edges run agent -> task - so a reversed edge in
+// upstream data (e.g. a generator emitting agent -> task for "assigned")
+// doesn't silently break code that assumes a fixed direction, such as
+// computeMarketMetricsFor counting "assigned" wins by e.Target. Edges of
+// other types, or whose endpoints aren't found among n's nodes, pass through
+// unchanged. Logs how many edges it corrected.
+func normalizeLinkDirection(n Network) Network {
+	nodeType := make(map[string]string, len(n.Nodes))
+	for _, node := range n.Nodes {
+		nodeType[node.ID] = node.Type
+	}
+
+	corrections := 0
+	edges := make([]Edge, len(n.Edges))
+	for i, e := range n.Edges {
+		edges[i] = e
+		switch e.Type {
+		case "assigned":
+			if nodeType[e.Source] == "agent" && nodeType[e.Target] == "issue" {
+				edges[i].Source, edges[i].Target = e.Target, e.Source
+				corrections++
+			}
+		case "bid":
+			if nodeType[e.Source] == "issue" && nodeType[e.Target] == "agent" {
+				edges[i].Source, edges[i].Target = e.Target, e.Source
+				corrections++
+			}
+		}
+	}
+	if corrections > 0 {
+		log.Printf("[normalizeLinkDirection] Reoriented %d edge(s) to their canonical direction", corrections)
+	}
+	n.Edges = edges
+	return n
+}
+
+// computeMarketMetricsFor derives per-agent bid/win counts from n's "bid" and
+// "assigned" edges ("assigned" edges run task -> winning agent).
+func computeMarketMetricsFor(n Network) MarketMetrics {
+	bidsByAgent := make(map[string]int)
+	winsByAgent := make(map[string]int)
+	for _, e := range n.Edges {
+		switch e.Type {
+		case "bid":
+			bidsByAgent[e.Source]++
+		case "assigned":
+			winsByAgent[e.Target]++
+		}
+	}
+
+	agentIDs := make([]string, 0, len(bidsByAgent))
+	for id := range bidsByAgent {
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Strings(agentIDs)
+
+	metrics := MarketMetrics{
+		Agents:              make([]AgentMetrics, 0, len(agentIDs)),
+		WinRateDistribution: make([]float64, 0, len(agentIDs)),
+		SkillCoverage:       computeSkillCoverage(n),
+	}
+	for _, id := range agentIDs {
+		submitted := bidsByAgent[id]
+		won := winsByAgent[id]
+		var winRate float64
+		if submitted > 0 {
+			winRate = float64(won) / float64(submitted)
+		}
+		metrics.Agents = append(metrics.Agents, AgentMetrics{
+			AgentID:       id,
+			BidsSubmitted: submitted,
+			BidsWon:       won,
+			WinRate:       winRate,
+		})
+		metrics.WinRateDistribution = append(metrics.WinRateDistribution, winRate)
+	}
+	return metrics
+}
+
+// computeMarketMetrics derives per-agent bid/win counts from network's "bid"
+// and "assigned" edges. Callers must hold mu.
+func computeMarketMetrics() MarketMetrics {
+	return computeMarketMetricsFor(network)
+}
+
+// ShutOutAgent summarizes an agent that bid repeatedly but never won -
+// wasted effort, or systematic outcompetition.
+type ShutOutAgent struct {
+	AgentID       string  `json:"agent_id"`
+	BidsSubmitted int     `json:"bids_submitted"`
+	TotalBidValue float64 `json:"total_bid_value"`
+}
+
+// computeShutOutAgentsFor returns agents in n with at least one "bid" edge
+// and no "assigned" edge, sorted by most bids submitted (ties broken by
+// agent ID for determinism).
+func computeShutOutAgentsFor(n Network) []ShutOutAgent {
+	bidsByAgent := make(map[string]int)
+	valueByAgent := make(map[string]float64)
+	winsByAgent := make(map[string]int)
+	for _, e := range n.Edges {
+		switch e.Type {
+		case "bid":
+			bidsByAgent[e.Source]++
+			valueByAgent[e.Source] += e.BidValue
+		case "assigned":
+			winsByAgent[e.Target]++
+		}
+	}
+
+	agentIDs := make([]string, 0, len(bidsByAgent))
+	for id := range bidsByAgent {
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Strings(agentIDs)
+
+	var shutOut []ShutOutAgent
+	for _, id := range agentIDs {
+		if winsByAgent[id] > 0 {
+			continue
+		}
+		shutOut = append(shutOut, ShutOutAgent{
+			AgentID:       id,
+			BidsSubmitted: bidsByAgent[id],
+			TotalBidValue: valueByAgent[id],
+		})
+	}
+	sort.SliceStable(shutOut, func(i, j int) bool {
+		if shutOut[i].BidsSubmitted != shutOut[j].BidsSubmitted {
+			return shutOut[i].BidsSubmitted > shutOut[j].BidsSubmitted
+		}
+		return shutOut[i].AgentID < shutOut[j].AgentID
+	})
+	return shutOut
+}
+
+// computeShutOutAgents is computeShutOutAgentsFor applied to the current
+// network. Callers must hold mu.
+func computeShutOutAgents() []ShutOutAgent {
+	return computeShutOutAgentsFor(network)
+}
+
+// advancedMetricsSnapshot bundles network-derived metrics that are expensive
+// enough to precompute once per reload rather than per request.
+type advancedMetricsSnapshot struct {
+	Network Network
+	Degrees map[string]int
+	Market  MarketMetrics
+}
+
+var (
+	snapshotMu      sync.Mutex
+	currentSnapshot *advancedMetricsSnapshot
+)
+
+// buildSnapshot computes a fresh snapshot from n. It touches no shared state,
+// so it can run concurrently with readers of the current snapshot.
+func buildSnapshot(n Network) *advancedMetricsSnapshot {
+	return &advancedMetricsSnapshot{
+		Network: n,
+		Degrees: computeDegreesFor(n),
+		Market:  computeMarketMetricsFor(n),
+	}
+}
+
+// reloadSnapshot builds a new snapshot off to the side and then atomically
+// swaps it in behind snapshotMu, so in-flight readers keep serving the old
+// snapshot until they next call getSnapshot rather than blocking on the
+// rebuild or observing a torn mix of old and new data.
+func reloadSnapshot(n Network) {
+	snap := buildSnapshot(n)
+	snapshotMu.Lock()
+	currentSnapshot = snap
+	snapshotMu.Unlock()
+}
+
+// getSnapshot returns the current advanced-metrics snapshot. The lock is held
+// only long enough to copy the pointer, not to recompute anything.
+func getSnapshot() *advancedMetricsSnapshot {
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+	return currentSnapshot
+}
+
+// reloadNetworkFromDisk re-reads and re-parses path, swapping it in as the
+// live network and rebuilding the advanced-metrics snapshot on success. A
+// failed read or parse is logged and the previous good network and snapshot
+// keep serving.
+func reloadNetworkFromDisk(path string) {
+	raw, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatalf("Failed to read baseline_network.json: %v", err)
+		log.Printf("[watch] Reload of %s failed, keeping previous snapshot: %v", path, err)
+		return
 	}
-	log.Printf("[main] Loaded baseline_network.json, %d bytes", len(raw))
-	err = json.Unmarshal(raw, &network)
+	var reloaded Network
+	if err := unmarshalJSONWithContext(path, raw, &reloaded); err != nil {
+		log.Printf("[watch] Reload of %s failed to parse, keeping previous snapshot: %v", path, err)
+		return
+	}
+	reloaded = normalizeLinkDirection(reloaded)
+
+	mu.Lock()
+	network = reloaded
+	degreeCache = nil
+	mu.Unlock()
+	reloadSnapshot(reloaded)
+	broadcastMarketMetrics()
+	log.Printf("[watch] Reloaded %s: nodes=%d, edges=%d", path, len(reloaded.Nodes), len(reloaded.Edges))
+}
+
+// watchNetworkFile watches path's directory for writes to path and reloads
+// the network once debounce has passed without another write, so a burst of
+// writes from regenerating data (which may briefly leave the file partially
+// written) only triggers one reload of the final, complete file. Runs until
+// the process exits; a failed reload just keeps the previous good network.
+func watchNetworkFile(path string, debounce time.Duration) error {
+	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		log.Fatalf("[main] Failed to unmarshal baseline_network.json: %v", err)
+		return fmt.Errorf("creating file watcher: %w", err)
 	}
-	log.Printf("[main] After load: nodes=%d, edges=%d", len(network.Nodes), len(network.Edges))
-	http.HandleFunc("/", serveDashboard)
+	dir := filepath.Dir(path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("watching %s: %w", dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(path) {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(debounce, func() {
+					reloadNetworkFromDisk(path)
+				})
+			case watchErr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("[watch] File watcher error: %v", watchErr)
+			}
+		}
+	}()
+	return nil
+}
+
+// maxWSConnections bounds concurrent /ws clients so a runaway number of
+// dashboards left open in browser tabs can't leak unbounded goroutines and
+// connections. Overridable via -max-ws-connections.
+var maxWSConnections = 100
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+var (
+	wsClientsMu sync.Mutex
+	wsClients   = make(map[*websocket.Conn]chan []byte)
+)
+
+// broadcastMarketMetrics marshals the current market metrics once and
+// pushes it to every connected /ws client. A client whose outbound buffer is
+// already full is skipped rather than blocking the broadcaster on a slow
+// reader; it will simply pick up the next update instead.
+func broadcastMarketMetrics() {
+	mu.Lock()
+	metrics := computeMarketMetrics()
+	mu.Unlock()
+
+	payload, err := json.Marshal(metrics)
+	if err != nil {
+		log.Printf("[ws] Failed to marshal market metrics: %v", err)
+		return
+	}
+
+	wsClientsMu.Lock()
+	defer wsClientsMu.Unlock()
+	for _, send := range wsClients {
+		select {
+		case send <- payload:
+		default:
+			log.Printf("[ws] Dropping update for a slow client")
+		}
+	}
+}
+
+// handleWebSocket upgrades the request to a WebSocket connection, sends the
+// current market metrics immediately, then keeps pushing whatever
+// broadcastMarketMetrics sends (e.g. after a hot-reload) until the client
+// disconnects or a write fails. Rejects the upgrade once maxWSConnections is
+// already in use.
+func handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	wsClientsMu.Lock()
+	full := len(wsClients) >= maxWSConnections
+	wsClientsMu.Unlock()
+	if full {
+		http.Error(w, "too many concurrent websocket connections", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[ws] Upgrade failed: %v", err)
+		return
+	}
+
+	send := make(chan []byte, 4)
+	wsClientsMu.Lock()
+	wsClients[conn] = send
+	wsClientsMu.Unlock()
+	defer func() {
+		wsClientsMu.Lock()
+		delete(wsClients, conn)
+		wsClientsMu.Unlock()
+		conn.Close()
+	}()
+
+	mu.Lock()
+	initial, err := json.Marshal(computeMarketMetrics())
+	mu.Unlock()
+	if err != nil {
+		log.Printf("[ws] Failed to marshal initial market metrics: %v", err)
+		return
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, initial); err != nil {
+		return
+	}
+
+	// The client isn't expected to send anything, but a connection only
+	// notices it has been closed by trying to read from it, so this drains
+	// (and discards) whatever arrives, including the close frame.
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case payload := <-send:
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-disconnected:
+			return
+		}
+	}
+}
+
+// histogramBucket is one bin of a win-rate histogram over [0, 1].
+type histogramBucket struct {
+	Lo    float64 `json:"lo"`
+	Hi    float64 `json:"hi"`
+	Count int     `json:"count"`
+}
+
+// binWinRates splits values (expected to lie in [0, 1]) into the requested
+// number of equal-width buckets spanning [0, 1], and reports mean and median.
+func binWinRates(values []float64, buckets int) ([]histogramBucket, float64, float64) {
+	bins := make([]histogramBucket, buckets)
+	width := 1.0 / float64(buckets)
+	for i := range bins {
+		bins[i] = histogramBucket{Lo: float64(i) * width, Hi: float64(i+1) * width}
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+		idx := int(v / width)
+		if idx >= buckets {
+			idx = buckets - 1
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		bins[idx].Count++
+	}
+
+	var mean, median float64
+	if len(values) > 0 {
+		mean = sum / float64(len(values))
+		sorted := make([]float64, len(values))
+		copy(sorted, values)
+		sort.Float64s(sorted)
+		mid := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			median = (sorted[mid-1] + sorted[mid]) / 2
+		} else {
+			median = sorted[mid]
+		}
+	}
+	return bins, mean, median
+}
+
+// winRateHistogramResponse is the body of /api/win-rate-histogram.
+type winRateHistogramResponse struct {
+	Buckets []histogramBucket `json:"buckets"`
+	Mean    float64           `json:"mean"`
+	Median  float64           `json:"median"`
+}
+
+// handleWinRateHistogram bins every bidding agent's win rate into the
+// requested number of buckets spanning [0, 1].
+func handleWinRateHistogram(w http.ResponseWriter, r *http.Request) {
+	buckets := 10
+	if v := r.URL.Query().Get("buckets"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid buckets", http.StatusBadRequest)
+			return
+		}
+		buckets = parsed
+	}
+
+	metrics := getSnapshot().Market
+
+	bins, mean, median := binWinRates(metrics.WinRateDistribution, buckets)
+	resp := winRateHistogramResponse{Buckets: bins, Mean: mean, Median: median}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleWinRateHistogram] Failed to encode response: %v", err)
+	}
+}
+
+// computeNodeValueAndBidCounts derives, per node, the total value of bids it
+// won (by matching "assigned" edges back to the winning "bid" edge for the
+// same agent/task pair) and the number of "bid" edges it takes part in,
+// either as bidder or as the task being bid on. Callers must hold mu.
+func computeNodeValueAndBidCounts() (map[string]float64, map[string]int) {
+	bidValue := make(map[[2]string]float64)
+	bidCount := make(map[string]int)
+	for _, e := range network.Edges {
+		if e.Type != "bid" {
+			continue
+		}
+		bidValue[[2]string{e.Source, e.Target}] = e.BidValue
+		bidCount[e.Source]++
+		bidCount[e.Target]++
+	}
+
+	value := make(map[string]float64)
+	for _, e := range network.Edges {
+		if e.Type != "assigned" {
+			continue
+		}
+		// "assigned" edges run task -> agent; the matching bid ran agent -> task.
+		value[e.Target] += bidValue[[2]string{e.Target, e.Source}]
+	}
+	return value, bidCount
+}
+
+// dataNode is a network node enriched with precomputed sizing metrics so the
+// frontend can offer multiple sizing modes without recomputing them.
+type dataNode struct {
+	Node
+	Degree   int     `json:"degree"`
+	Value    float64 `json:"value"`
+	BidCount int     `json:"bid_count"`
+}
+
+// dataResponse is the body of /data.
+type dataResponse struct {
+	Nodes []dataNode `json:"nodes"`
+	Edges []Edge     `json:"edges"`
+}
+
+// handleData serves the network enriched with per-node degree, total won
+// value, and bid count, for frontend sizing options. The response is
+// streamed straight into w via json.NewEncoder rather than json.Marshal
+// followed by w.Write, so a graph with hundreds of thousands of nodes
+// doesn't require holding a second full copy of the encoded JSON in memory.
+func handleData(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	degrees := cachedDegrees()
+	values, bidCounts := computeNodeValueAndBidCounts()
+	nodes := make([]dataNode, len(network.Nodes))
+	for i, n := range network.Nodes {
+		nodes[i] = dataNode{
+			Node:     n,
+			Degree:   degrees[n.ID],
+			Value:    values[n.ID],
+			BidCount: bidCounts[n.ID],
+		}
+	}
+	edges := network.Edges
+	mu.Unlock()
+
+	resp := dataResponse{Nodes: nodes, Edges: edges}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleData] Failed to encode response: %v", err)
+	}
+}
+
+// ConsistencyCheck compares two independently-derived computations of the
+// same metric, which should agree unless one of them has drifted.
+type ConsistencyCheck struct {
+	Name       string  `json:"name"`
+	ValueA     float64 `json:"value_a"`
+	ValueB     float64 `json:"value_b"`
+	Consistent bool    `json:"consistent"`
+}
+
+// checkConsistency reports whether a and b agree within tolerance.
+func checkConsistency(name string, a, b, tolerance float64) ConsistencyCheck {
+	return ConsistencyCheck{
+		Name:       name,
+		ValueA:     a,
+		ValueB:     b,
+		Consistent: math.Abs(a-b) <= tolerance,
+	}
+}
+
+// computeConsistencyChecks recomputes a few metrics via independent methods
+// that should always agree, as a guardrail against formula drift between
+// them: network density from the raw edge count vs. from summed node degree,
+// and total bids counted directly vs. counted via bidsByTask groupings.
+// Callers must hold mu.
+func computeConsistencyChecks(tolerance float64) []ConsistencyCheck {
+	n := len(network.Nodes)
+	edgeCount := len(network.Edges)
+	degrees := cachedDegrees()
+	degreeSum := 0
+	for _, d := range degrees {
+		degreeSum += d
+	}
+
+	var densityViaEdges, densityViaDegrees float64
+	if n > 1 {
+		densityViaEdges = float64(edgeCount) / float64(n*(n-1))
+		densityViaDegrees = float64(degreeSum) / float64(2*n*(n-1))
+	}
+
+	bidsDirect := 0
+	for _, e := range network.Edges {
+		if e.Type == "bid" {
+			bidsDirect++
+		}
+	}
+	bidsViaGroups := 0
+	for _, bids := range bidsByTask() {
+		bidsViaGroups += len(bids)
+	}
+
+	return []ConsistencyCheck{
+		checkConsistency("network_density", densityViaEdges, densityViaDegrees, tolerance),
+		checkConsistency("total_bids", float64(bidsDirect), float64(bidsViaGroups), tolerance),
+	}
+}
+
+// consistencyResponse is the body of /api/consistency.
+type consistencyResponse struct {
+	Tolerance       float64            `json:"tolerance"`
+	Checks          []ConsistencyCheck `json:"checks"`
+	AnyInconsistent bool               `json:"any_inconsistent"`
+}
+
+// handleConsistency recomputes key metrics via independent methods and
+// reports any pair that disagrees beyond tolerance.
+func handleConsistency(w http.ResponseWriter, r *http.Request) {
+	tolerance := 1e-9
+	if v := r.URL.Query().Get("tolerance"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, "invalid tolerance", http.StatusBadRequest)
+			return
+		}
+		tolerance = parsed
+	}
+
+	mu.Lock()
+	checks := computeConsistencyChecks(tolerance)
+	mu.Unlock()
+
+	anyInconsistent := false
+	for _, c := range checks {
+		if !c.Consistent {
+			anyInconsistent = true
+			break
+		}
+	}
+
+	resp := consistencyResponse{Tolerance: tolerance, Checks: checks, AnyInconsistent: anyInconsistent}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleConsistency] Failed to encode response: %v", err)
+	}
+}
+
+// Auction failure reasons classify why an unassigned task never cleared.
+const (
+	reasonNoBidders          = "no_bidders"
+	reasonAllBidsOverReserve = "all_bids_over_reserve"
+	reasonMinBiddersNotMet   = "min_bidders_not_met"
+)
+
+// assignedTasks returns the set of task IDs with an "assigned" edge, i.e.
+// those that cleared the auction. Callers must hold mu.
+func assignedTasks() map[string]bool {
+	assigned := make(map[string]bool)
+	for _, e := range network.Edges {
+		if e.Type == "assigned" {
+			assigned[e.Source] = true
+		}
+	}
+	return assigned
+}
+
+// classifyAuctionFailure reports why task (an "issue" node with no "assigned"
+// edge) failed to clear: no bids at all, every bid exceeded the task's
+// reserve price (PriceMax, when set), or too few bidders participated.
+// Returns "" if none of those conditions apply.
+func classifyAuctionFailure(task Node, bids []Edge, minBidders int) string {
+	if len(bids) == 0 {
+		return reasonNoBidders
+	}
+	if task.PriceMax > 0 {
+		allOverReserve := true
+		for _, b := range bids {
+			if b.BidValue <= task.PriceMax {
+				allOverReserve = false
+				break
+			}
+		}
+		if allOverReserve {
+			return reasonAllBidsOverReserve
+		}
+	}
+	if len(bids) < minBidders {
+		return reasonMinBiddersNotMet
+	}
+	return ""
+}
+
+// auctionFailureBreakdown groups unassigned tasks by why their auction failed.
+type auctionFailureBreakdown struct {
+	Reason string   `json:"reason"`
+	Count  int      `json:"count"`
+	Tasks  []string `json:"tasks"`
+}
+
+// auctionFailuresResponse is the body of /api/auction-failures.
+type auctionFailuresResponse struct {
+	MinBidders int                       `json:"min_bidders"`
+	Breakdown  []auctionFailureBreakdown `json:"breakdown"`
+}
+
+// handleAuctionFailures categorizes every unassigned task by why its auction
+// failed to clear: no bidders, every bid over the task's reserve price, or
+// fewer than min_bidders bidders participating.
+func handleAuctionFailures(w http.ResponseWriter, r *http.Request) {
+	minBidders := 1
+	if v := r.URL.Query().Get("min_bidders"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid min_bidders", http.StatusBadRequest)
+			return
+		}
+		minBidders = parsed
+	}
+
+	mu.Lock()
+	assigned := assignedTasks()
+	byTask := bidsByTask()
+	var tasks []Node
+	for _, n := range network.Nodes {
+		if n.Type == "issue" && !assigned[n.ID] {
+			tasks = append(tasks, n)
+		}
+	}
+	mu.Unlock()
+
+	byReason := make(map[string][]string)
+	for _, task := range tasks {
+		reason := classifyAuctionFailure(task, byTask[task.ID], minBidders)
+		if reason == "" {
+			continue
+		}
+		byReason[reason] = append(byReason[reason], task.ID)
+	}
+
+	breakdown := make([]auctionFailureBreakdown, 0, len(byReason))
+	for _, reason := range []string{reasonNoBidders, reasonAllBidsOverReserve, reasonMinBiddersNotMet} {
+		taskIDs := byReason[reason]
+		if len(taskIDs) == 0 {
+			continue
+		}
+		sort.Strings(taskIDs)
+		breakdown = append(breakdown, auctionFailureBreakdown{Reason: reason, Count: len(taskIDs), Tasks: taskIDs})
+	}
+
+	resp := auctionFailuresResponse{MinBidders: minBidders, Breakdown: breakdown}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleAuctionFailures] Failed to encode response: %v", err)
+	}
+}
+
+// lineAndColumn converts a byte offset into data into a 1-indexed line and
+// column, for reporting JSON errors with human-readable location context.
+func lineAndColumn(data []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// unmarshalJSONWithContext wraps json.Unmarshal errors with filename and, for
+// offset-carrying errors, line/column context, so callers don't have to
+// puzzle out a bare "invalid character ... at offset N".
+func unmarshalJSONWithContext(filename string, data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	line, col := lineAndColumn(data, offset)
+	return fmt.Errorf("%s:%d:%d: %w", filename, line, col, err)
+}
+
+// parseEdgeTimestamp parses e's RFC3339 timestamp, reporting false when it is
+// absent or malformed so callers can treat the edge as untimestamped.
+func parseEdgeTimestamp(e Edge) (time.Time, bool) {
+	if e.Timestamp == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, e.Timestamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// AgentTimeMetrics characterizes how quickly an agent becomes productive.
+// Fields are nil when the underlying edges carry no timestamps.
+type AgentTimeMetrics struct {
+	AgentID        string   `json:"agent_id"`
+	TimeToFirstWin *float64 `json:"time_to_first_win,omitempty"` // seconds, first bid -> first win
+	ActivitySpan   *float64 `json:"activity_span,omitempty"`     // seconds, first -> last activity
+}
+
+type agentActivity struct {
+	firstBid, firstWin, firstActivity, lastActivity time.Time
+	hasBid, hasWin, hasActivity                     bool
+}
+
+// computeAgentTimeMetrics derives time-to-first-win and activity-span per
+// agent from timestamped "bid" and "assigned" edges. Callers must hold mu.
+func computeAgentTimeMetrics() []AgentTimeMetrics {
+	activity := make(map[string]*agentActivity)
+	get := func(id string) *agentActivity {
+		a, ok := activity[id]
+		if !ok {
+			a = &agentActivity{}
+			activity[id] = a
+		}
+		return a
+	}
+
+	for _, e := range network.Edges {
+		var agentID string
+		switch e.Type {
+		case "bid":
+			agentID = e.Source
+		case "assigned":
+			agentID = e.Target
+		default:
+			continue
+		}
+		ts, ok := parseEdgeTimestamp(e)
+		if !ok {
+			continue
+		}
+
+		a := get(agentID)
+		if !a.hasActivity || ts.Before(a.firstActivity) {
+			a.firstActivity = ts
+		}
+		if !a.hasActivity || ts.After(a.lastActivity) {
+			a.lastActivity = ts
+		}
+		a.hasActivity = true
+
+		if e.Type == "bid" && (!a.hasBid || ts.Before(a.firstBid)) {
+			a.firstBid = ts
+			a.hasBid = true
+		}
+		if e.Type == "assigned" && (!a.hasWin || ts.Before(a.firstWin)) {
+			a.firstWin = ts
+			a.hasWin = true
+		}
+	}
+
+	ids := make([]string, 0, len(activity))
+	for id := range activity {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	metrics := make([]AgentTimeMetrics, 0, len(ids))
+	for _, id := range ids {
+		a := activity[id]
+		m := AgentTimeMetrics{AgentID: id}
+		if a.hasBid && a.hasWin {
+			d := a.firstWin.Sub(a.firstBid).Seconds()
+			m.TimeToFirstWin = &d
+		}
+		if a.hasActivity {
+			d := a.lastActivity.Sub(a.firstActivity).Seconds()
+			m.ActivitySpan = &d
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// agentMetricsResponse is the body of /api/agent-metrics.
+type agentMetricsResponse struct {
+	Agents []AgentTimeMetrics `json:"agents"`
+}
+
+// handleAgentMetrics reports per-agent time-to-first-win and activity span.
+func handleAgentMetrics(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	agents := computeAgentTimeMetrics()
+	mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(agentMetricsResponse{Agents: agents}); err != nil {
+		log.Printf("[handleAgentMetrics] Failed to encode response: %v", err)
+	}
+}
+
+// shutOutAgentsResponse is the body of /api/shut-out-agents.
+type shutOutAgentsResponse struct {
+	Agents []ShutOutAgent `json:"agents"`
+}
+
+// handleShutOutAgents reports agents that bid at least once but never won,
+// sorted by most bids submitted.
+func handleShutOutAgents(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	agents := computeShutOutAgents()
+	mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(shutOutAgentsResponse{Agents: agents}); err != nil {
+		log.Printf("[handleShutOutAgents] Failed to encode response: %v", err)
+	}
+}
+
+// assignmentLatency is one task's time from its earliest market-entry event
+// to its assignment.
+type assignmentLatency struct {
+	TaskID  string  `json:"task_id"`
+	Seconds float64 `json:"seconds"`
+}
+
+// computeAssignmentLatencies derives, for each assigned task with the needed
+// timestamps, the time between its earliest market-entry event - a
+// timestamped "auction" edge if present, otherwise its earliest timestamped
+// "bid" edge - and its "assigned" edge. Tasks with an assignment but no
+// usable entry timestamp are excluded and counted in excluded, since latency
+// can't be measured without one.
+func computeAssignmentLatencies(n Network) (latencies []assignmentLatency, excluded int) {
+	earliestAuction := make(map[string]time.Time)
+	earliestBid := make(map[string]time.Time)
+	assignedAt := make(map[string]time.Time)
+	var assignedIDs []string
+
+	for _, e := range n.Edges {
+		ts, ok := parseEdgeTimestamp(e)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case "auction":
+			taskID := e.Target
+			if cur, seen := earliestAuction[taskID]; !seen || ts.Before(cur) {
+				earliestAuction[taskID] = ts
+			}
+		case "bid":
+			taskID := e.Target
+			if cur, seen := earliestBid[taskID]; !seen || ts.Before(cur) {
+				earliestBid[taskID] = ts
+			}
+		case "assigned":
+			taskID := e.Source
+			if _, seen := assignedAt[taskID]; !seen {
+				assignedIDs = append(assignedIDs, taskID)
+			}
+			assignedAt[taskID] = ts
+		}
+	}
+
+	sort.Strings(assignedIDs)
+	for _, taskID := range assignedIDs {
+		entry, ok := earliestAuction[taskID]
+		if !ok {
+			entry, ok = earliestBid[taskID]
+		}
+		if !ok {
+			excluded++
+			continue
+		}
+		latencies = append(latencies, assignmentLatency{
+			TaskID:  taskID,
+			Seconds: assignedAt[taskID].Sub(entry).Seconds(),
+		})
+	}
+	return latencies, excluded
+}
+
+// percentile returns the value at the given percentile (0-100) of sorted,
+// ascending, non-empty values, interpolating linearly between ranks.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	if lo == hi {
+		return sorted[lo]
+	}
+	return sorted[lo] + (rank-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// histogramFor splits values into the requested number of equal-width
+// buckets spanning [min(values), max(values)]. Returns nil for an empty
+// input; puts every value in the first bucket when they're all identical.
+func histogramFor(values []float64, buckets int) []histogramBucket {
+	if len(values) == 0 {
+		return nil
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	bins := make([]histogramBucket, buckets)
+	width := (hi - lo) / float64(buckets)
+	for i := range bins {
+		bins[i] = histogramBucket{Lo: lo + float64(i)*width, Hi: lo + float64(i+1)*width}
+	}
+	if width == 0 {
+		bins[0].Count = len(values)
+		return bins
+	}
+	for _, v := range values {
+		idx := int((v - lo) / width)
+		if idx >= buckets {
+			idx = buckets - 1
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		bins[idx].Count++
+	}
+	return bins
+}
+
+// assignmentLatencyResponse is the body of /api/assignment-latency.
+type assignmentLatencyResponse struct {
+	Latencies []assignmentLatency `json:"latencies"`
+	P50       float64             `json:"p50"`
+	P90       float64             `json:"p90"`
+	P99       float64             `json:"p99"`
+	Histogram []histogramBucket   `json:"histogram"`
+	Excluded  int                 `json:"excluded"`
+}
+
+// handleAssignmentLatency reports the distribution of time between a task
+// entering the market and being assigned, as a measure of market
+// responsiveness.
+func handleAssignmentLatency(w http.ResponseWriter, r *http.Request) {
+	buckets := 10
+	if v := r.URL.Query().Get("buckets"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid buckets", http.StatusBadRequest)
+			return
+		}
+		buckets = parsed
+	}
+
+	mu.Lock()
+	latencies, excluded := computeAssignmentLatencies(network)
+	mu.Unlock()
+
+	seconds := make([]float64, len(latencies))
+	for i, l := range latencies {
+		seconds[i] = l.Seconds
+	}
+	sort.Float64s(seconds)
+
+	resp := assignmentLatencyResponse{
+		Latencies: latencies,
+		Excluded:  excluded,
+		Histogram: histogramFor(seconds, buckets),
+	}
+	if len(seconds) > 0 {
+		resp.P50 = percentile(seconds, 50)
+		resp.P90 = percentile(seconds, 90)
+		resp.P99 = percentile(seconds, 99)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleAssignmentLatency] Failed to encode response: %v", err)
+	}
+}
+
+// leaderboardWeights controls how win rate, total won value, and
+// specialization combine into the composite score in /api/agent-leaderboard.
+type leaderboardWeights struct {
+	WinRate        float64
+	Value          float64
+	Specialization float64
+}
+
+// currentLeaderboardWeights is set from flags in main(); the defaults here
+// apply to tests and to other entry points that skip flag parsing.
+var currentLeaderboardWeights = leaderboardWeights{WinRate: 0.4, Value: 0.4, Specialization: 0.2}
+
+// AgentLeaderboardEntry is one agent's composite performance score, with the
+// component values that produced it.
+type AgentLeaderboardEntry struct {
+	AgentID             string  `json:"agent_id"`
+	WinRate             float64 `json:"win_rate"`
+	TotalValue          float64 `json:"total_value"`
+	SpecializationScore float64 `json:"specialization_score"`
+	Composite           float64 `json:"composite"`
+}
+
+// computeAgentLeaderboard ranks agents by a weighted composite of win rate,
+// total won value (normalized against the highest-earning agent), and
+// specialization (the fraction of their wins within their declared
+// specialities). Ties break deterministically by agent ID. Callers must hold mu.
+func computeAgentLeaderboard(weights leaderboardWeights) []AgentLeaderboardEntry {
+	market := computeMarketMetrics()
+	values, _ := computeNodeValueAndBidCounts()
+	byID := nodesByID()
+
+	winningTasksByAgent := make(map[string][]string)
+	for _, e := range network.Edges {
+		if e.Type == "assigned" {
+			winningTasksByAgent[e.Target] = append(winningTasksByAgent[e.Target], e.Source)
+		}
+	}
+
+	maxValue := 0.0
+	for _, m := range market.Agents {
+		if v := values[m.AgentID]; v > maxValue {
+			maxValue = v
+		}
+	}
+
+	entries := make([]AgentLeaderboardEntry, 0, len(market.Agents))
+	for _, m := range market.Agents {
+		tasks := winningTasksByAgent[m.AgentID]
+		specialistWins := 0
+		for _, taskID := range tasks {
+			if isSpecialist(byID, m.AgentID, taskSpeciality(byID, taskID)) {
+				specialistWins++
+			}
+		}
+		var specializationScore float64
+		if len(tasks) > 0 {
+			specializationScore = float64(specialistWins) / float64(len(tasks))
+		}
+
+		totalValue := values[m.AgentID]
+		var normalizedValue float64
+		if maxValue > 0 {
+			normalizedValue = totalValue / maxValue
+		}
+
+		composite := weights.WinRate*m.WinRate + weights.Value*normalizedValue + weights.Specialization*specializationScore
+		entries = append(entries, AgentLeaderboardEntry{
+			AgentID:             m.AgentID,
+			WinRate:             m.WinRate,
+			TotalValue:          totalValue,
+			SpecializationScore: specializationScore,
+			Composite:           composite,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Composite != entries[j].Composite {
+			return entries[i].Composite > entries[j].Composite
+		}
+		return entries[i].AgentID < entries[j].AgentID
+	})
+	return entries
+}
+
+// agentLeaderboardResponse is the body of /api/agent-leaderboard.
+type agentLeaderboardResponse struct {
+	Weights leaderboardWeights      `json:"weights"`
+	Agents  []AgentLeaderboardEntry `json:"agents"`
+}
+
+// handleAgentLeaderboard ranks agents by composite performance score.
+func handleAgentLeaderboard(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	entries := computeAgentLeaderboard(currentLeaderboardWeights)
+	mu.Unlock()
+
+	resp := agentLeaderboardResponse{Weights: currentLeaderboardWeights, Agents: entries}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleAgentLeaderboard] Failed to encode response: %v", err)
+	}
+}
+
+// clearingPrices returns, per task, the value of the bid that won its
+// auction (the bid matching the task's "assigned" edge). Tasks that never
+// cleared are absent. Callers must hold mu.
+func clearingPrices() map[string]float64 {
+	bidValue := make(map[[2]string]float64)
+	for _, e := range network.Edges {
+		if e.Type == "bid" {
+			bidValue[[2]string{e.Source, e.Target}] = e.BidValue
+		}
+	}
+	prices := make(map[string]float64)
+	for _, e := range network.Edges {
+		if e.Type != "assigned" {
+			continue
+		}
+		// "assigned" edges run task -> agent; the matching bid ran agent -> task.
+		if price, ok := bidValue[[2]string{e.Target, e.Source}]; ok {
+			prices[e.Source] = price
+		}
+	}
+	return prices
+}
+
+// BidShadingMetric reports how an agent's bids compare, on average, to the
+// eventual clearing price of the tasks they bid on. A positive
+// AverageMarkup means the agent tends to bid above clearing price
+// (shading upward); negative means they tend to bid below it.
+type BidShadingMetric struct {
+	AgentID       string  `json:"agent_id"`
+	AverageMarkup float64 `json:"average_markup"`
+}
+
+// computeBidShading averages, per agent, markup = (bid - clearingPrice) /
+// clearingPrice over every bid the agent placed on a task that eventually
+// cleared. Bids on tasks that never cleared, or with a zero clearing price,
+// are skipped, as are agents left with no qualifying bids. Callers must
+// hold mu.
+func computeBidShading() []BidShadingMetric {
+	prices := clearingPrices()
+
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, e := range network.Edges {
+		if e.Type != "bid" {
+			continue
+		}
+		price, ok := prices[e.Target]
+		if !ok || price == 0 {
+			continue
+		}
+		markup := (e.BidValue - price) / price
+		sums[e.Source] += markup
+		counts[e.Source]++
+	}
+
+	agentIDs := make([]string, 0, len(sums))
+	for id := range sums {
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Strings(agentIDs)
+
+	metrics := make([]BidShadingMetric, 0, len(agentIDs))
+	for _, id := range agentIDs {
+		metrics = append(metrics, BidShadingMetric{
+			AgentID:       id,
+			AverageMarkup: sums[id] / float64(counts[id]),
+		})
+	}
+	return metrics
+}
+
+// bidShadingResponse is the body of /api/bid-shading.
+type bidShadingResponse struct {
+	Agents []BidShadingMetric `json:"agents"`
+}
+
+// handleBidShading reports each agent's average markup relative to the
+// clearing price of the tasks they bid on, as a signal of strategic
+// bid-shading.
+func handleBidShading(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	agents := computeBidShading()
+	mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(bidShadingResponse{Agents: agents}); err != nil {
+		log.Printf("[handleBidShading] Failed to encode response: %v", err)
+	}
+}
+
+// TaskBidSpread reports how tightly agents competed for a task: the
+// fractional gap between the highest and lowest bid, relative to the lowest.
+type TaskBidSpread struct {
+	TaskID  string  `json:"task_id"`
+	Spread  float64 `json:"spread"`
+	NumBids int     `json:"num_bids"`
+	MinBid  float64 `json:"min_bid"`
+	MaxBid  float64 `json:"max_bid"`
+}
+
+// computeBidSpreads reports (maxBid-minBid)/minBid per task, skipping tasks
+// with fewer than 2 bids or a zero minimum bid, sorted by tightest
+// competition (lowest spread) first, tie-broken by task ID. Callers must
+// hold mu.
+func computeBidSpreads() []TaskBidSpread {
+	var spreads []TaskBidSpread
+	for taskID, bids := range bidsByTask() {
+		if len(bids) < 2 {
+			continue
+		}
+		min, max := bids[0].BidValue, bids[0].BidValue
+		for _, b := range bids[1:] {
+			if b.BidValue < min {
+				min = b.BidValue
+			}
+			if b.BidValue > max {
+				max = b.BidValue
+			}
+		}
+		if min == 0 {
+			continue
+		}
+		spreads = append(spreads, TaskBidSpread{
+			TaskID:  taskID,
+			Spread:  (max - min) / min,
+			NumBids: len(bids),
+			MinBid:  min,
+			MaxBid:  max,
+		})
+	}
+
+	sort.Slice(spreads, func(i, j int) bool {
+		if spreads[i].Spread != spreads[j].Spread {
+			return spreads[i].Spread < spreads[j].Spread
+		}
+		return spreads[i].TaskID < spreads[j].TaskID
+	})
+	return spreads
+}
+
+// bidSpreadResponse is the body of /api/bid-spread.
+type bidSpreadResponse struct {
+	Tasks []TaskBidSpread `json:"tasks"`
+}
+
+// handleBidSpread reports each task's bid spread, tightest competition first.
+func handleBidSpread(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	tasks := computeBidSpreads()
+	mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(bidSpreadResponse{Tasks: tasks}); err != nil {
+		log.Printf("[handleBidSpread] Failed to encode response: %v", err)
+	}
+}
+
+// paretoMetricValues returns the value of every metric usable as a Pareto
+// axis for a single agent, keyed by metric name.
+func paretoMetricValues(m AgentMetrics, avgBidValue float64) map[string]float64 {
+	return map[string]float64{
+		"bids_submitted": float64(m.BidsSubmitted),
+		"bids_won":       float64(m.BidsWon),
+		"win_rate":       m.WinRate,
+		"avg_bid_value":  avgBidValue,
+	}
+}
+
+// paretoMetricNames lists the metric names valid as /api/pareto axes.
+var paretoMetricNames = map[string]bool{
+	"bids_submitted": true,
+	"bids_won":       true,
+	"win_rate":       true,
+	"avg_bid_value":  true,
+}
+
+// ParetoAgent is one agent's position on the requested x/y metrics, and
+// whether another agent dominates it on both axes.
+type ParetoAgent struct {
+	AgentID   string  `json:"agent_id"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Dominated bool    `json:"dominated"`
+}
+
+// computeParetoFront ranks agents on xMetric (minimized) and yMetric
+// (maximized) and marks which are dominated: an agent is dominated if some
+// other agent is at least as good on both axes and strictly better on one.
+func computeParetoFront(xMetric, yMetric string) ([]ParetoAgent, error) {
+	if !paretoMetricNames[xMetric] {
+		return nil, fmt.Errorf("unknown metric %q", xMetric)
+	}
+	if !paretoMetricNames[yMetric] {
+		return nil, fmt.Errorf("unknown metric %q", yMetric)
+	}
+
+	metrics := computeMarketMetrics()
+	bidValueSum := make(map[string]float64)
+	bidCount := make(map[string]int)
+	for _, e := range network.Edges {
+		if e.Type == "bid" {
+			bidValueSum[e.Source] += e.BidValue
+			bidCount[e.Source]++
+		}
+	}
+
+	agents := make([]ParetoAgent, 0, len(metrics.Agents))
+	for _, m := range metrics.Agents {
+		avgBidValue := 0.0
+		if bidCount[m.AgentID] > 0 {
+			avgBidValue = bidValueSum[m.AgentID] / float64(bidCount[m.AgentID])
+		}
+		values := paretoMetricValues(m, avgBidValue)
+		agents = append(agents, ParetoAgent{AgentID: m.AgentID, X: values[xMetric], Y: values[yMetric]})
+	}
+
+	for i := range agents {
+		for j := range agents {
+			if i == j {
+				continue
+			}
+			b := agents[j]
+			a := agents[i]
+			if b.X <= a.X && b.Y >= a.Y && (b.X < a.X || b.Y > a.Y) {
+				agents[i].Dominated = true
+				break
+			}
+		}
+	}
+
+	sort.Slice(agents, func(i, j int) bool { return agents[i].AgentID < agents[j].AgentID })
+	return agents, nil
+}
+
+// paretoResponse is the body of /api/pareto.
+type paretoResponse struct {
+	XMetric string        `json:"x_metric"`
+	YMetric string        `json:"y_metric"`
+	Agents  []ParetoAgent `json:"agents"`
+}
+
+// handlePareto computes the Pareto front of agents on the two metrics named
+// by the ?x= and ?y= query parameters, minimizing x and maximizing y.
+func handlePareto(w http.ResponseWriter, r *http.Request) {
+	xMetric := r.URL.Query().Get("x")
+	yMetric := r.URL.Query().Get("y")
+	if xMetric == "" || yMetric == "" {
+		http.Error(w, "missing required \"x\" and \"y\" query parameters", http.StatusBadRequest)
+		return
+	}
+
+	mu.Lock()
+	agents, err := computeParetoFront(xMetric, yMetric)
+	mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(paretoResponse{XMetric: xMetric, YMetric: yMetric, Agents: agents}); err != nil {
+		log.Printf("[handlePareto] Failed to encode response: %v", err)
+	}
+}
+
+// computeEngagementMatrix mirrors the dashboard's agent-skill participation
+// heatmap: for every "bid" edge from an agent to an issue with a declared
+// speciality, increment that agent/skill cell. Agents and skills are
+// returned sorted for deterministic iteration.
+func computeEngagementMatrix() (agentIDs, skills []string, matrix map[string]map[string]int) {
+	byID := nodesByID()
+	skillSet := make(map[string]bool)
+	for _, n := range network.Nodes {
+		if n.Type == "issue" && n.Speciality != nil {
+			skillSet[n.Speciality.Name] = true
+		}
+	}
+	for s := range skillSet {
+		skills = append(skills, s)
+	}
+	sort.Strings(skills)
+
+	matrix = make(map[string]map[string]int)
+	for _, n := range network.Nodes {
+		if n.Type == "agent" {
+			agentIDs = append(agentIDs, n.ID)
+			row := make(map[string]int, len(skills))
+			for _, s := range skills {
+				row[s] = 0
+			}
+			matrix[n.ID] = row
+		}
+	}
+	sort.Strings(agentIDs)
+
+	for _, e := range network.Edges {
+		if e.Type != "bid" {
+			continue
+		}
+		row, ok := matrix[e.Source]
+		if !ok {
+			continue
+		}
+		issue, ok := byID[e.Target]
+		if !ok || issue.Type != "issue" || issue.Speciality == nil {
+			continue
+		}
+		if _, ok := row[issue.Speciality.Name]; ok {
+			row[issue.Speciality.Name]++
+		}
+	}
+	return agentIDs, skills, matrix
+}
+
+// engagementMatrixResponse is the body of /api/engagement-matrix.
+type engagementMatrixResponse struct {
+	Normalize string                        `json:"normalize"`
+	Agents    []string                      `json:"agents"`
+	Skills    []string                      `json:"skills"`
+	Matrix    map[string]map[string]float64 `json:"matrix"`
+}
+
+// buildEngagementMatrixResponse normalizes the raw engagement matrix per
+// mode: "agent" divides each row by its total so rows sum to 1, "skill"
+// divides each column by its total so columns sum to 1, and "none" (or "")
+// returns raw counts. Zero-total rows/columns are left as all zeros.
+func buildEngagementMatrixResponse(agentIDs, skills []string, raw map[string]map[string]int, mode string) (engagementMatrixResponse, error) {
+	if mode == "" {
+		mode = "none"
+	}
+	if mode != "agent" && mode != "skill" && mode != "none" {
+		return engagementMatrixResponse{}, fmt.Errorf("unknown normalize mode %q", mode)
+	}
+
+	columnTotals := make(map[string]int, len(skills))
+	for _, agentID := range agentIDs {
+		for _, s := range skills {
+			columnTotals[s] += raw[agentID][s]
+		}
+	}
+
+	normalized := make(map[string]map[string]float64, len(agentIDs))
+	for _, agentID := range agentIDs {
+		row := make(map[string]float64, len(skills))
+		rowTotal := 0
+		for _, s := range skills {
+			rowTotal += raw[agentID][s]
+		}
+		for _, s := range skills {
+			v := float64(raw[agentID][s])
+			switch mode {
+			case "agent":
+				if rowTotal > 0 {
+					v /= float64(rowTotal)
+				} else {
+					v = 0
+				}
+			case "skill":
+				if columnTotals[s] > 0 {
+					v /= float64(columnTotals[s])
+				} else {
+					v = 0
+				}
+			}
+			row[s] = v
+		}
+		normalized[agentID] = row
+	}
+
+	return engagementMatrixResponse{Normalize: mode, Agents: agentIDs, Skills: skills, Matrix: normalized}, nil
+}
+
+// handleEngagementMatrix reports the agent-skill bid engagement matrix,
+// optionally normalized by the ?normalize= query parameter (agent, skill, or
+// none, the default).
+func handleEngagementMatrix(w http.ResponseWriter, r *http.Request) {
+	mode := r.URL.Query().Get("normalize")
+
+	mu.Lock()
+	agentIDs, skills, raw := computeEngagementMatrix()
+	mu.Unlock()
+
+	resp, err := buildEngagementMatrixResponse(agentIDs, skills, raw, mode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleEngagementMatrix] Failed to encode response: %v", err)
+	}
+}
+
+// degreeTailResponse summarizes the high-degree tail of the network for a threshold.
+type degreeTailResponse struct {
+	Threshold    int      `json:"threshold"`
+	Nodes        []string `json:"nodes"`
+	NodeFraction float64  `json:"node_fraction"`
+	EdgeFraction float64  `json:"edge_fraction"`
+}
+
+// handleDegreeTail reports the nodes at or above a degree threshold and how much
+// of the network's mass (nodes, edges) they account for.
+func handleDegreeTail(w http.ResponseWriter, r *http.Request) {
+	threshold := 1
+	if v := r.URL.Query().Get("threshold"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			http.Error(w, "invalid threshold", http.StatusBadRequest)
+			return
+		}
+		threshold = parsed
+	}
+
+	mu.Lock()
+	degrees := cachedDegrees()
+	totalNodes := len(network.Nodes)
+	tail := make(map[string]bool)
+	for id, d := range degrees {
+		if d >= threshold {
+			tail[id] = true
+		}
+	}
+	tailEdges := 0
+	for _, e := range network.Edges {
+		if tail[e.Source] || tail[e.Target] {
+			tailEdges++
+		}
+	}
+	totalEdges := len(network.Edges)
+	mu.Unlock()
+
+	ids := make([]string, 0, len(tail))
+	for id := range tail {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	resp := degreeTailResponse{Threshold: threshold, Nodes: ids}
+	if totalNodes > 0 {
+		resp.NodeFraction = float64(len(ids)) / float64(totalNodes)
+	}
+	if totalEdges > 0 {
+		resp.EdgeFraction = float64(tailEdges) / float64(totalEdges)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleDegreeTail] Failed to encode response: %v", err)
+	}
+}
+
+// cardinalityBucket is one bin of an assignment-count histogram: how many
+// nodes have exactly Count assignments, with the top bucket (Label ending in
+// "+") absorbing everything at or above the configured cap.
+type cardinalityBucket struct {
+	Count     int    `json:"count"`
+	Label     string `json:"label"`
+	Frequency int    `json:"frequency"`
+}
+
+// bucketAssignmentCounts buckets every ID in ids by its count in counts
+// (0 for IDs absent from counts), capping at cap so a handful of outliers
+// don't produce an unbounded number of buckets.
+func bucketAssignmentCounts(ids []string, counts map[string]int, cap int) []cardinalityBucket {
+	freq := make([]int, cap+1)
+	for _, id := range ids {
+		c := counts[id]
+		if c > cap {
+			c = cap
+		}
+		freq[c]++
+	}
+	buckets := make([]cardinalityBucket, cap+1)
+	for i := 0; i <= cap; i++ {
+		label := strconv.Itoa(i)
+		if i == cap {
+			label += "+"
+		}
+		buckets[i] = cardinalityBucket{Count: i, Label: label, Frequency: freq[i]}
+	}
+	return buckets
+}
+
+// computeAssignmentDistribution reports how "assigned" edges distribute
+// across agents (tasks won per agent, including agents with zero) and across
+// tasks (winning agents per task, including unassigned tasks at zero).
+// "assigned" edges run task -> agent. Callers must hold mu.
+func computeAssignmentDistribution(cap int) (tasksPerAgent, agentsPerTask []cardinalityBucket) {
+	assignedByAgent := make(map[string]int)
+	assignedByTask := make(map[string]int)
+	for _, e := range network.Edges {
+		if e.Type != "assigned" {
+			continue
+		}
+		assignedByTask[e.Source]++
+		assignedByAgent[e.Target]++
+	}
+
+	var agentIDs, taskIDs []string
+	for _, n := range network.Nodes {
+		switch n.Type {
+		case "agent":
+			agentIDs = append(agentIDs, n.ID)
+		case "issue":
+			taskIDs = append(taskIDs, n.ID)
+		}
+	}
+
+	tasksPerAgent = bucketAssignmentCounts(agentIDs, assignedByAgent, cap)
+	agentsPerTask = bucketAssignmentCounts(taskIDs, assignedByTask, cap)
+	return tasksPerAgent, agentsPerTask
+}
+
+// assignmentDistributionResponse is the body of /api/assignment-distribution.
+type assignmentDistributionResponse struct {
+	Cap           int                 `json:"cap"`
+	TasksPerAgent []cardinalityBucket `json:"tasks_per_agent"`
+	AgentsPerTask []cardinalityBucket `json:"agents_per_task"`
+}
+
+// handleAssignmentDistribution reports, at a glance, how many agents are
+// idle vs. overloaded and how many tasks went unassigned vs. multiply
+// assigned, by histogramming the same "assigned" edges other endpoints
+// traverse individually.
+func handleAssignmentDistribution(w http.ResponseWriter, r *http.Request) {
+	cap := 3
+	if v := r.URL.Query().Get("cap"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			http.Error(w, "invalid cap", http.StatusBadRequest)
+			return
+		}
+		cap = parsed
+	}
+
+	mu.Lock()
+	tasksPerAgent, agentsPerTask := computeAssignmentDistribution(cap)
+	mu.Unlock()
+
+	resp := assignmentDistributionResponse{Cap: cap, TasksPerAgent: tasksPerAgent, AgentsPerTask: agentsPerTask}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleAssignmentDistribution] Failed to encode response: %v", err)
+	}
+}
+
+// sparklineCategories is the canonical, stable ordering of specialty
+// categories (matching the taxonomy swe_manager_task_distribution.go assigns
+// agents and tasks from) used by /api/sparklines, so every series in a
+// response - and every response across repeated calls - lines category i up
+// with the same specialty.
+var sparklineCategories = []string{
+	"ApplicationLogic",
+	"ServerSideLogic",
+	"BugFixes",
+	"UI/UX",
+	"SystemWideQualityAndReliability",
+	"NewFeaturesOrEnhancements",
+	"ReliabilityImprovements",
+}
+
+// sparkline is one named metric's per-category series, one value per entry
+// in sparklineCategories, in order.
+type sparkline struct {
+	Metric string    `json:"metric"`
+	Values []float64 `json:"values"`
+}
+
+// sparklinesResponse is the body of /api/sparklines.
+type sparklinesResponse struct {
+	Categories []string    `json:"categories"`
+	Series     []sparkline `json:"series"`
+}
+
+// computeSparklines derives compact per-category series suitable for inline
+// dashboard-tile trend charts: how many tasks demand each category, how many
+// bids targeted them, and how many were won. Callers must hold mu.
+func computeSparklines(n Network) sparklinesResponse {
+	taskCategory := make(map[string]string)
+	demand := make(map[string]float64)
+	for _, node := range n.Nodes {
+		if node.Type == "issue" && node.Speciality != nil {
+			taskCategory[node.ID] = node.Speciality.Name
+			demand[node.Speciality.Name]++
+		}
+	}
+
+	bids := make(map[string]float64)
+	wins := make(map[string]float64)
+	for _, e := range n.Edges {
+		switch e.Type {
+		case "bid":
+			if cat, ok := taskCategory[e.Target]; ok {
+				bids[cat]++
+			}
+		case "assigned":
+			if cat, ok := taskCategory[e.Source]; ok {
+				wins[cat]++
+			}
+		}
+	}
+
+	seriesFor := func(m map[string]float64) []float64 {
+		values := make([]float64, len(sparklineCategories))
+		for i, cat := range sparklineCategories {
+			values[i] = m[cat]
+		}
+		return values
+	}
+
+	return sparklinesResponse{
+		Categories: sparklineCategories,
+		Series: []sparkline{
+			{Metric: "demand", Values: seriesFor(demand)},
+			{Metric: "bids", Values: seriesFor(bids)},
+			{Metric: "wins", Values: seriesFor(wins)},
+		},
+	}
+}
+
+// handleSparklines serves a lightweight alternative to the full metric
+// endpoints: short per-category numeric series sized for inline sparkline
+// rendering on dashboard tiles, rather than a full breakdown.
+func handleSparklines(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	resp := computeSparklines(network)
+	mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleSparklines] Failed to encode response: %v", err)
+	}
+}
+
+// agentsDir, when set via -agents-dir, points to a directory of per-agent
+// JSON files in the format loadAgents (baseline_network.go) reads and
+// swe_manager_task_distribution.go writes, backing /api/specialty-weights.
+var agentsDir string
+
+// specialtyWeightTolerance is how far an agent's specialities' weights may
+// sum from 100 and still be considered valid, absorbing floating-point
+// rounding from upstream generation.
+const specialtyWeightTolerance = 0.5
+
+// specialtyWeightsEntry is one agent's speciality-weight breakdown, plus
+// whether its weights sum to 100 within specialtyWeightTolerance.
+type specialtyWeightsEntry struct {
+	AgentID      string       `json:"agent_id"`
+	Specialities []Speciality `json:"specialities"`
+	WeightSum    float64      `json:"weight_sum"`
+	WeightsValid bool         `json:"weights_valid"`
+}
+
+// specialtyWeightsResponse is the body of /api/specialty-weights.
+type specialtyWeightsResponse struct {
+	Agents []specialtyWeightsEntry `json:"agents"`
+}
+
+// loadAgentSpecialtyWeights reads every *.json file in dir as an agent
+// record carrying agent_id and specialities, computing each agent's weight
+// sum and flagging it if that sum strays from 100 by more than
+// specialtyWeightTolerance. Returns entries sorted by AgentID.
+func loadAgentSpecialtyWeights(dir string) ([]specialtyWeightsEntry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []specialtyWeightsEntry
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var agent struct {
+			AgentID      string       `json:"agent_id"`
+			Specialities []Speciality `json:"specialities"`
+		}
+		if err := unmarshalJSONWithContext(path, data, &agent); err != nil {
+			return nil, err
+		}
+
+		sum := 0.0
+		for _, s := range agent.Specialities {
+			sum += s.Weight
+		}
+		entries = append(entries, specialtyWeightsEntry{
+			AgentID:      agent.AgentID,
+			Specialities: agent.Specialities,
+			WeightSum:    sum,
+			WeightsValid: math.Abs(sum-100) <= specialtyWeightTolerance,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].AgentID < entries[j].AgentID })
+	return entries, nil
+}
+
+// handleSpecialtyWeights serves the complete per-agent specialty-weight
+// table backing a stacked-bar of each agent's focus, from the directory
+// configured via -agents-dir.
+func handleSpecialtyWeights(w http.ResponseWriter, r *http.Request) {
+	if agentsDir == "" {
+		http.Error(w, "no agents directory configured; start the dashboard with -agents-dir", http.StatusNotFound)
+		return
+	}
+	entries, err := loadAgentSpecialtyWeights(agentsDir)
+	if err != nil {
+		log.Printf("[handleSpecialtyWeights] Failed to load agents from %s: %v", agentsDir, err)
+		http.Error(w, "failed to load agents", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(specialtyWeightsResponse{Agents: entries}); err != nil {
+		log.Printf("[handleSpecialtyWeights] Failed to encode response: %v", err)
+	}
+}
+
+// defaultTopSkills is how many of an agent's highest-Weight specialities
+// /api/agent-profile reports in top_skills when ?top= is absent.
+const defaultTopSkills = 3
+
+// agentProfile is one agent's /api/agent-profile row: its full specialty
+// breakdown plus the top_skills highlight.
+type agentProfile struct {
+	AgentID      string       `json:"agent_id"`
+	Specialities []Speciality `json:"specialities"`
+	TopSkills    []Speciality `json:"top_skills"`
+}
+
+// topSkills returns the top n specialities by Weight descending (ties
+// broken by name for determinism), or all of them if there are fewer than
+// n.
+func topSkills(specialities []Speciality, n int) []Speciality {
+	sorted := make([]Speciality, len(specialities))
+	copy(sorted, specialities)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Weight != sorted[j].Weight {
+			return sorted[i].Weight > sorted[j].Weight
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	if n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// agentProfileResponse is the body of /api/agent-profile.
+type agentProfileResponse struct {
+	Agents []agentProfile `json:"agents"`
+}
+
+// handleAgentProfile serves each agent's specialities alongside a
+// configurable top_skills highlight (?top=N, default 3), from the
+// directory configured via -agents-dir.
+func handleAgentProfile(w http.ResponseWriter, r *http.Request) {
+	if agentsDir == "" {
+		http.Error(w, "no agents directory configured; start the dashboard with -agents-dir", http.StatusNotFound)
+		return
+	}
+
+	n := defaultTopSkills
+	if v := r.URL.Query().Get("top"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			http.Error(w, "invalid top", http.StatusBadRequest)
+			return
+		}
+		n = parsed
+	}
+
+	entries, err := loadAgentSpecialtyWeights(agentsDir)
+	if err != nil {
+		log.Printf("[handleAgentProfile] Failed to load agents from %s: %v", agentsDir, err)
+		http.Error(w, "failed to load agents", http.StatusInternalServerError)
+		return
+	}
+
+	profiles := make([]agentProfile, 0, len(entries))
+	for _, entry := range entries {
+		profiles = append(profiles, agentProfile{
+			AgentID:      entry.AgentID,
+			Specialities: entry.Specialities,
+			TopSkills:    topSkills(entry.Specialities, n),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(agentProfileResponse{Agents: profiles}); err != nil {
+		log.Printf("[handleAgentProfile] Failed to encode response: %v", err)
+	}
+}
+
+// handleAgentMetricsCSV streams computeMarketMetrics's per-agent bidding
+// metrics as text/csv, one row per agent, so they can be pulled into a
+// spreadsheet without scraping JSON. An empty dataset still emits the header
+// row alone.
+func handleAgentMetricsCSV(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	agents := computeMarketMetrics().Agents
+	mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="agent-metrics.csv"`)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"agent_id", "bids_submitted", "bids_won", "win_rate"}); err != nil {
+		log.Printf("[handleAgentMetricsCSV] Failed to write header: %v", err)
+		return
+	}
+	for _, a := range agents {
+		row := []string{
+			a.AgentID,
+			strconv.Itoa(a.BidsSubmitted),
+			strconv.Itoa(a.BidsWon),
+			strconv.FormatFloat(a.WinRate, 'f', 4, 64),
+		}
+		if err := cw.Write(row); err != nil {
+			log.Printf("[handleAgentMetricsCSV] Failed to write row for %s: %v", a.AgentID, err)
+			return
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Printf("[handleAgentMetricsCSV] Failed to flush response: %v", err)
+	}
+}
+
+// handleTaskMetricsCSV streams computeBidSpreads's per-task bid-competition
+// metrics as text/csv, one row per task, so they can be pulled into a
+// spreadsheet without scraping JSON. An empty dataset still emits the header
+// row alone.
+func handleTaskMetricsCSV(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	tasks := computeBidSpreads()
+	mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="task-metrics.csv"`)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"task_id", "spread", "num_bids", "min_bid", "max_bid"}); err != nil {
+		log.Printf("[handleTaskMetricsCSV] Failed to write header: %v", err)
+		return
+	}
+	for _, t := range tasks {
+		row := []string{
+			t.TaskID,
+			strconv.FormatFloat(t.Spread, 'f', 4, 64),
+			strconv.Itoa(t.NumBids),
+			strconv.FormatFloat(t.MinBid, 'f', 4, 64),
+			strconv.FormatFloat(t.MaxBid, 'f', 4, 64),
+		}
+		if err := cw.Write(row); err != nil {
+			log.Printf("[handleTaskMetricsCSV] Failed to write row for %s: %v", t.TaskID, err)
+			return
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Printf("[handleTaskMetricsCSV] Failed to flush response: %v", err)
+	}
+}
+
+// agentBreadthResponse is the body of /api/agent-breadth: how generalist the
+// agent pool is, by number of declared specialities per agent.
+type agentBreadthResponse struct {
+	AvgSpecialtiesPerAgent float64      `json:"avg_specialties_per_agent"`
+	Distribution           map[int]int  `json:"distribution"`
+	Agents                 []agentArity `json:"agents"`
+}
+
+// agentArity is one agent's declared specialty count.
+type agentArity struct {
+	AgentID          string `json:"agent_id"`
+	SpecialtiesCount int    `json:"specialties_count"`
+}
+
+// computeAgentBreadth summarizes how many specialities each agent in entries
+// declares: the mean across all agents, and a histogram of specialty counts
+// (keyed 1, 2, 3+ per the request that introduced this - counts of 3 or more
+// are folded into the 3 bucket). Returns a zero-value response for no
+// agents.
+func computeAgentBreadth(entries []specialtyWeightsEntry) agentBreadthResponse {
+	resp := agentBreadthResponse{
+		Distribution: make(map[int]int),
+		Agents:       make([]agentArity, 0, len(entries)),
+	}
+	if len(entries) == 0 {
+		return resp
+	}
+
+	total := 0
+	for _, e := range entries {
+		count := len(e.Specialities)
+		total += count
+		resp.Agents = append(resp.Agents, agentArity{AgentID: e.AgentID, SpecialtiesCount: count})
+
+		bucket := count
+		if bucket > 3 {
+			bucket = 3
+		}
+		resp.Distribution[bucket]++
+	}
+	resp.AvgSpecialtiesPerAgent = float64(total) / float64(len(entries))
+	return resp
+}
+
+// handleAgentBreadth serves how generalist the agent pool is: the average
+// number of declared specialities per agent and the 1/2/3+ distribution,
+// computed from the same -agents-dir files /api/specialty-weights reads.
+func handleAgentBreadth(w http.ResponseWriter, r *http.Request) {
+	if agentsDir == "" {
+		http.Error(w, "no agents directory configured; start the dashboard with -agents-dir", http.StatusNotFound)
+		return
+	}
+
+	entries, err := loadAgentSpecialtyWeights(agentsDir)
+	if err != nil {
+		log.Printf("[handleAgentBreadth] Failed to load agents from %s: %v", agentsDir, err)
+		http.Error(w, "failed to load agents", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(computeAgentBreadth(entries)); err != nil {
+		log.Printf("[handleAgentBreadth] Failed to encode response: %v", err)
+	}
+}
+
+// taskFeature is one task's raw (average bid price, bid count) pair before
+// normalization for clustering.
+type taskFeature struct {
+	TaskID  string
+	Price   float64
+	NumBids int
+}
+
+// taskFeaturesFor collects each "issue" node's average bid value and bid
+// count from n's "bid" edges. A task with no bids gets price 0 and
+// num_bids 0, so it still appears in the segmentation as the cheapest,
+// least-contested cluster rather than being dropped.
+func taskFeaturesFor(n Network) []taskFeature {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, e := range n.Edges {
+		if e.Type != "bid" {
+			continue
+		}
+		sums[e.Target] += e.BidValue
+		counts[e.Target]++
+	}
+
+	var features []taskFeature
+	for _, node := range n.Nodes {
+		if node.Type != "issue" {
+			continue
+		}
+		price := 0.0
+		if counts[node.ID] > 0 {
+			price = sums[node.ID] / float64(counts[node.ID])
+		}
+		features = append(features, taskFeature{TaskID: node.ID, Price: price, NumBids: counts[node.ID]})
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i].TaskID < features[j].TaskID })
+	return features
+}
+
+// minMaxNormalize rescales values into [0, 1]. All-equal input normalizes to
+// all zeros rather than dividing by a zero span.
+func minMaxNormalize(values []float64) []float64 {
+	norm := make([]float64, len(values))
+	if len(values) == 0 {
+		return norm
+	}
+	min, max := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	span := max - min
+	for i, v := range values {
+		if span == 0 {
+			continue
+		}
+		norm[i] = (v - min) / span
+	}
+	return norm
+}
+
+// clusterPoint is a task's position in normalized (price, bid count) space.
+type clusterPoint struct{ x, y float64 }
+
+// kMeans clusters points into k groups via Lloyd's algorithm, seeded
+// deterministically by picking k points evenly spaced along the input
+// sorted by x-then-y - so the same points always produce the same
+// clustering, with no randomness to make -k results irreproducible.
+// Iterates until assignments stop changing or maxIterations is reached.
+func kMeans(points []clusterPoint, k int, maxIterations int) ([]int, []clusterPoint) {
+	n := len(points)
+	sortedIdx := make([]int, n)
+	for i := range sortedIdx {
+		sortedIdx[i] = i
+	}
+	sort.Slice(sortedIdx, func(i, j int) bool {
+		pi, pj := points[sortedIdx[i]], points[sortedIdx[j]]
+		if pi.x != pj.x {
+			return pi.x < pj.x
+		}
+		return pi.y < pj.y
+	})
+
+	centroids := make([]clusterPoint, k)
+	for c := 0; c < k; c++ {
+		idx := 0
+		if k > 1 {
+			idx = c * (n - 1) / (k - 1)
+		}
+		centroids[c] = points[sortedIdx[idx]]
+	}
+
+	assignments := make([]int, n)
+	for iter := 0; iter < maxIterations; iter++ {
+		changed := false
+		for i, p := range points {
+			best, bestDist := 0, math.MaxFloat64
+			for c, centroid := range centroids {
+				dx, dy := p.x-centroid.x, p.y-centroid.y
+				dist := dx*dx + dy*dy
+				if dist < bestDist {
+					best, bestDist = c, dist
+				}
+			}
+			if assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+
+		sums := make([]clusterPoint, k)
+		counts := make([]int, k)
+		for i, p := range points {
+			c := assignments[i]
+			sums[c].x += p.x
+			sums[c].y += p.y
+			counts[c]++
+		}
+		for c := range centroids {
+			if counts[c] > 0 {
+				centroids[c] = clusterPoint{x: sums[c].x / float64(counts[c]), y: sums[c].y / float64(counts[c])}
+			}
+		}
+
+		if !changed && iter > 0 {
+			break
+		}
+	}
+	return assignments, centroids
+}
+
+// taskSegment is one task's assignment to a price/competition cluster.
+type taskSegment struct {
+	TaskID  string  `json:"task_id"`
+	Cluster int     `json:"cluster"`
+	Price   float64 `json:"price"`
+	NumBids int     `json:"num_bids"`
+}
+
+// segmentCentroid is one cluster's centroid in normalized (price, bid count)
+// space, plus how many tasks fell into it.
+type segmentCentroid struct {
+	Cluster           int     `json:"cluster"`
+	NormalizedPrice   float64 `json:"normalized_price"`
+	NormalizedNumBids float64 `json:"normalized_num_bids"`
+	TaskCount         int     `json:"task_count"`
+}
+
+// taskSegmentsResponse is the body of /api/task-segments.
+type taskSegmentsResponse struct {
+	K         int               `json:"k"`
+	Tasks     []taskSegment     `json:"tasks"`
+	Centroids []segmentCentroid `json:"centroids"`
+}
+
+// computeTaskSegments groups n's tasks into k clusters by normalized price
+// and bid count using deterministic k-means, so analysts can see segments
+// like "cheap-competitive" or "expensive-thin". Returns an error if k isn't
+// between 1 and the number of tasks.
+func computeTaskSegments(n Network, k int) (taskSegmentsResponse, error) {
+	features := taskFeaturesFor(n)
+	if k < 1 || k > len(features) {
+		return taskSegmentsResponse{}, fmt.Errorf("k must be between 1 and %d (the number of tasks), got %d", len(features), k)
+	}
+
+	prices := make([]float64, len(features))
+	bidCounts := make([]float64, len(features))
+	for i, f := range features {
+		prices[i] = f.Price
+		bidCounts[i] = float64(f.NumBids)
+	}
+	normPrices := minMaxNormalize(prices)
+	normBidCounts := minMaxNormalize(bidCounts)
+
+	points := make([]clusterPoint, len(features))
+	for i := range features {
+		points[i] = clusterPoint{x: normPrices[i], y: normBidCounts[i]}
+	}
+	assignments, centroids := kMeans(points, k, 100)
+
+	tasks := make([]taskSegment, len(features))
+	taskCounts := make([]int, k)
+	for i, f := range features {
+		tasks[i] = taskSegment{TaskID: f.TaskID, Cluster: assignments[i], Price: f.Price, NumBids: f.NumBids}
+		taskCounts[assignments[i]]++
+	}
+
+	segCentroids := make([]segmentCentroid, k)
+	for c := 0; c < k; c++ {
+		segCentroids[c] = segmentCentroid{
+			Cluster:           c,
+			NormalizedPrice:   centroids[c].x,
+			NormalizedNumBids: centroids[c].y,
+			TaskCount:         taskCounts[c],
+		}
+	}
+
+	return taskSegmentsResponse{K: k, Tasks: tasks, Centroids: segCentroids}, nil
+}
+
+// handleTaskSegments serves /api/task-segments?k=3, clustering tasks by
+// price and competition for a market-segmentation panel.
+func handleTaskSegments(w http.ResponseWriter, r *http.Request) {
+	k := 3
+	if raw := r.URL.Query().Get("k"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "invalid \"k\" query parameter", http.StatusBadRequest)
+			return
+		}
+		k = parsed
+	}
+
+	mu.Lock()
+	resp, err := computeTaskSegments(network, k)
+	mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleTaskSegments] Failed to encode response: %v", err)
+	}
+}
+
+// AgentMobility reports how often an agent's wins fall inside versus outside
+// its declared specialty.
+type AgentMobility struct {
+	AgentID            string  `json:"agent_id"`
+	InSpecialtyWins    int     `json:"in_specialty_wins"`
+	OutOfSpecialtyWins int     `json:"out_of_specialty_wins"`
+	MobilityScore      float64 `json:"mobility_score"`
+}
+
+// computeAgentMobility reports, per agent, how often its wins fall outside
+// its declared specialty rather than inside it - MobilityScore is the
+// fraction of wins that are out-of-specialty; a high score suggests a
+// generalist thriving beyond its declared niche. Tasks with no declared
+// speciality don't count toward either bucket, since there's nothing to be
+// "in" or "out" of.
+func computeAgentMobility(n Network) []AgentMobility {
+	byID := make(map[string]Node, len(n.Nodes))
+	for _, node := range n.Nodes {
+		byID[node.ID] = node
+	}
+
+	inByAgent := make(map[string]int)
+	outByAgent := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, e := range n.Edges {
+		if e.Type != "assigned" {
+			continue
+		}
+		taskID, agentID := e.Source, e.Target
+		speciality := taskSpeciality(byID, taskID)
+		if speciality == "" {
+			continue
+		}
+		seen[agentID] = true
+		if isSpecialist(byID, agentID, speciality) {
+			inByAgent[agentID]++
+		} else {
+			outByAgent[agentID]++
+		}
+	}
+
+	agentIDs := make([]string, 0, len(seen))
+	for id := range seen {
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Strings(agentIDs)
+
+	mobility := make([]AgentMobility, 0, len(agentIDs))
+	for _, id := range agentIDs {
+		in, out := inByAgent[id], outByAgent[id]
+		var score float64
+		if total := in + out; total > 0 {
+			score = float64(out) / float64(total)
+		}
+		mobility = append(mobility, AgentMobility{
+			AgentID:            id,
+			InSpecialtyWins:    in,
+			OutOfSpecialtyWins: out,
+			MobilityScore:      score,
+		})
+	}
+	return mobility
+}
+
+// agentMobilityResponse is the body of /api/agent-mobility.
+type agentMobilityResponse struct {
+	Agents []AgentMobility `json:"agents"`
+}
+
+// handleAgentMobility reports each agent's cross-category mobility: the
+// fraction of its wins that fall outside its declared specialty.
+func handleAgentMobility(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	mobility := computeAgentMobility(network)
+	mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(agentMobilityResponse{Agents: mobility}); err != nil {
+		log.Printf("[handleAgentMobility] Failed to encode response: %v", err)
+	}
+}
+
+// metricSource documents which network elements and formula produced one
+// metric exposed by this server, so downstream tools can verify they're
+// interpreting a number the way it was actually computed - notably useful
+// given that viz.go computes some overlapping metrics (e.g. tier assignment
+// rates) from the same edge types with an independently-written formula.
+type metricSource struct {
+	Metric   string `json:"metric"`
+	Endpoint string `json:"endpoint"`
+	Inputs   string `json:"inputs"`
+	Formula  string `json:"formula"`
+}
+
+// metricSources enumerates every metric-producing /api endpoint this server
+// registers in main(). / and /data are excluded: they serve the dashboard
+// page and the raw network dump, not derived metrics.
+var metricSources = []metricSource{
+	{
+		Metric:   "task_segments.clusters",
+		Endpoint: "/api/task-segments",
+		Inputs:   "\"bid\" edges grouped by task (average bid value, bid count)",
+		Formula:  "deterministic k-means over min-max-normalized (price, bid count), seeded by evenly-spaced points along the price-sorted task list",
+	},
+	{
+		Metric:   "lowest_bid.total_cost / specialist_preferred.total_cost",
+		Endpoint: "/api/specialist-preference",
+		Inputs:   "\"bid\" edges grouped by task (bidsByTask)",
+		Formula:  "sum of winning BidValue per task under lowest-bid vs. specialist-preferred-within-tolerance resolution",
+	},
+	{
+		Metric:   "specialist_win_rate",
+		Endpoint: "/api/specialist-preference",
+		Inputs:   "task winners, Node.Specialities, TaskSpeciality",
+		Formula:  "count(winners specializing in the task's speciality) / count(winners)",
+	},
+	{
+		Metric:   "node_fraction, edge_fraction",
+		Endpoint: "/api/degree-tail",
+		Inputs:   "node degrees (computeDegrees), all edges",
+		Formula:  "node_fraction = |{nodes with degree >= threshold}| / |nodes|; edge_fraction = |{edges touching that set}| / |edges|",
+	},
+	{
+		Metric:   "win_rate_histogram",
+		Endpoint: "/api/win-rate-histogram",
+		Inputs:   "MarketMetrics.WinRateDistribution (see win_rate below)",
+		Formula:  "per-agent win_rate values binned into N equal-width buckets over [0, 1]; mean and median of the same distribution",
+	},
+	{
+		Metric:   "network_density",
+		Endpoint: "/api/consistency",
+		Inputs:   "node count, edge count, node degrees",
+		Formula:  "cross-checked two ways: edgeCount / (n*(n-1)) vs. sum(degrees) / (2*n*(n-1)); flagged inconsistent if they differ beyond tolerance",
+	},
+	{
+		Metric:   "total_bids",
+		Endpoint: "/api/consistency",
+		Inputs:   "\"bid\" edges, bidsByTask grouping",
+		Formula:  "cross-checked two ways: direct count of edges with Type==\"bid\" vs. sum of per-task bid-group sizes",
+	},
+	{
+		Metric:   "auction_failure breakdown",
+		Endpoint: "/api/auction-failures",
+		Inputs:   "unassigned \"issue\" nodes, their \"bid\" edges, PriceMax",
+		Formula:  "each unassigned task classified as no_bidders, all_bids_over_reserve (every BidValue > PriceMax), or below_min_bidders (fewer than min_bidders bids)",
+	},
+	{
+		Metric:   "time_to_first_win, activity_span",
+		Endpoint: "/api/agent-metrics",
+		Inputs:   "timestamped \"bid\" and \"assigned\" edges per agent",
+		Formula:  "time_to_first_win = first assigned-edge timestamp - first bid-edge timestamp; activity_span = last edge timestamp - first edge timestamp",
+	},
+	{
+		Metric:   "win_rate",
+		Endpoint: "/api/agent-leaderboard",
+		Inputs:   "\"bid\" edges (Source=agent), \"assigned\" edges (Target=agent)",
+		Formula:  "bids_won / bids_submitted per agent",
+	},
+	{
+		Metric:   "composite",
+		Endpoint: "/api/agent-leaderboard",
+		Inputs:   "win_rate, total won BidValue, specialization score",
+		Formula:  "weighted sum: win_rate_weight*win_rate + value_weight*(total_value / max total_value) + specialization_weight*specialization_score",
+	},
+	{
+		Metric:   "average_markup",
+		Endpoint: "/api/bid-shading",
+		Inputs:   "\"bid\" edges, per-task clearing price (clearingPrices)",
+		Formula:  "mean over an agent's bids of (BidValue - clearing_price) / clearing_price",
+	},
+	{
+		Metric:   "spread",
+		Endpoint: "/api/bid-spread",
+		Inputs:   "\"bid\" edges grouped by task (bidsByTask)",
+		Formula:  "(max BidValue - min BidValue) / min BidValue per task, tasks with fewer than 2 bids or a zero minimum excluded",
+	},
+	{
+		Metric:   "pareto front (x, y, dominated)",
+		Endpoint: "/api/pareto",
+		Inputs:   "bids_submitted, bids_won, win_rate, avg_bid_value per agent",
+		Formula:  "agent A is dominated if some agent B has B.x <= A.x and B.y >= A.y with at least one strict inequality, for the requested x/y metric pair",
+	},
+	{
+		Metric:   "engagement_matrix",
+		Endpoint: "/api/engagement-matrix",
+		Inputs:   "\"bid\" edges from agent nodes to \"issue\" nodes, grouped by issue Speciality",
+		Formula:  "raw count of bids per (agent, skill) pair, optionally normalized so agent rows or skill columns sum to 1",
+	},
+	{
+		Metric:   "tasks_per_agent, agents_per_task",
+		Endpoint: "/api/assignment-distribution",
+		Inputs:   "\"assigned\" edges (task -> agent), all agent and issue nodes",
+		Formula:  "histogram of assigned-edge count per agent node and per issue node, including zero-count nodes, capped into a top \"N+\" bucket",
+	},
+	{
+		Metric:   "demand, bids, wins",
+		Endpoint: "/api/sparklines",
+		Inputs:   "issue node Speciality, \"bid\" and \"assigned\" edges, grouped by task's Speciality",
+		Formula:  "per-category counts in the fixed sparklineCategories order: number of tasks demanding each category, bids targeting them, and assigned wins among them",
+	},
+	{
+		Metric:   "mobility_score",
+		Endpoint: "/api/agent-mobility",
+		Inputs:   "\"assigned\" edges (task -> agent), task Speciality, agent Specialities",
+		Formula:  "out_of_specialty_wins / (in_specialty_wins + out_of_specialty_wins) per agent; tasks with no declared speciality are excluded from both counts",
+	},
+	{
+		Metric:   "assignment_latency (p50, p90, p99, histogram)",
+		Endpoint: "/api/assignment-latency",
+		Inputs:   "timestamped \"auction\", \"bid\", and \"assigned\" edges per task",
+		Formula:  "per task, assigned-edge timestamp minus the earliest timestamped auction edge (or, absent one, the earliest timestamped bid edge); tasks missing either timestamp are excluded and counted",
+	},
+	{
+		Metric:   "shut_out_agents",
+		Endpoint: "/api/shut-out-agents",
+		Inputs:   "\"bid\" edges (agent -> task, with BidValue) and \"assigned\" edges (task -> agent)",
+		Formula:  "agents with at least one bid and zero wins, with their bid count and summed BidValue, sorted by most bids submitted",
+	},
+	{
+		Metric:   "top_skills",
+		Endpoint: "/api/agent-profile",
+		Inputs:   "per-agent JSON files under -agents-dir (agent_id, specialities)",
+		Formula:  "each agent's specialities sorted by Weight descending, truncated to the top N (?top=, default 3)",
+	},
+	{
+		Metric:   "avg_specialties_per_agent",
+		Endpoint: "/api/agent-breadth",
+		Inputs:   "per-agent JSON files under -agents-dir (agent_id, specialities)",
+		Formula:  "mean number of declared specialities across all agents, plus a count-of-agents distribution bucketed at 1, 2, and 3+ specialities",
+	},
+}
+
+// metricSourcesResponse is the body of /api/metric-sources.
+type metricSourcesResponse struct {
+	Metrics []metricSource `json:"metrics"`
+}
+
+// handleMetricSources documents, for every metric-producing endpoint this
+// server exposes, which network elements and formula produced it - a
+// reproducibility aid for downstream tools consuming these numbers.
+func handleMetricSources(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(metricSourcesResponse{Metrics: metricSources}); err != nil {
+		log.Printf("[handleMetricSources] Failed to encode response: %v", err)
+	}
+}
+
+func main() {
+	winRateWeight := flag.Float64("win-rate-weight", currentLeaderboardWeights.WinRate, "Weight of win rate in the agent leaderboard composite score")
+	valueWeight := flag.Float64("value-weight", currentLeaderboardWeights.Value, "Weight of normalized total won value in the agent leaderboard composite score")
+	specializationWeight := flag.Float64("specialization-weight", currentLeaderboardWeights.Specialization, "Weight of specialization score in the agent leaderboard composite score")
+	currency := flag.String("currency", currencySymbol, "Currency symbol to prefix monetary values with in the dashboard HTML")
+	agentsDirFlag := flag.String("agents-dir", "", "Optional path to a directory of per-agent JSON files (agent_id + specialities) backing /api/specialty-weights")
+	watch := flag.Bool("watch", false, "Watch baseline_network.json for changes and hot-reload it without restarting the dashboard")
+	maxWS := flag.Int("max-ws-connections", maxWSConnections, "Maximum number of concurrent /ws client connections")
+	flag.Parse()
+	maxWSConnections = *maxWS
+	currentLeaderboardWeights = leaderboardWeights{WinRate: *winRateWeight, Value: *valueWeight, Specialization: *specializationWeight}
+	currencySymbol = *currency
+	agentsDir = *agentsDirFlag
+
+	const networkFile = "data/baseline_network.json"
+	raw, err := os.ReadFile(networkFile)
+	if err != nil {
+		log.Fatalf("Failed to read baseline_network.json: %v", err)
+	}
+	log.Printf("[main] Loaded baseline_network.json, %d bytes", len(raw))
+	if err := unmarshalJSONWithContext(networkFile, raw, &network); err != nil {
+		log.Fatalf("[main] Failed to unmarshal baseline_network.json: %v", err)
+	}
+	log.Printf("[main] After load: nodes=%d, edges=%d", len(network.Nodes), len(network.Edges))
+	network = normalizeLinkDirection(network)
+	reloadSnapshot(network)
+	if *watch {
+		if err := watchNetworkFile(networkFile, 500*time.Millisecond); err != nil {
+			log.Fatalf("[main] Failed to start file watcher: %v", err)
+		}
+		log.Printf("[main] Watching %s for changes", networkFile)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", serveDashboard)
+	mux.HandleFunc("/api/specialist-preference", handleSpecialistPreference)
+	mux.HandleFunc("/api/degree-tail", handleDegreeTail)
+	mux.HandleFunc("/api/win-rate-histogram", handleWinRateHistogram)
+	mux.HandleFunc("/data", handleData)
+	mux.HandleFunc("/api/consistency", handleConsistency)
+	mux.HandleFunc("/api/auction-failures", handleAuctionFailures)
+	mux.HandleFunc("/api/agent-metrics", handleAgentMetrics)
+	mux.HandleFunc("/api/agent-leaderboard", handleAgentLeaderboard)
+	mux.HandleFunc("/api/bid-shading", handleBidShading)
+	mux.HandleFunc("/api/bid-spread", handleBidSpread)
+	mux.HandleFunc("/api/pareto", handlePareto)
+	mux.HandleFunc("/api/engagement-matrix", handleEngagementMatrix)
+	mux.HandleFunc("/api/metric-sources", handleMetricSources)
+	mux.HandleFunc("/api/assignment-distribution", handleAssignmentDistribution)
+	mux.HandleFunc("/api/sparklines", handleSparklines)
+	mux.HandleFunc("/api/specialty-weights", handleSpecialtyWeights)
+	mux.HandleFunc("/api/agent-mobility", handleAgentMobility)
+	mux.HandleFunc("/api/assignment-latency", handleAssignmentLatency)
+	mux.HandleFunc("/api/shut-out-agents", handleShutOutAgents)
+	mux.HandleFunc("/api/agent-profile", handleAgentProfile)
+	mux.HandleFunc("/api/agent-metrics.csv", handleAgentMetricsCSV)
+	mux.HandleFunc("/api/task-metrics.csv", handleTaskMetricsCSV)
+	mux.HandleFunc("/api/agent-breadth", handleAgentBreadth)
+	mux.HandleFunc("/ws", handleWebSocket)
+	mux.HandleFunc("/api/task-segments", handleTaskSegments)
 	log.Println("[main] Dashboard running at http://localhost:8080")
-	http.ListenAndServe(":8080", nil)
+	http.ListenAndServe(":8080", mux)
 }
 
 const dashboardHTML = `
@@ -251,6 +3125,7 @@ const dashboardHTML = `
     let graph = window.graphData || {{.Network}};
     if (!graph.nodes) graph.nodes = [];
     if (!graph.edges) graph.edges = [];
+    window.currencySymbol = "{{.CurrencySymbol}}";
 
     // --- Summary metrics ---
     let degMap = {}, degSum = 0;
@@ -484,8 +3359,8 @@ if (!graph || !Array.isArray(graph.nodes) || !Array.isArray(graph.edges)) {
       if (d.name) html += d.name + "<br>";
       if (d.speciality && d.speciality.name) html += "Skill: " + d.speciality.name + "<br>";
       if (d.skills) html += "Skills: " + d.skills.map(s => s.name).join(", ") + "<br>";
-      if (typeof d.price_min === "number") html += "Min Price: " + d.price_min + "<br>";
-      if (typeof d.price_max === "number") html += "Max Price: " + d.price_max + "<br>";
+      if (typeof d.price_min === "number") html += "Min Price: " + window.currencySymbol + d.price_min + "<br>";
+      if (typeof d.price_max === "number") html += "Max Price: " + window.currencySymbol + d.price_max + "<br>";
       html += "Connections: " + d.degree;
       tooltip.html(html).style("visibility", "visible");
       link.attr("stroke-opacity", l => (l.source.id === d.id || l.target.id === d.id) ? 1 : 0.18);
@@ -1183,4 +4058,4 @@ renderMarketBalanceBar(graph.nodes);
 
 </body>
 </html>
-`
\ No newline at end of file
+`
@@ -0,0 +1,1877 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func fixtureSpecialistNetwork() (map[string]Node, map[string][]Edge) {
+	byID := map[string]Node{
+		"agentSpecialist": {ID: "agentSpecialist", Type: "agent", Specialities: []Speciality{{Name: "UI/UX"}}},
+		"agentGeneralist": {ID: "agentGeneralist", Type: "agent"},
+		"task1":           {ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "UI/UX"}},
+	}
+	byTask := map[string][]Edge{
+		"task1": {
+			{Source: "agentGeneralist", Target: "task1", Type: "bid", BidValue: 100},
+			{Source: "agentSpecialist", Target: "task1", Type: "bid", BidValue: 104},
+		},
+	}
+	return byID, byTask
+}
+
+func TestSpecialistPreferenceIncreasesWinRateWithTolerance(t *testing.T) {
+	byID, byTask := fixtureSpecialistNetwork()
+
+	low := resolveSpecialistPreferred(byID, "task1", byTask["task1"], 0.01)
+	if low.Source != "agentGeneralist" {
+		t.Fatalf("expected generalist to win at low tolerance, got %s", low.Source)
+	}
+
+	high := resolveSpecialistPreferred(byID, "task1", byTask["task1"], 0.05)
+	if high.Source != "agentSpecialist" {
+		t.Fatalf("expected specialist to win within tolerance, got %s", high.Source)
+	}
+	if high.BidValue <= low.BidValue {
+		t.Fatalf("expected specialist preference to cost at least as much, got %v vs %v", high.BidValue, low.BidValue)
+	}
+}
+
+func TestSpecialistWinRatePureLowestBid(t *testing.T) {
+	byID, byTask := fixtureSpecialistNetwork()
+	winners := map[string]Edge{"task1": resolveLowestBid(byTask["task1"])}
+	if rate := specialistWinRate(byID, winners); rate != 0 {
+		t.Fatalf("expected 0 specialist win rate under pure lowest bid, got %v", rate)
+	}
+}
+
+func TestDegreeTailFractions(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{{ID: "hub"}, {ID: "leaf1"}, {ID: "leaf2"}, {ID: "leaf3"}},
+		Edges: []Edge{
+			{Source: "hub", Target: "leaf1", Type: "bid"},
+			{Source: "hub", Target: "leaf2", Type: "bid"},
+			{Source: "hub", Target: "leaf3", Type: "bid"},
+		},
+	}
+	degreeCache = nil
+	degrees := cachedDegrees()
+	mu.Unlock()
+
+	if degrees["hub"] != 3 {
+		t.Fatalf("expected hub degree 3, got %d", degrees["hub"])
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	tail := map[string]bool{"hub": true}
+	tailEdges := 0
+	for _, e := range network.Edges {
+		if tail[e.Source] || tail[e.Target] {
+			tailEdges++
+		}
+	}
+	nodeFraction := float64(len(tail)) / float64(len(network.Nodes))
+	edgeFraction := float64(tailEdges) / float64(len(network.Edges))
+	if nodeFraction != 0.25 {
+		t.Fatalf("expected node fraction 0.25, got %v", nodeFraction)
+	}
+	if edgeFraction != 1 {
+		t.Fatalf("expected edge fraction 1, got %v", edgeFraction)
+	}
+}
+
+func TestBinWinRatesCoversRangeAndCounts(t *testing.T) {
+	values := []float64{0, 0.05, 0.5, 0.95, 1}
+	bins, mean, median := binWinRates(values, 10)
+
+	if len(bins) != 10 {
+		t.Fatalf("expected 10 buckets, got %d", len(bins))
+	}
+	if bins[0].Lo != 0 || bins[len(bins)-1].Hi != 1 {
+		t.Fatalf("expected range 0-1, got %v to %v", bins[0].Lo, bins[len(bins)-1].Hi)
+	}
+
+	total := 0
+	for _, b := range bins {
+		total += b.Count
+	}
+	if total != len(values) {
+		t.Fatalf("expected bucket counts to sum to %d, got %d", len(values), total)
+	}
+	if mean != 0.5 {
+		t.Fatalf("expected mean 0.5, got %v", mean)
+	}
+	if median != 0.5 {
+		t.Fatalf("expected median 0.5, got %v", median)
+	}
+}
+
+func TestNormalizeLinkDirectionReorientsReversedAssignedEdge(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agentA", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "bid"},
+			// Reversed: agent -> task, instead of the canonical task -> agent.
+			{Source: "agentA", Target: "task1", Type: "assigned"},
+		},
+	}
+
+	// Without normalization, computeMarketMetricsFor (which counts wins by
+	// e.Target on "assigned" edges) would credit the win to task1, not
+	// agentA - the exact silent breakage this request describes.
+	broken := computeMarketMetricsFor(n)
+	if broken.Agents[0].BidsWon != 0 {
+		t.Fatalf("expected the reversed edge to hide agentA's win before normalization, got %+v", broken.Agents[0])
+	}
+
+	normalized := normalizeLinkDirection(n)
+	assignedEdge := normalized.Edges[1]
+	if assignedEdge.Source != "task1" || assignedEdge.Target != "agentA" {
+		t.Fatalf("expected the assigned edge reoriented to task1 -> agentA, got %s -> %s", assignedEdge.Source, assignedEdge.Target)
+	}
+
+	metrics := computeMarketMetricsFor(normalized)
+	if len(metrics.Agents) != 1 || metrics.Agents[0].AgentID != "agentA" || metrics.Agents[0].BidsWon != 1 {
+		t.Fatalf("expected agentA's win to be counted after normalization, got %+v", metrics.Agents)
+	}
+}
+
+func TestNormalizeLinkDirectionLeavesCanonicalEdgesUnchanged(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agentA", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "agentA", Type: "assigned"},
+			{Source: "agentA", Target: "task1", Type: "bid"},
+		},
+	}
+	normalized := normalizeLinkDirection(n)
+	if normalized.Edges[0] != n.Edges[0] || normalized.Edges[1] != n.Edges[1] {
+		t.Fatalf("expected already-canonical edges to pass through unchanged, got %+v", normalized.Edges)
+	}
+}
+
+func TestComputeMarketMetricsWinRates(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{{ID: "agentA"}, {ID: "agentB"}, {ID: "task1"}, {ID: "task2"}},
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "bid"},
+			{Source: "agentA", Target: "task2", Type: "bid"},
+			{Source: "agentB", Target: "task1", Type: "bid"},
+			{Source: "task1", Target: "agentA", Type: "assigned"},
+		},
+	}
+	metrics := computeMarketMetrics()
+	mu.Unlock()
+
+	byID := make(map[string]AgentMetrics, len(metrics.Agents))
+	for _, m := range metrics.Agents {
+		byID[m.AgentID] = m
+	}
+	if got := byID["agentA"].WinRate; got != 0.5 {
+		t.Fatalf("expected agentA win rate 0.5, got %v", got)
+	}
+	if got := byID["agentB"].WinRate; got != 0 {
+		t.Fatalf("expected agentB win rate 0, got %v", got)
+	}
+	if len(metrics.WinRateDistribution) != 2 {
+		t.Fatalf("expected win rate distribution of length 2, got %d", len(metrics.WinRateDistribution))
+	}
+}
+
+func TestComputeShutOutAgentsIncludesRepeatedLosersAndExcludesWinners(t *testing.T) {
+	n := Network{
+		Nodes: []Node{{ID: "agentA"}, {ID: "agentB"}, {ID: "task1"}, {ID: "task2"}, {ID: "task3"}},
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "bid", BidValue: 100},
+			{Source: "agentA", Target: "task2", Type: "bid", BidValue: 120},
+			{Source: "agentA", Target: "task3", Type: "bid", BidValue: 90},
+			{Source: "agentB", Target: "task1", Type: "bid", BidValue: 110},
+			{Source: "task1", Target: "agentB", Type: "assigned"},
+		},
+	}
+	shutOut := computeShutOutAgentsFor(n)
+
+	if len(shutOut) != 1 {
+		t.Fatalf("expected exactly 1 shut-out agent, got %d: %+v", len(shutOut), shutOut)
+	}
+	if shutOut[0].AgentID != "agentA" {
+		t.Fatalf("expected agentA to be shut out, got %s", shutOut[0].AgentID)
+	}
+	if shutOut[0].BidsSubmitted != 3 {
+		t.Fatalf("expected 3 bids submitted, got %d", shutOut[0].BidsSubmitted)
+	}
+	if shutOut[0].TotalBidValue != 310 {
+		t.Fatalf("expected total bid value 310, got %v", shutOut[0].TotalBidValue)
+	}
+	for _, s := range shutOut {
+		if s.AgentID == "agentB" {
+			t.Fatalf("expected winning agentB to be excluded from shut-out list")
+		}
+	}
+}
+
+func TestHandleShutOutAgentsSortsByMostBidsSubmitted(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{{ID: "agentA"}, {ID: "agentB"}, {ID: "task1"}, {ID: "task2"}},
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "bid", BidValue: 10},
+			{Source: "agentB", Target: "task1", Type: "bid", BidValue: 20},
+			{Source: "agentB", Target: "task2", Type: "bid", BidValue: 30},
+		},
+	}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/shut-out-agents", nil)
+	rec := httptest.NewRecorder()
+	handleShutOutAgents(rec, req)
+
+	var resp shutOutAgentsResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Agents) != 2 {
+		t.Fatalf("expected 2 shut-out agents, got %d", len(resp.Agents))
+	}
+	if resp.Agents[0].AgentID != "agentB" || resp.Agents[0].BidsSubmitted != 2 {
+		t.Fatalf("expected agentB with 2 bids first, got %+v", resp.Agents[0])
+	}
+	if resp.Agents[1].AgentID != "agentA" || resp.Agents[1].BidsSubmitted != 1 {
+		t.Fatalf("expected agentA with 1 bid second, got %+v", resp.Agents[1])
+	}
+}
+
+func TestComputeSkillCoverageDropsBelowOneWhenASkillHasNoSupplier(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agentA", Type: "agent", Specialities: []Speciality{{Name: "UI/UX", Weight: 100}}},
+			{ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "UI/UX"}},
+			{ID: "task2", Type: "issue", Speciality: &TaskSpeciality{Name: "ServerSideLogic"}},
+		},
+	}
+	if got := computeSkillCoverage(n); got != 0.5 {
+		t.Fatalf("expected coverage 0.5 (1 of 2 demanded skills supplied), got %v", got)
+	}
+}
+
+func TestComputeSkillCoverageIsFullWhenEverySkillIsSupplied(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agentA", Type: "agent", Specialities: []Speciality{{Name: "UI/UX", Weight: 100}}},
+			{ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "UI/UX"}},
+		},
+	}
+	if got := computeSkillCoverage(n); got != 1 {
+		t.Fatalf("expected full coverage 1, got %v", got)
+	}
+}
+
+func TestComputeSkillCoverageIsOneWhenNoSkillsAreDemanded(t *testing.T) {
+	if got := computeSkillCoverage(Network{}); got != 1 {
+		t.Fatalf("expected coverage 1 for an empty network, got %v", got)
+	}
+}
+
+func TestComputeSparklinesOneValuePerCategoryInStableOrder(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agentA", Type: "agent"},
+			{ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "UI/UX"}},
+			{ID: "task2", Type: "issue", Speciality: &TaskSpeciality{Name: "BugFixes"}},
+		},
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "bid"},
+			{Source: "task1", Target: "agentA", Type: "assigned"},
+		},
+	}
+	resp := computeSparklines(n)
+
+	if len(resp.Categories) != len(sparklineCategories) {
+		t.Fatalf("expected %d categories, got %d", len(sparklineCategories), len(resp.Categories))
+	}
+	for _, s := range resp.Series {
+		if len(s.Values) != len(sparklineCategories) {
+			t.Fatalf("series %q: expected one value per category (%d), got %d", s.Metric, len(sparklineCategories), len(s.Values))
+		}
+	}
+
+	uiuxIndex := -1
+	for i, c := range resp.Categories {
+		if c == "UI/UX" {
+			uiuxIndex = i
+		}
+	}
+	if uiuxIndex == -1 {
+		t.Fatal("expected UI/UX to appear in categories")
+	}
+
+	byMetric := make(map[string][]float64, len(resp.Series))
+	for _, s := range resp.Series {
+		byMetric[s.Metric] = s.Values
+	}
+	if got := byMetric["demand"][uiuxIndex]; got != 1 {
+		t.Fatalf("expected UI/UX demand 1, got %v", got)
+	}
+	if got := byMetric["bids"][uiuxIndex]; got != 1 {
+		t.Fatalf("expected UI/UX bids 1, got %v", got)
+	}
+	if got := byMetric["wins"][uiuxIndex]; got != 1 {
+		t.Fatalf("expected UI/UX wins 1, got %v", got)
+	}
+}
+
+func TestHandleSparklinesReturnsConsistentCategoryOrderAcrossCalls(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{{ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "BugFixes"}}},
+	}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sparklines", nil)
+	rec := httptest.NewRecorder()
+	handleSparklines(rec, req)
+
+	var first sparklinesResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&first); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	rec2 := httptest.NewRecorder()
+	handleSparklines(rec2, req)
+	var second sparklinesResponse
+	if err := json.NewDecoder(rec2.Result().Body).Decode(&second); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	for i := range first.Categories {
+		if first.Categories[i] != second.Categories[i] {
+			t.Fatalf("expected category order to be stable across calls, got %v then %v", first.Categories, second.Categories)
+		}
+	}
+}
+
+func TestComputeAgentMobilityHighForGeneralistWinningMostlyOutOfSpecialty(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "generalist", Type: "agent", Specialities: []Speciality{{Name: "UI/UX"}}},
+			{ID: "specialist", Type: "agent", Specialities: []Speciality{{Name: "BugFixes"}}},
+			{ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "BugFixes"}},
+			{ID: "task2", Type: "issue", Speciality: &TaskSpeciality{Name: "ServerSideLogic"}},
+			{ID: "task3", Type: "issue", Speciality: &TaskSpeciality{Name: "UI/UX"}},
+			{ID: "task4", Type: "issue", Speciality: &TaskSpeciality{Name: "BugFixes"}},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "generalist", Type: "assigned"},
+			{Source: "task2", Target: "generalist", Type: "assigned"},
+			{Source: "task3", Target: "generalist", Type: "assigned"},
+			{Source: "task4", Target: "specialist", Type: "assigned"},
+		},
+	}
+
+	mobility := computeAgentMobility(n)
+	byAgent := make(map[string]AgentMobility, len(mobility))
+	for _, m := range mobility {
+		byAgent[m.AgentID] = m
+	}
+
+	generalist := byAgent["generalist"]
+	if generalist.InSpecialtyWins != 1 || generalist.OutOfSpecialtyWins != 2 {
+		t.Fatalf("expected generalist to have 1 in-specialty and 2 out-of-specialty wins, got %+v", generalist)
+	}
+	if generalist.MobilityScore < 0.66 || generalist.MobilityScore > 0.67 {
+		t.Fatalf("expected a high mobility score (~0.67) for the generalist, got %v", generalist.MobilityScore)
+	}
+
+	specialist := byAgent["specialist"]
+	if specialist.InSpecialtyWins != 1 || specialist.OutOfSpecialtyWins != 0 || specialist.MobilityScore != 0 {
+		t.Fatalf("expected the specialist to have mobility score 0, got %+v", specialist)
+	}
+}
+
+func TestComputeAgentMobilityIgnoresTasksWithNoDeclaredSpeciality(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agentA", Type: "agent", Specialities: []Speciality{{Name: "UI/UX"}}},
+			{ID: "task1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "agentA", Type: "assigned"},
+		},
+	}
+
+	if got := computeAgentMobility(n); len(got) != 0 {
+		t.Fatalf("expected no mobility entries when tasks lack a declared speciality, got %+v", got)
+	}
+}
+
+func TestHandleAgentMobilityServesComputedScores(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{
+			{ID: "agentA", Type: "agent", Specialities: []Speciality{{Name: "UI/UX"}}},
+			{ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "BugFixes"}},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "agentA", Type: "assigned"},
+		},
+	}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agent-mobility", nil)
+	rec := httptest.NewRecorder()
+	handleAgentMobility(rec, req)
+
+	var resp agentMobilityResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Agents) != 1 || resp.Agents[0].AgentID != "agentA" || resp.Agents[0].MobilityScore != 1 {
+		t.Fatalf("expected agentA with mobility score 1, got %+v", resp.Agents)
+	}
+}
+
+func TestComputeAssignmentLatenciesUsesAuctionOrFallsBackToFirstBid(t *testing.T) {
+	n := Network{
+		Edges: []Edge{
+			// task1: auction -> assigned, 100s later.
+			{Source: "agentA", Target: "task1", Type: "auction", Timestamp: "2024-01-01T00:00:00Z"},
+			{Source: "agentA", Target: "task1", Type: "bid", Timestamp: "2024-01-01T00:00:10Z"},
+			{Source: "task1", Target: "agentA", Type: "assigned", Timestamp: "2024-01-01T00:01:40Z"},
+			// task2: no auction edge, falls back to earliest bid, 50s later.
+			{Source: "agentB", Target: "task2", Type: "bid", Timestamp: "2024-01-01T00:00:00Z"},
+			{Source: "agentB", Target: "task2", Type: "bid", Timestamp: "2024-01-01T00:00:20Z"},
+			{Source: "task2", Target: "agentB", Type: "assigned", Timestamp: "2024-01-01T00:00:50Z"},
+			// task3: assigned but no timestamped entry event - excluded.
+			{Source: "task3", Target: "agentC", Type: "assigned", Timestamp: "2024-01-01T00:00:00Z"},
+		},
+	}
+
+	latencies, excluded := computeAssignmentLatencies(n)
+	if excluded != 1 {
+		t.Fatalf("expected 1 excluded task, got %d", excluded)
+	}
+	byTask := make(map[string]float64, len(latencies))
+	for _, l := range latencies {
+		byTask[l.TaskID] = l.Seconds
+	}
+	if got := byTask["task1"]; got != 100 {
+		t.Fatalf("expected task1 latency 100s (from auction edge), got %v", got)
+	}
+	if got := byTask["task2"]; got != 50 {
+		t.Fatalf("expected task2 latency 50s (from earliest bid edge), got %v", got)
+	}
+}
+
+func TestPercentileInterpolatesBetweenRanks(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+	if got := percentile(sorted, 0); got != 10 {
+		t.Fatalf("expected p0 == min, got %v", got)
+	}
+	if got := percentile(sorted, 100); got != 40 {
+		t.Fatalf("expected p100 == max, got %v", got)
+	}
+	if got := percentile(sorted, 50); got != 25 {
+		t.Fatalf("expected p50 == 25, got %v", got)
+	}
+}
+
+func TestHandleAssignmentLatencyReportsPercentilesAndExcludedCount(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "auction", Timestamp: "2024-01-01T00:00:00Z"},
+			{Source: "task1", Target: "agentA", Type: "assigned", Timestamp: "2024-01-01T00:01:00Z"},
+			{Source: "task2", Target: "agentB", Type: "assigned", Timestamp: "2024-01-01T00:00:00Z"},
+		},
+	}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/assignment-latency", nil)
+	rec := httptest.NewRecorder()
+	handleAssignmentLatency(rec, req)
+
+	var resp assignmentLatencyResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Latencies) != 1 || resp.Latencies[0].Seconds != 60 {
+		t.Fatalf("expected a single 60s latency, got %+v", resp.Latencies)
+	}
+	if resp.Excluded != 1 {
+		t.Fatalf("expected 1 excluded task, got %d", resp.Excluded)
+	}
+	if resp.P50 != 60 || resp.P99 != 60 {
+		t.Fatalf("expected p50 and p99 to both equal the single sample, got p50=%v p99=%v", resp.P50, resp.P99)
+	}
+}
+
+func TestLoadAgentSpecialtyWeightsFlagsAgentWhoseWeightsDontSumToHundred(t *testing.T) {
+	dir := t.TempDir()
+	write := func(filename, content string) {
+		if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test fixture: %v", err)
+		}
+	}
+	write("agent1.json", `{"agent_id": "agent1", "specialities": [{"name": "UI/UX", "weight": 60}, {"name": "BugFixes", "weight": 40}]}`)
+	write("agent2.json", `{"agent_id": "agent2", "specialities": [{"name": "UI/UX", "weight": 60}, {"name": "BugFixes", "weight": 30}]}`)
+
+	entries, err := loadAgentSpecialtyWeights(dir)
+	if err != nil {
+		t.Fatalf("loadAgentSpecialtyWeights failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 agents, got %d", len(entries))
+	}
+
+	byID := make(map[string]specialtyWeightsEntry, len(entries))
+	for _, e := range entries {
+		byID[e.AgentID] = e
+	}
+	if got := byID["agent1"]; got.WeightSum != 100 || !got.WeightsValid {
+		t.Fatalf("expected agent1 valid with sum 100, got %+v", got)
+	}
+	if got := byID["agent2"]; got.WeightSum != 90 || got.WeightsValid {
+		t.Fatalf("expected agent2 flagged invalid with sum 90, got %+v", got)
+	}
+}
+
+func TestHandleSpecialtyWeightsReturns404WithoutAgentsDirConfigured(t *testing.T) {
+	orig := agentsDir
+	agentsDir = ""
+	defer func() { agentsDir = orig }()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/specialty-weights", nil)
+	rec := httptest.NewRecorder()
+	handleSpecialtyWeights(rec, req)
+
+	if rec.Result().StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 when -agents-dir isn't configured, got %d", rec.Result().StatusCode)
+	}
+}
+
+func TestHandleSpecialtyWeightsServesFixtureAgent(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "agent1.json"), []byte(`{"agent_id": "agent1", "specialities": [{"name": "UI/UX", "weight": 100}]}`), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+
+	orig := agentsDir
+	agentsDir = dir
+	defer func() { agentsDir = orig }()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/specialty-weights", nil)
+	rec := httptest.NewRecorder()
+	handleSpecialtyWeights(rec, req)
+
+	var resp specialtyWeightsResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Agents) != 1 || resp.Agents[0].AgentID != "agent1" || !resp.Agents[0].WeightsValid {
+		t.Fatalf("expected agent1 with valid weights, got %+v", resp.Agents)
+	}
+}
+
+func TestTopSkillsOrdersByWeightDescendingAndTruncates(t *testing.T) {
+	specialities := []Speciality{
+		{Name: "Backend", Weight: 40},
+		{Name: "Frontend", Weight: 90},
+		{Name: "DevOps", Weight: 90},
+		{Name: "Data", Weight: 10},
+	}
+
+	got := topSkills(specialities, 3)
+
+	want := []string{"DevOps", "Frontend", "Backend"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d top skills, got %d: %+v", len(want), len(got), got)
+	}
+	for i, name := range want {
+		if got[i].Name != name {
+			t.Fatalf("expected top skill %d to be %q, got %+v", i, name, got)
+		}
+	}
+}
+
+func TestTopSkillsHandlesFewerSkillsThanRequested(t *testing.T) {
+	specialities := []Speciality{{Name: "Solo", Weight: 5}}
+
+	got := topSkills(specialities, 3)
+
+	if len(got) != 1 || got[0].Name != "Solo" {
+		t.Fatalf("expected single skill unchanged, got %+v", got)
+	}
+}
+
+func TestHandleAgentProfileReturns404WithoutAgentsDirConfigured(t *testing.T) {
+	orig := agentsDir
+	agentsDir = ""
+	defer func() { agentsDir = orig }()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agent-profile", nil)
+	rec := httptest.NewRecorder()
+	handleAgentProfile(rec, req)
+
+	if rec.Result().StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 when -agents-dir isn't configured, got %d", rec.Result().StatusCode)
+	}
+}
+
+func TestHandleAgentProfileServesTopSkillsForFixtureAgent(t *testing.T) {
+	dir := t.TempDir()
+	agentJSON := `{"agent_id": "agent1", "specialities": [
+		{"name": "UI/UX", "weight": 30},
+		{"name": "Backend", "weight": 80},
+		{"name": "DevOps", "weight": 60},
+		{"name": "QA", "weight": 10}
+	]}`
+	if err := os.WriteFile(filepath.Join(dir, "agent1.json"), []byte(agentJSON), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+
+	orig := agentsDir
+	agentsDir = dir
+	defer func() { agentsDir = orig }()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agent-profile", nil)
+	rec := httptest.NewRecorder()
+	handleAgentProfile(rec, req)
+
+	var resp agentProfileResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Agents) != 1 || resp.Agents[0].AgentID != "agent1" {
+		t.Fatalf("expected agent1 profile, got %+v", resp.Agents)
+	}
+	top := resp.Agents[0].TopSkills
+	if len(top) != 3 || top[0].Name != "Backend" || top[1].Name != "DevOps" || top[2].Name != "UI/UX" {
+		t.Fatalf("expected default top-3 skills ordered by weight, got %+v", top)
+	}
+}
+
+func TestHandleAgentProfileRespectsTopQueryParam(t *testing.T) {
+	dir := t.TempDir()
+	agentJSON := `{"agent_id": "agent1", "specialities": [
+		{"name": "UI/UX", "weight": 30},
+		{"name": "Backend", "weight": 80}
+	]}`
+	if err := os.WriteFile(filepath.Join(dir, "agent1.json"), []byte(agentJSON), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+
+	orig := agentsDir
+	agentsDir = dir
+	defer func() { agentsDir = orig }()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agent-profile?top=1", nil)
+	rec := httptest.NewRecorder()
+	handleAgentProfile(rec, req)
+
+	var resp agentProfileResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Agents) != 1 || len(resp.Agents[0].TopSkills) != 1 || resp.Agents[0].TopSkills[0].Name != "Backend" {
+		t.Fatalf("expected top=1 to return only Backend, got %+v", resp.Agents)
+	}
+}
+
+func TestHandleAgentProfileRejectsInvalidTopQueryParam(t *testing.T) {
+	dir := t.TempDir()
+	orig := agentsDir
+	agentsDir = dir
+	defer func() { agentsDir = orig }()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agent-profile?top=-1", nil)
+	rec := httptest.NewRecorder()
+	handleAgentProfile(rec, req)
+
+	if rec.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for negative top, got %d", rec.Result().StatusCode)
+	}
+}
+
+func TestComputeAgentBreadthAveragesAndBucketsSpecialtyCounts(t *testing.T) {
+	entries := []specialtyWeightsEntry{
+		{AgentID: "agent1", Specialities: []Speciality{{Name: "UI/UX"}}},
+		{AgentID: "agent2", Specialities: []Speciality{{Name: "UI/UX"}, {Name: "BugFixes"}}},
+		{AgentID: "agent3", Specialities: []Speciality{{Name: "UI/UX"}, {Name: "BugFixes"}, {Name: "ApplicationLogic"}}},
+		{AgentID: "agent4", Specialities: []Speciality{{Name: "UI/UX"}, {Name: "BugFixes"}, {Name: "ApplicationLogic"}, {Name: "ServerSideLogic"}}},
+	}
+
+	resp := computeAgentBreadth(entries)
+
+	if resp.AvgSpecialtiesPerAgent != 2.5 {
+		t.Fatalf("expected average 2.5, got %v", resp.AvgSpecialtiesPerAgent)
+	}
+	if resp.Distribution[1] != 1 || resp.Distribution[2] != 1 || resp.Distribution[3] != 2 {
+		t.Fatalf("expected distribution {1:1, 2:1, 3:2} (3+ bucketed together), got %+v", resp.Distribution)
+	}
+	if len(resp.Agents) != 4 {
+		t.Fatalf("expected 4 per-agent entries, got %d", len(resp.Agents))
+	}
+}
+
+func TestComputeAgentBreadthReturnsZeroValueForNoAgents(t *testing.T) {
+	resp := computeAgentBreadth(nil)
+	if resp.AvgSpecialtiesPerAgent != 0 || len(resp.Agents) != 0 {
+		t.Fatalf("expected a zero-value response for no agents, got %+v", resp)
+	}
+}
+
+func TestHandleAgentBreadthReturns404WithoutAgentsDirConfigured(t *testing.T) {
+	orig := agentsDir
+	agentsDir = ""
+	defer func() { agentsDir = orig }()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agent-breadth", nil)
+	rec := httptest.NewRecorder()
+	handleAgentBreadth(rec, req)
+
+	if rec.Result().StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 when -agents-dir isn't configured, got %d", rec.Result().StatusCode)
+	}
+}
+
+func TestHandleAgentBreadthServesFixtureAgents(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "agent1.json"), []byte(`{"agent_id": "agent1", "specialities": [{"name": "UI/UX", "weight": 100}]}`), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "agent2.json"), []byte(`{"agent_id": "agent2", "specialities": [{"name": "UI/UX", "weight": 50}, {"name": "BugFixes", "weight": 50}]}`), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+
+	orig := agentsDir
+	agentsDir = dir
+	defer func() { agentsDir = orig }()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agent-breadth", nil)
+	rec := httptest.NewRecorder()
+	handleAgentBreadth(rec, req)
+
+	var resp agentBreadthResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.AvgSpecialtiesPerAgent != 1.5 {
+		t.Fatalf("expected average 1.5, got %v", resp.AvgSpecialtiesPerAgent)
+	}
+}
+
+func TestComputeNodeValueAndBidCounts(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{{ID: "agentA"}, {ID: "agentB"}, {ID: "task1"}},
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "bid", BidValue: 120},
+			{Source: "agentB", Target: "task1", Type: "bid", BidValue: 150},
+			{Source: "task1", Target: "agentA", Type: "assigned"},
+		},
+	}
+	values, bidCounts := computeNodeValueAndBidCounts()
+	mu.Unlock()
+
+	if values["agentA"] != 120 {
+		t.Fatalf("expected agentA won value 120, got %v", values["agentA"])
+	}
+	if values["agentB"] != 0 {
+		t.Fatalf("expected agentB won value 0, got %v", values["agentB"])
+	}
+	if bidCounts["agentA"] != 1 {
+		t.Fatalf("expected agentA bid count 1, got %d", bidCounts["agentA"])
+	}
+	if bidCounts["task1"] != 2 {
+		t.Fatalf("expected task1 bid count 2 (received from both agents), got %d", bidCounts["task1"])
+	}
+}
+
+func TestConsistencyChecksAgreeOnUnperturbedNetwork(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{{ID: "agentA"}, {ID: "agentB"}, {ID: "task1"}},
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "bid", BidValue: 120},
+			{Source: "agentB", Target: "task1", Type: "bid", BidValue: 150},
+		},
+	}
+	degreeCache = nil
+	checks := computeConsistencyChecks(1e-9)
+	mu.Unlock()
+
+	for _, c := range checks {
+		if !c.Consistent {
+			t.Fatalf("expected %s to be consistent on an unperturbed network, got %+v", c.Name, c)
+		}
+	}
+}
+
+func TestConsistencyChecksFlagPerturbedDegree(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{{ID: "agentA"}, {ID: "agentB"}, {ID: "task1"}},
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "bid", BidValue: 120},
+			{Source: "agentB", Target: "task1", Type: "bid", BidValue: 150},
+		},
+	}
+	degreeCache = computeDegrees()
+	degreeCache["agentA"] += 10 // perturb one node's cached degree
+	checks := computeConsistencyChecks(1e-9)
+	mu.Unlock()
+
+	var density *ConsistencyCheck
+	for i, c := range checks {
+		if c.Name == "network_density" {
+			density = &checks[i]
+		}
+	}
+	if density == nil {
+		t.Fatal("expected a network_density check")
+	}
+	if density.Consistent {
+		t.Fatalf("expected the perturbed degree to flag network_density as inconsistent, got %+v", density)
+	}
+}
+
+func TestClassifyAuctionFailureReasons(t *testing.T) {
+	reserved := Node{ID: "taskReserve", Type: "issue", PriceMax: 100}
+	underBidders := Node{ID: "taskFewBidders", Type: "issue"}
+
+	if got := classifyAuctionFailure(Node{ID: "taskNone", Type: "issue"}, nil, 2); got != reasonNoBidders {
+		t.Errorf("expected %s, got %s", reasonNoBidders, got)
+	}
+	overReserveBids := []Edge{{Source: "agentA", Target: "taskReserve", BidValue: 150}}
+	if got := classifyAuctionFailure(reserved, overReserveBids, 1); got != reasonAllBidsOverReserve {
+		t.Errorf("expected %s, got %s", reasonAllBidsOverReserve, got)
+	}
+	tooFewBids := []Edge{{Source: "agentA", Target: "taskFewBidders", BidValue: 50}}
+	if got := classifyAuctionFailure(underBidders, tooFewBids, 2); got != reasonMinBiddersNotMet {
+		t.Errorf("expected %s, got %s", reasonMinBiddersNotMet, got)
+	}
+}
+
+func TestHandleAuctionFailuresCategorizesUnassignedTasks(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{
+			{ID: "agentA", Type: "agent"},
+			{ID: "agentB", Type: "agent"},
+			{ID: "taskNoBids", Type: "issue"},
+			{ID: "taskOverReserve", Type: "issue", PriceMax: 100},
+			{ID: "taskFewBidders", Type: "issue"},
+			{ID: "taskAssigned", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agentA", Target: "taskOverReserve", Type: "bid", BidValue: 150},
+			{Source: "agentA", Target: "taskFewBidders", Type: "bid", BidValue: 50},
+			{Source: "agentA", Target: "taskAssigned", Type: "bid", BidValue: 50},
+			{Source: "agentB", Target: "taskAssigned", Type: "bid", BidValue: 60},
+			{Source: "taskAssigned", Target: "agentA", Type: "assigned"},
+		},
+	}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auction-failures?min_bidders=2", nil)
+	rec := httptest.NewRecorder()
+	handleAuctionFailures(rec, req)
+
+	var resp auctionFailuresResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	byReason := make(map[string]auctionFailureBreakdown, len(resp.Breakdown))
+	for _, b := range resp.Breakdown {
+		byReason[b.Reason] = b
+	}
+	if tasks := byReason[reasonNoBidders].Tasks; len(tasks) != 1 || tasks[0] != "taskNoBids" {
+		t.Errorf("expected taskNoBids under %s, got %+v", reasonNoBidders, tasks)
+	}
+	if tasks := byReason[reasonAllBidsOverReserve].Tasks; len(tasks) != 1 || tasks[0] != "taskOverReserve" {
+		t.Errorf("expected taskOverReserve under %s, got %+v", reasonAllBidsOverReserve, tasks)
+	}
+	if tasks := byReason[reasonMinBiddersNotMet].Tasks; len(tasks) != 1 || tasks[0] != "taskFewBidders" {
+		t.Errorf("expected taskFewBidders under %s, got %+v", reasonMinBiddersNotMet, tasks)
+	}
+}
+
+// TestSnapshotReloadUnderConcurrentReads hammers getSnapshot while reloadSnapshot
+// repeatedly swaps in freshly built snapshots, to catch torn reads and data
+// races (run with -race).
+func TestSnapshotReloadUnderConcurrentReads(t *testing.T) {
+	reloadSnapshot(Network{
+		Nodes: []Node{{ID: "agentA"}, {ID: "task1"}},
+		Edges: []Edge{{Source: "agentA", Target: "task1", Type: "bid", BidValue: 10}},
+	})
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				snap := getSnapshot()
+				if len(snap.Degrees) != len(snap.Network.Nodes) {
+					t.Errorf("torn snapshot: %d degrees for %d nodes", len(snap.Degrees), len(snap.Network.Nodes))
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		agentID := fmt.Sprintf("agent%d", i)
+		reloadSnapshot(Network{
+			Nodes: []Node{{ID: agentID}, {ID: "task1"}},
+			Edges: []Edge{{Source: agentID, Target: "task1", Type: "bid", BidValue: float64(i)}},
+		})
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestComputeAgentTimeMetrics(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{{ID: "agentA"}, {ID: "agentB"}, {ID: "task1"}},
+		Edges: []Edge{
+			{Source: "agentA", Target: "task1", Type: "bid", BidValue: 100, Timestamp: "2026-01-01T00:00:00Z"},
+			{Source: "agentA", Target: "task1", Type: "bid", BidValue: 90, Timestamp: "2026-01-01T01:00:00Z"},
+			{Source: "task1", Target: "agentA", Type: "assigned", Timestamp: "2026-01-01T03:00:00Z"},
+			{Source: "agentB", Target: "task1", Type: "bid", BidValue: 95, Timestamp: "2026-01-01T00:30:00Z"},
+		},
+	}
+	metrics := computeAgentTimeMetrics()
+	mu.Unlock()
+
+	byID := make(map[string]AgentTimeMetrics, len(metrics))
+	for _, m := range metrics {
+		byID[m.AgentID] = m
+	}
+
+	agentA := byID["agentA"]
+	if agentA.TimeToFirstWin == nil || *agentA.TimeToFirstWin != 3*3600 {
+		t.Fatalf("expected agentA time-to-first-win of 3h, got %v", agentA.TimeToFirstWin)
+	}
+	if agentA.ActivitySpan == nil || *agentA.ActivitySpan != 3*3600 {
+		t.Fatalf("expected agentA activity span of 3h, got %v", agentA.ActivitySpan)
+	}
+
+	agentB := byID["agentB"]
+	if agentB.TimeToFirstWin != nil {
+		t.Fatalf("expected agentB to have no win, got %v", *agentB.TimeToFirstWin)
+	}
+	if agentB.ActivitySpan == nil || *agentB.ActivitySpan != 0 {
+		t.Fatalf("expected agentB activity span of 0 (single event), got %v", agentB.ActivitySpan)
+	}
+}
+
+func TestComputeAgentLeaderboardOrdersByComposite(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{
+			{ID: "agentSpecialist", Type: "agent", Specialities: []Speciality{{Name: "UI/UX"}}},
+			{ID: "agentGeneralist", Type: "agent"},
+			{ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "UI/UX"}},
+			{ID: "task2", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agentSpecialist", Target: "task1", Type: "bid", BidValue: 100},
+			{Source: "agentGeneralist", Target: "task2", Type: "bid", BidValue: 50},
+			{Source: "task1", Target: "agentSpecialist", Type: "assigned"},
+			{Source: "task2", Target: "agentGeneralist", Type: "assigned"},
+		},
+	}
+	weights := leaderboardWeights{WinRate: 0.4, Value: 0.4, Specialization: 0.2}
+	entries := computeAgentLeaderboard(weights)
+	mu.Unlock()
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 leaderboard entries, got %d", len(entries))
+	}
+
+	byID := make(map[string]AgentLeaderboardEntry, len(entries))
+	for _, e := range entries {
+		byID[e.AgentID] = e
+	}
+	specialist := byID["agentSpecialist"]
+	generalist := byID["agentGeneralist"]
+
+	wantSpecialist := weights.WinRate*specialist.WinRate + weights.Value*1.0 + weights.Specialization*1.0
+	if specialist.Composite != wantSpecialist {
+		t.Fatalf("expected specialist composite %v, got %v", wantSpecialist, specialist.Composite)
+	}
+	wantGeneralist := weights.WinRate*generalist.WinRate + weights.Value*(50.0/100.0) + weights.Specialization*0.0
+	if generalist.Composite != wantGeneralist {
+		t.Fatalf("expected generalist composite %v, got %v", wantGeneralist, generalist.Composite)
+	}
+	if entries[0].AgentID != "agentSpecialist" {
+		t.Fatalf("expected agentSpecialist to rank first, got %s", entries[0].AgentID)
+	}
+}
+
+func TestComputeBidShadingSignsMatchOverAndUnderBidders(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{
+			{ID: "winner", Type: "agent"},
+			{ID: "overBidder", Type: "agent"},
+			{ID: "underBidder", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+			{ID: "task2", Type: "issue"},
+		},
+		Edges: []Edge{
+			// Both tasks clear at 100, always won by "winner".
+			{Source: "winner", Target: "task1", Type: "bid", BidValue: 100},
+			{Source: "task1", Target: "winner", Type: "assigned"},
+			{Source: "winner", Target: "task2", Type: "bid", BidValue: 100},
+			{Source: "task2", Target: "winner", Type: "assigned"},
+			// overBidder consistently bids above the clearing price.
+			{Source: "overBidder", Target: "task1", Type: "bid", BidValue: 130},
+			{Source: "overBidder", Target: "task2", Type: "bid", BidValue: 140},
+			// underBidder consistently bids below the clearing price.
+			{Source: "underBidder", Target: "task1", Type: "bid", BidValue: 70},
+			{Source: "underBidder", Target: "task2", Type: "bid", BidValue: 60},
+		},
+	}
+	metrics := computeBidShading()
+	mu.Unlock()
+
+	byID := make(map[string]BidShadingMetric, len(metrics))
+	for _, m := range metrics {
+		byID[m.AgentID] = m
+	}
+	if len(byID) != 3 {
+		t.Fatalf("expected 3 agents with bid-shading metrics, got %d", len(byID))
+	}
+	if over := byID["overBidder"]; over.AverageMarkup <= 0 {
+		t.Errorf("expected overBidder to have a positive average markup, got %v", over.AverageMarkup)
+	}
+	if under := byID["underBidder"]; under.AverageMarkup >= 0 {
+		t.Errorf("expected underBidder to have a negative average markup, got %v", under.AverageMarkup)
+	}
+}
+
+func TestComputeBidSpreadsOrdersByTightestCompetition(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"},
+			{ID: "agent2", Type: "agent"},
+			{ID: "agent3", Type: "agent"},
+			{ID: "taskTight", Type: "issue"},
+			{ID: "taskWide", Type: "issue"},
+			{ID: "taskSingleBid", Type: "issue"},
+		},
+		Edges: []Edge{
+			// Spread: (110-100)/100 = 0.1
+			{Source: "agent1", Target: "taskTight", Type: "bid", BidValue: 100},
+			{Source: "agent2", Target: "taskTight", Type: "bid", BidValue: 110},
+			// Spread: (300-100)/100 = 2.0
+			{Source: "agent1", Target: "taskWide", Type: "bid", BidValue: 100},
+			{Source: "agent2", Target: "taskWide", Type: "bid", BidValue: 300},
+			// Only 1 bid: must be skipped.
+			{Source: "agent3", Target: "taskSingleBid", Type: "bid", BidValue: 50},
+		},
+	}
+	spreads := computeBidSpreads()
+	mu.Unlock()
+
+	if len(spreads) != 2 {
+		t.Fatalf("expected 2 tasks with bid spreads, got %d: %+v", len(spreads), spreads)
+	}
+	if spreads[0].TaskID != "taskTight" || spreads[0].Spread != 0.1 {
+		t.Fatalf("expected taskTight first with spread 0.1, got %+v", spreads[0])
+	}
+	if spreads[1].TaskID != "taskWide" || spreads[1].Spread != 2.0 {
+		t.Fatalf("expected taskWide second with spread 2.0, got %+v", spreads[1])
+	}
+}
+
+func TestHandleAgentMetricsCSVWritesHeaderAndOneRowPerAgent(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{{ID: "agent1", Type: "agent"}, {ID: "task1", Type: "issue"}},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 100},
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+		},
+	}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agent-metrics.csv", nil)
+	rec := httptest.NewRecorder()
+	handleAgentMetricsCSV(rec, req)
+
+	if ct := rec.Result().Header.Get("Content-Type"); ct != "text/csv" {
+		t.Fatalf("expected Content-Type text/csv, got %q", ct)
+	}
+	if cd := rec.Result().Header.Get("Content-Disposition"); !strings.Contains(cd, "attachment") {
+		t.Fatalf("expected an attachment Content-Disposition, got %q", cd)
+	}
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected a header row plus one agent row, got %d: %v", len(rows), rows)
+	}
+	wantHeader := []string{"agent_id", "bids_submitted", "bids_won", "win_rate"}
+	for i, col := range wantHeader {
+		if rows[0][i] != col {
+			t.Fatalf("expected header column %d to be %q, got %q", i, col, rows[0][i])
+		}
+	}
+	if rows[1][0] != "agent1" || rows[1][1] != "1" || rows[1][2] != "1" || rows[1][3] != "1.0000" {
+		t.Fatalf("expected agent1 row with 1 bid, 1 win, win_rate 1.0000, got %v", rows[1])
+	}
+}
+
+func TestHandleAgentMetricsCSVEmitsOnlyHeaderWhenNoAgents(t *testing.T) {
+	mu.Lock()
+	network = Network{}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agent-metrics.csv", nil)
+	rec := httptest.NewRecorder()
+	handleAgentMetricsCSV(rec, req)
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected only the header row for an empty network, got %d: %v", len(rows), rows)
+	}
+}
+
+func TestHandleTaskMetricsCSVWritesHeaderAndOneRowPerTask(t *testing.T) {
+	mu.Lock()
+	network = Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"}, {ID: "agent2", Type: "agent"}, {ID: "task1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 100},
+			{Source: "agent2", Target: "task1", Type: "bid", BidValue: 150},
+		},
+	}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/task-metrics.csv", nil)
+	rec := httptest.NewRecorder()
+	handleTaskMetricsCSV(rec, req)
+
+	if cd := rec.Result().Header.Get("Content-Disposition"); !strings.Contains(cd, "attachment") {
+		t.Fatalf("expected an attachment Content-Disposition, got %q", cd)
+	}
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected a header row plus one task row, got %d: %v", len(rows), rows)
+	}
+	wantHeader := []string{"task_id", "spread", "num_bids", "min_bid", "max_bid"}
+	for i, col := range wantHeader {
+		if rows[0][i] != col {
+			t.Fatalf("expected header column %d to be %q, got %q", i, col, rows[0][i])
+		}
+	}
+	if rows[1][0] != "task1" || rows[1][1] != "0.5000" || rows[1][2] != "2" || rows[1][3] != "100.0000" || rows[1][4] != "150.0000" {
+		t.Fatalf("expected task1 row with spread 0.5000, got %v", rows[1])
+	}
+}
+
+func TestHandleTaskMetricsCSVEmitsOnlyHeaderWhenNoTasksHaveMultipleBids(t *testing.T) {
+	mu.Lock()
+	network = Network{}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/task-metrics.csv", nil)
+	rec := httptest.NewRecorder()
+	handleTaskMetricsCSV(rec, req)
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected only the header row for an empty network, got %d: %v", len(rows), rows)
+	}
+}
+
+func TestServeDashboardUsesCurrencySymbol(t *testing.T) {
+	original := currencySymbol
+	currencySymbol = "€"
+	defer func() { currencySymbol = original }()
+
+	mu.Lock()
+	network = Network{Nodes: []Node{{ID: "task1", Type: "issue"}}}
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	serveDashboard(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `window.currencySymbol = "€"`) {
+		t.Fatalf("expected injected currency symbol to be euro sign, got body: %s", body)
+	}
+	if strings.Contains(body, `window.currencySymbol = "$"`) {
+		t.Fatalf("expected dollar sign to be replaced, got body: %s", body)
+	}
+}
+
+func fixtureParetoNetwork() Network {
+	bids := func(agentID string, taskIDs []string, value float64) []Edge {
+		edges := make([]Edge, len(taskIDs))
+		for i, taskID := range taskIDs {
+			edges[i] = Edge{Source: agentID, Target: taskID, Type: "bid", BidValue: value}
+		}
+		return edges
+	}
+	won := func(agentID, taskID string) Edge {
+		return Edge{Source: taskID, Target: agentID, Type: "assigned"}
+	}
+
+	n := Network{Edges: []Edge{}}
+	n.Edges = append(n.Edges, bids("agentBest", []string{"task1", "task2"}, 50)...)
+	n.Edges = append(n.Edges, won("agentBest", "task1"), won("agentBest", "task2"))
+
+	n.Edges = append(n.Edges, bids("agentWorse", []string{"task3", "task4"}, 100)...)
+	n.Edges = append(n.Edges, won("agentWorse", "task3"))
+
+	n.Edges = append(n.Edges, bids("agentDominated", []string{"task5", "task6"}, 80)...)
+	n.Edges = append(n.Edges, won("agentDominated", "task5"))
+
+	n.Edges = append(n.Edges, bids("agentCheap", []string{"task7", "task8", "task9", "task10", "task11"}, 20)...)
+	n.Edges = append(n.Edges, won("agentCheap", "task7"))
+
+	return n
+}
+
+func TestComputeParetoFrontMarksDominatedAgents(t *testing.T) {
+	mu.Lock()
+	network = fixtureParetoNetwork()
+	agents, err := computeParetoFront("avg_bid_value", "win_rate")
+	mu.Unlock()
+	if err != nil {
+		t.Fatalf("computeParetoFront failed: %v", err)
+	}
+
+	byID := make(map[string]ParetoAgent, len(agents))
+	for _, a := range agents {
+		byID[a.AgentID] = a
+	}
+	if len(byID) != 4 {
+		t.Fatalf("expected 4 agents, got %d: %+v", len(byID), agents)
+	}
+	if byID["agentBest"].Dominated {
+		t.Errorf("expected agentBest (cheapest and highest win rate) to be on the frontier, got %+v", byID["agentBest"])
+	}
+	if byID["agentCheap"].Dominated {
+		t.Errorf("expected agentCheap (cheapest overall) to be on the frontier, got %+v", byID["agentCheap"])
+	}
+	if !byID["agentWorse"].Dominated {
+		t.Errorf("expected agentWorse to be dominated by agentBest, got %+v", byID["agentWorse"])
+	}
+	if !byID["agentDominated"].Dominated {
+		t.Errorf("expected agentDominated to be dominated by agentBest, got %+v", byID["agentDominated"])
+	}
+}
+
+func fixtureEngagementNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"},
+			{ID: "agent2", Type: "agent"},
+			{ID: "taskUI1", Type: "issue", Speciality: &TaskSpeciality{Name: "UI/UX"}},
+			{ID: "taskUI2", Type: "issue", Speciality: &TaskSpeciality{Name: "UI/UX"}},
+			{ID: "taskAPI1", Type: "issue", Speciality: &TaskSpeciality{Name: "ServerSideLogic"}},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "taskUI1", Type: "bid"},
+			{Source: "agent1", Target: "taskUI2", Type: "bid"},
+			{Source: "agent1", Target: "taskAPI1", Type: "bid"},
+			{Source: "agent2", Target: "taskAPI1", Type: "bid"},
+		},
+	}
+}
+
+func TestBuildEngagementMatrixResponseAgentNormalizationRowsSumToOne(t *testing.T) {
+	mu.Lock()
+	network = fixtureEngagementNetwork()
+	agentIDs, skills, raw := computeEngagementMatrix()
+	mu.Unlock()
+
+	resp, err := buildEngagementMatrixResponse(agentIDs, skills, raw, "agent")
+	if err != nil {
+		t.Fatalf("buildEngagementMatrixResponse failed: %v", err)
+	}
+	for _, agentID := range resp.Agents {
+		sum := 0.0
+		for _, s := range resp.Skills {
+			sum += resp.Matrix[agentID][s]
+		}
+		if agentID == "agent1" && (sum < 0.999 || sum > 1.001) {
+			t.Errorf("expected agent1's row to sum to 1, got %v: %+v", sum, resp.Matrix[agentID])
+		}
+		if agentID == "agent2" && (sum < 0.999 || sum > 1.001) {
+			t.Errorf("expected agent2's row to sum to 1, got %v: %+v", sum, resp.Matrix[agentID])
+		}
+	}
+}
+
+func TestBuildEngagementMatrixResponseSkillNormalizationColumnsSumToOne(t *testing.T) {
+	mu.Lock()
+	network = fixtureEngagementNetwork()
+	agentIDs, skills, raw := computeEngagementMatrix()
+	mu.Unlock()
+
+	resp, err := buildEngagementMatrixResponse(agentIDs, skills, raw, "skill")
+	if err != nil {
+		t.Fatalf("buildEngagementMatrixResponse failed: %v", err)
+	}
+	for _, s := range resp.Skills {
+		sum := 0.0
+		for _, agentID := range resp.Agents {
+			sum += resp.Matrix[agentID][s]
+		}
+		if sum < 0.999 || sum > 1.001 {
+			t.Errorf("expected skill %q's column to sum to 1, got %v", s, sum)
+		}
+	}
+}
+
+func TestBuildEngagementMatrixResponseRejectsUnknownMode(t *testing.T) {
+	_, err := buildEngagementMatrixResponse(nil, nil, nil, "bogus")
+	if err == nil {
+		t.Fatal("expected an error for an unknown normalize mode, got nil")
+	}
+}
+
+func TestComputeParetoFrontRejectsUnknownMetric(t *testing.T) {
+	mu.Lock()
+	network = fixtureParetoNetwork()
+	_, err := computeParetoFront("bogus_metric", "win_rate")
+	mu.Unlock()
+	if err == nil {
+		t.Fatal("expected an error for an unknown metric name, got nil")
+	}
+}
+
+// TestMetricSourcesDescribesEveryExposedMetricEndpoint confirms
+// /api/metric-sources documents every metric-producing endpoint registered
+// in main(), so the descriptor can't silently drift out of sync as new
+// endpoints are added.
+func TestMetricSourcesDescribesEveryExposedMetricEndpoint(t *testing.T) {
+	exposedEndpoints := []string{
+		"/api/specialist-preference",
+		"/api/degree-tail",
+		"/api/win-rate-histogram",
+		"/api/consistency",
+		"/api/auction-failures",
+		"/api/agent-metrics",
+		"/api/agent-leaderboard",
+		"/api/bid-shading",
+		"/api/bid-spread",
+		"/api/pareto",
+		"/api/engagement-matrix",
+		"/api/assignment-distribution",
+		"/api/sparklines",
+		"/api/agent-mobility",
+		"/api/assignment-latency",
+		"/api/shut-out-agents",
+		"/api/agent-profile",
+		"/api/agent-breadth",
+		"/api/task-segments",
+	}
+
+	documented := make(map[string]bool)
+	for _, m := range metricSources {
+		if m.Metric == "" || m.Inputs == "" || m.Formula == "" {
+			t.Fatalf("metric source for %s has an empty field: %+v", m.Endpoint, m)
+		}
+		documented[m.Endpoint] = true
+	}
+
+	for _, endpoint := range exposedEndpoints {
+		if !documented[endpoint] {
+			t.Fatalf("expected /api/metric-sources to document %s, but it doesn't", endpoint)
+		}
+	}
+}
+
+func TestHandleMetricSourcesReturnsDescriptorList(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/metric-sources", nil)
+	rec := httptest.NewRecorder()
+	handleMetricSources(rec, req)
+
+	var resp metricSourcesResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Metrics) != len(metricSources) {
+		t.Fatalf("expected %d metrics, got %d", len(metricSources), len(resp.Metrics))
+	}
+}
+
+// fixtureAssignmentDistributionNetwork has a known assignment pattern: agent1
+// idle, agent2 with 2 wins, agent3 and agent4 with 3 wins each, agent5 with 4
+// wins (exceeding a cap of 3); task0 unassigned, task1-task10 with exactly
+// one winner, task11 double-assigned to agent2 and agent3.
+func fixtureAssignmentDistributionNetwork() Network {
+	n := Network{}
+	for i := 1; i <= 5; i++ {
+		n.Nodes = append(n.Nodes, Node{ID: fmt.Sprintf("agent%d", i), Type: "agent"})
+	}
+	for i := 0; i <= 11; i++ {
+		n.Nodes = append(n.Nodes, Node{ID: fmt.Sprintf("task%d", i), Type: "issue"})
+	}
+	assign := func(task, agent string) {
+		n.Edges = append(n.Edges, Edge{Source: task, Target: agent, Type: "assigned"})
+	}
+	assign("task1", "agent2")
+	assign("task2", "agent3")
+	assign("task3", "agent3")
+	assign("task4", "agent4")
+	assign("task5", "agent4")
+	assign("task6", "agent4")
+	assign("task7", "agent5")
+	assign("task8", "agent5")
+	assign("task9", "agent5")
+	assign("task10", "agent5")
+	assign("task11", "agent2")
+	assign("task11", "agent3")
+	return n
+}
+
+func bucketFrequency(t *testing.T, buckets []cardinalityBucket, label string) int {
+	t.Helper()
+	for _, b := range buckets {
+		if b.Label == label {
+			return b.Frequency
+		}
+	}
+	t.Fatalf("no bucket labeled %q in %+v", label, buckets)
+	return 0
+}
+
+func TestComputeAssignmentDistributionHistogramsKnownPattern(t *testing.T) {
+	mu.Lock()
+	network = fixtureAssignmentDistributionNetwork()
+	tasksPerAgent, agentsPerTask := computeAssignmentDistribution(3)
+	mu.Unlock()
+
+	if got := bucketFrequency(t, tasksPerAgent, "0"); got != 1 {
+		t.Fatalf("expected 1 idle agent (agent1), got %d", got)
+	}
+	if got := bucketFrequency(t, tasksPerAgent, "1"); got != 0 {
+		t.Fatalf("expected 0 agents with exactly 1 task, got %d", got)
+	}
+	if got := bucketFrequency(t, tasksPerAgent, "2"); got != 1 {
+		t.Fatalf("expected 1 agent with 2 tasks (agent2), got %d", got)
+	}
+	if got := bucketFrequency(t, tasksPerAgent, "3+"); got != 3 {
+		t.Fatalf("expected 3 agents at or above the cap (agent3, agent4, agent5), got %d", got)
+	}
+
+	if got := bucketFrequency(t, agentsPerTask, "0"); got != 1 {
+		t.Fatalf("expected 1 unassigned task (task0), got %d", got)
+	}
+	if got := bucketFrequency(t, agentsPerTask, "1"); got != 10 {
+		t.Fatalf("expected 10 singly-assigned tasks, got %d", got)
+	}
+	if got := bucketFrequency(t, agentsPerTask, "2"); got != 1 {
+		t.Fatalf("expected 1 doubly-assigned task (task11), got %d", got)
+	}
+}
+
+func TestHandleAssignmentDistributionRejectsInvalidCap(t *testing.T) {
+	mu.Lock()
+	network = fixtureAssignmentDistributionNetwork()
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/assignment-distribution?cap=-1", nil)
+	rec := httptest.NewRecorder()
+	handleAssignmentDistribution(rec, req)
+	if rec.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a negative cap, got %d", rec.Result().StatusCode)
+	}
+}
+
+// fixtureLargeDataNetwork builds a network with n agent/task pairs, each
+// connected by a bid edge, for benchmarking handleData at scale.
+func fixtureLargeDataNetwork(n int) Network {
+	nodes := make([]Node, 0, 2*n)
+	edges := make([]Edge, 0, n)
+	for i := 0; i < n; i++ {
+		agentID := fmt.Sprintf("agent%d", i)
+		taskID := fmt.Sprintf("task%d", i)
+		nodes = append(nodes, Node{ID: agentID, Type: "agent"})
+		nodes = append(nodes, Node{ID: taskID, Type: "issue"})
+		edges = append(edges, Edge{Source: agentID, Target: taskID, Type: "bid", BidValue: float64(i)})
+	}
+	return Network{Nodes: nodes, Edges: edges}
+}
+
+// BenchmarkHandleDataLargeGraph confirms handleData's peak allocation stays
+// proportional to the response it builds, not a multiple of it, now that it
+// encodes directly into the ResponseWriter instead of buffering first. Run
+// with -benchmem to see allocations.
+func BenchmarkHandleDataLargeGraph(b *testing.B) {
+	mu.Lock()
+	network = fixtureLargeDataNetwork(50000)
+	degreeCache = nil
+	mu.Unlock()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/data", nil)
+		rec := httptest.NewRecorder()
+		handleData(rec, req)
+	}
+}
+
+func TestReloadNetworkFromDiskSwapsNetworkAndRebuildsSnapshot(t *testing.T) {
+	mu.Lock()
+	network = Network{Nodes: []Node{{ID: "agent1", Type: "agent"}}}
+	degreeCache = map[string]int{"stale": 1}
+	mu.Unlock()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "network.json")
+	updated := `{"nodes":[{"id":"agent1","type":"agent"},{"id":"task1","type":"issue"}],"edges":[{"source":"agent1","target":"task1","type":"bid"}]}`
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		t.Fatalf("failed to write fixture network file: %v", err)
+	}
+
+	reloadNetworkFromDisk(path)
+
+	mu.Lock()
+	got := network
+	cacheCleared := degreeCache == nil
+	mu.Unlock()
+
+	if len(got.Nodes) != 2 || len(got.Edges) != 1 {
+		t.Fatalf("expected reloaded network with 2 nodes and 1 edge, got %d nodes and %d edges", len(got.Nodes), len(got.Edges))
+	}
+	if !cacheCleared {
+		t.Fatalf("expected degreeCache to be invalidated after reload")
+	}
+	if getSnapshot().Network.Nodes == nil {
+		t.Fatalf("expected snapshot to be rebuilt from the reloaded network")
+	}
+}
+
+func TestReloadNetworkFromDiskKeepsPreviousNetworkOnParseError(t *testing.T) {
+	mu.Lock()
+	network = Network{Nodes: []Node{{ID: "agent1", Type: "agent"}}}
+	mu.Unlock()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "network.json")
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatalf("failed to write fixture network file: %v", err)
+	}
+
+	reloadNetworkFromDisk(path)
+
+	mu.Lock()
+	got := network
+	mu.Unlock()
+	if len(got.Nodes) != 1 || got.Nodes[0].ID != "agent1" {
+		t.Fatalf("expected previous network to survive a failed reload, got %+v", got)
+	}
+}
+
+func TestWatchNetworkFileReloadsAfterDebouncedWrite(t *testing.T) {
+	mu.Lock()
+	network = Network{Nodes: []Node{{ID: "agent1", Type: "agent"}}}
+	mu.Unlock()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "network.json")
+	if err := os.WriteFile(path, []byte(`{"nodes":[{"id":"agent1","type":"agent"}],"edges":[]}`), 0644); err != nil {
+		t.Fatalf("failed to write initial fixture: %v", err)
+	}
+
+	if err := watchNetworkFile(path, 20*time.Millisecond); err != nil {
+		t.Fatalf("watchNetworkFile failed: %v", err)
+	}
+
+	updated := `{"nodes":[{"id":"agent1","type":"agent"},{"id":"task1","type":"issue"}],"edges":[]}`
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		t.Fatalf("failed to write updated fixture: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(network.Nodes)
+		mu.Unlock()
+		if n == 2 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("expected watchNetworkFile to reload the network within the deadline")
+}
+
+func TestHandleWebSocketSendsInitialSnapshotThenUpdatesOnBroadcast(t *testing.T) {
+	mu.Lock()
+	network = fixtureLargeDataNetwork(2)
+	mu.Unlock()
+
+	server := httptest.NewServer(http.HandlerFunc(handleWebSocket))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	defer conn.Close()
+
+	_, initial, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read initial snapshot: %v", err)
+	}
+	var initialMetrics MarketMetrics
+	if err := json.Unmarshal(initial, &initialMetrics); err != nil {
+		t.Fatalf("failed to decode initial snapshot: %v", err)
+	}
+	if len(initialMetrics.Agents) != 2 {
+		t.Fatalf("expected 2 agents in initial snapshot, got %d", len(initialMetrics.Agents))
+	}
+
+	mu.Lock()
+	network = fixtureLargeDataNetwork(5)
+	mu.Unlock()
+	broadcastMarketMetrics()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, update, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read update after broadcast: %v", err)
+	}
+	var updatedMetrics MarketMetrics
+	if err := json.Unmarshal(update, &updatedMetrics); err != nil {
+		t.Fatalf("failed to decode update: %v", err)
+	}
+	if len(updatedMetrics.Agents) != 5 {
+		t.Fatalf("expected 5 agents after update, got %d", len(updatedMetrics.Agents))
+	}
+}
+
+func TestHandleWebSocketRejectsConnectionsPastTheCap(t *testing.T) {
+	mu.Lock()
+	network = fixtureLargeDataNetwork(1)
+	mu.Unlock()
+
+	orig := maxWSConnections
+	maxWSConnections = 1
+	defer func() { maxWSConnections = orig }()
+
+	server := httptest.NewServer(http.HandlerFunc(handleWebSocket))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to dial first websocket: %v", err)
+	}
+	defer conn.Close()
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("failed to read initial snapshot on first connection: %v", err)
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatalf("expected second connection past the cap to be rejected")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for the rejected connection, got %v", resp)
+	}
+}
+
+func TestHandleWebSocketRemovesClientOnDisconnect(t *testing.T) {
+	mu.Lock()
+	network = fixtureLargeDataNetwork(1)
+	mu.Unlock()
+
+	server := httptest.NewServer(http.HandlerFunc(handleWebSocket))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("failed to read initial snapshot: %v", err)
+	}
+	conn.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		wsClientsMu.Lock()
+		n := len(wsClients)
+		wsClientsMu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("expected disconnected client to be removed from wsClients")
+}
+
+func fixtureTwoClusterNetwork() Network {
+	nodes := []Node{}
+	edges := []Edge{}
+	addTask := func(id string, price float64, bidCount int) {
+		nodes = append(nodes, Node{ID: id, Type: "issue"})
+		for i := 0; i < bidCount; i++ {
+			agentID := fmt.Sprintf("agent-%s-%d", id, i)
+			nodes = append(nodes, Node{ID: agentID, Type: "agent"})
+			edges = append(edges, Edge{Source: agentID, Target: id, Type: "bid", BidValue: price})
+		}
+	}
+	// Cluster A: cheap and competitive.
+	addTask("cheap1", 10, 8)
+	addTask("cheap2", 12, 9)
+	addTask("cheap3", 11, 7)
+	// Cluster B: expensive and thin.
+	addTask("expensive1", 500, 1)
+	addTask("expensive2", 520, 1)
+	addTask("expensive3", 480, 2)
+	return Network{Nodes: nodes, Edges: edges}
+}
+
+func TestComputeTaskSegmentsSeparatesTwoObviousClustersForK2(t *testing.T) {
+	n := fixtureTwoClusterNetwork()
+	resp, err := computeTaskSegments(n, 2)
+	if err != nil {
+		t.Fatalf("computeTaskSegments failed: %v", err)
+	}
+	if len(resp.Centroids) != 2 {
+		t.Fatalf("expected 2 centroids, got %d", len(resp.Centroids))
+	}
+
+	clusterOf := make(map[string]int)
+	for _, task := range resp.Tasks {
+		clusterOf[task.TaskID] = task.Cluster
+	}
+
+	cheapCluster := clusterOf["cheap1"]
+	for _, id := range []string{"cheap1", "cheap2", "cheap3"} {
+		if clusterOf[id] != cheapCluster {
+			t.Fatalf("expected all cheap-competitive tasks in the same cluster, got %v", clusterOf)
+		}
+	}
+	expensiveCluster := clusterOf["expensive1"]
+	if expensiveCluster == cheapCluster {
+		t.Fatalf("expected expensive-thin tasks in a different cluster from cheap-competitive ones")
+	}
+	for _, id := range []string{"expensive1", "expensive2", "expensive3"} {
+		if clusterOf[id] != expensiveCluster {
+			t.Fatalf("expected all expensive-thin tasks in the same cluster, got %v", clusterOf)
+		}
+	}
+}
+
+func TestComputeTaskSegmentsIsDeterministicAcrossRuns(t *testing.T) {
+	n := fixtureTwoClusterNetwork()
+	first, err := computeTaskSegments(n, 2)
+	if err != nil {
+		t.Fatalf("computeTaskSegments failed: %v", err)
+	}
+	second, err := computeTaskSegments(n, 2)
+	if err != nil {
+		t.Fatalf("computeTaskSegments failed: %v", err)
+	}
+	for i := range first.Tasks {
+		if first.Tasks[i].Cluster != second.Tasks[i].Cluster {
+			t.Fatalf("expected identical clustering across runs, got %v vs %v", first.Tasks, second.Tasks)
+		}
+	}
+}
+
+func TestComputeTaskSegmentsRejectsKOutOfRange(t *testing.T) {
+	n := fixtureTwoClusterNetwork()
+	if _, err := computeTaskSegments(n, 0); err == nil {
+		t.Fatalf("expected error for k=0")
+	}
+	if _, err := computeTaskSegments(n, len(taskFeaturesFor(n))+1); err == nil {
+		t.Fatalf("expected error for k greater than the number of tasks")
+	}
+}
+
+func TestHandleTaskSegmentsServesClustersAndCentroids(t *testing.T) {
+	mu.Lock()
+	network = fixtureTwoClusterNetwork()
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/task-segments?k=2", nil)
+	rec := httptest.NewRecorder()
+	handleTaskSegments(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var got taskSegmentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.K != 2 || len(got.Tasks) != 6 || len(got.Centroids) != 2 {
+		t.Fatalf("unexpected response shape: %+v", got)
+	}
+}
+
+func TestHandleTaskSegmentsReturns400ForInvalidK(t *testing.T) {
+	mu.Lock()
+	network = fixtureTwoClusterNetwork()
+	mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/task-segments?k=100", nil)
+	rec := httptest.NewRecorder()
+	handleTaskSegments(rec, req)
+	if rec.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for k greater than the number of tasks, got %d", rec.Result().StatusCode)
+	}
+}
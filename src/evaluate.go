@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+)
+
+// GroundTruth and Predicted both map a manager ID to the task IDs assigned to
+// it, matching the shape of ground_truth.json (src/truth.go) and the
+// agent_id -> Tasks assignment produced by swe_manager_task_distribution.go.
+type GroundTruth map[string][]string
+type Predicted map[string][]string
+
+// ScoreReport summarizes how well predicted assignments match ground truth,
+// both overall (the micro-averaged confusion counts, treating every
+// (manager, task) pair as one sample) and per manager, with macro
+// precision/recall/F1 - the unweighted mean of PerManager's scores - to
+// surface managers a micro average would drown out.
+type ScoreReport struct {
+	TruePositives  int            `json:"true_positives"`
+	FalsePositives int            `json:"false_positives"`
+	FalseNegatives int            `json:"false_negatives"`
+	Precision      float64        `json:"precision"`
+	Recall         float64        `json:"recall"`
+	F1             float64        `json:"f1"`
+	MacroPrecision float64        `json:"macro_precision"`
+	MacroRecall    float64        `json:"macro_recall"`
+	MacroF1        float64        `json:"macro_f1"`
+	PerManager     []ManagerScore `json:"per_manager"`
+}
+
+// ManagerScore is one manager's confusion counts and precision/recall/F1,
+// treating that manager's predicted and ground-truth task sets as the
+// positive class.
+type ManagerScore struct {
+	Manager        string  `json:"manager"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// TaskDetail records a single task's predicted manager(s), ground-truth
+// manager(s), and how the prediction classified, for -detail-out inspection.
+type TaskDetail struct {
+	TaskID           string   `json:"task_id"`
+	PredictedManager string   `json:"predicted_manager,omitempty"`
+	TruthManagers    []string `json:"truth_managers"`
+	Classification   string   `json:"classification"`
+}
+
+func invertToTaskManager(m map[string][]string) map[string]string {
+	taskManager := make(map[string]string)
+	for manager, tasks := range m {
+		for _, t := range tasks {
+			taskManager[t] = manager
+		}
+	}
+	return taskManager
+}
+
+// scoreByManager computes each manager's confusion counts and
+// precision/recall/F1 independently, comparing that manager's predicted
+// task set against its ground-truth task set. Every manager appearing in
+// either predicted or truth is included - one with no predictions has
+// recall 0 (or undefined, reported as 0) and no false positives, and vice
+// versa - so scoring never panics on a manager missing from either side.
+func scoreByManager(predicted Predicted, truth GroundTruth) []ManagerScore {
+	managers := make(map[string]bool, len(predicted)+len(truth))
+	for m := range predicted {
+		managers[m] = true
+	}
+	for m := range truth {
+		managers[m] = true
+	}
+
+	scores := make([]ManagerScore, 0, len(managers))
+	for m := range managers {
+		predictedSet := make(map[string]bool, len(predicted[m]))
+		for _, t := range predicted[m] {
+			predictedSet[t] = true
+		}
+		truthSet := make(map[string]bool, len(truth[m]))
+		for _, t := range truth[m] {
+			truthSet[t] = true
+		}
+
+		s := ManagerScore{Manager: m}
+		for t := range predictedSet {
+			if truthSet[t] {
+				s.TruePositives++
+			} else {
+				s.FalsePositives++
+			}
+		}
+		for t := range truthSet {
+			if !predictedSet[t] {
+				s.FalseNegatives++
+			}
+		}
+		if s.TruePositives+s.FalsePositives > 0 {
+			s.Precision = float64(s.TruePositives) / float64(s.TruePositives+s.FalsePositives)
+		}
+		if s.TruePositives+s.FalseNegatives > 0 {
+			s.Recall = float64(s.TruePositives) / float64(s.TruePositives+s.FalseNegatives)
+		}
+		if s.Precision+s.Recall > 0 {
+			s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+		}
+		scores = append(scores, s)
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].Manager < scores[j].Manager })
+	return scores
+}
+
+// macroAverage is the unweighted mean of scores' precision, recall, and F1;
+// 0 for all three when scores is empty.
+func macroAverage(scores []ManagerScore) (precision, recall, f1 float64) {
+	if len(scores) == 0 {
+		return 0, 0, 0
+	}
+	for _, s := range scores {
+		precision += s.Precision
+		recall += s.Recall
+		f1 += s.F1
+	}
+	n := float64(len(scores))
+	return precision / n, recall / n, f1 / n
+}
+
+// scoreAssignments computes micro precision/recall/F1 over (manager, task)
+// pairs (a pair counts as a true positive only when predicted and truth
+// agree on which manager owns the task), plus per-manager and macro
+// precision/recall/F1 via scoreByManager and macroAverage.
+func scoreAssignments(predicted Predicted, truth GroundTruth) ScoreReport {
+	predictedPairs := make(map[[2]string]bool)
+	for manager, tasks := range predicted {
+		for _, t := range tasks {
+			predictedPairs[[2]string{manager, t}] = true
+		}
+	}
+	truthPairs := make(map[[2]string]bool)
+	for manager, tasks := range truth {
+		for _, t := range tasks {
+			truthPairs[[2]string{manager, t}] = true
+		}
+	}
+
+	tp, fp, fn := 0, 0, 0
+	for pair := range predictedPairs {
+		if truthPairs[pair] {
+			tp++
+		} else {
+			fp++
+		}
+	}
+	for pair := range truthPairs {
+		if !predictedPairs[pair] {
+			fn++
+		}
+	}
+
+	report := ScoreReport{TruePositives: tp, FalsePositives: fp, FalseNegatives: fn}
+	if tp+fp > 0 {
+		report.Precision = float64(tp) / float64(tp+fp)
+	}
+	if tp+fn > 0 {
+		report.Recall = float64(tp) / float64(tp+fn)
+	}
+	if report.Precision+report.Recall > 0 {
+		report.F1 = 2 * report.Precision * report.Recall / (report.Precision + report.Recall)
+	}
+
+	report.PerManager = scoreByManager(predicted, truth)
+	report.MacroPrecision, report.MacroRecall, report.MacroF1 = macroAverage(report.PerManager)
+	return report
+}
+
+// taskDetails classifies every task seen in either predicted or truth as a
+// true positive, false positive, or false negative. Each task is assumed to
+// belong to at most one manager on each side; when predicted and truth both
+// name a manager but disagree, the task is reported as a false positive.
+func taskDetails(predicted Predicted, truth GroundTruth) []TaskDetail {
+	predictedByTask := invertToTaskManager(predicted)
+	truthByTask := make(map[string][]string)
+	for manager, tasks := range truth {
+		for _, t := range tasks {
+			truthByTask[t] = append(truthByTask[t], manager)
+		}
+	}
+
+	taskIDs := make(map[string]bool)
+	for t := range predictedByTask {
+		taskIDs[t] = true
+	}
+	for t := range truthByTask {
+		taskIDs[t] = true
+	}
+
+	details := make([]TaskDetail, 0, len(taskIDs))
+	for t := range taskIDs {
+		predictedManager := predictedByTask[t]
+		truthManagers := truthByTask[t]
+		sort.Strings(truthManagers)
+
+		classification := ""
+		switch {
+		case predictedManager == "" && len(truthManagers) > 0:
+			classification = "false_negative"
+		case predictedManager != "" && len(truthManagers) == 0:
+			classification = "false_positive"
+		case predictedManager != "" && containsString(truthManagers, predictedManager):
+			classification = "true_positive"
+		default:
+			classification = "false_positive"
+		}
+
+		details = append(details, TaskDetail{
+			TaskID:           t,
+			PredictedManager: predictedManager,
+			TruthManagers:    truthManagers,
+			Classification:   classification,
+		})
+	}
+	sort.Slice(details, func(i, j int) bool { return details[i].TaskID < details[j].TaskID })
+	return details
+}
+
+// writeJSONAtomic marshals v as indented JSON and writes it to path via a
+// temp-file-then-rename so a crash or write failure mid-write can't leave a
+// truncated file at path for a later tool to choke on: path either has its
+// old complete contents or its new complete contents, never a partial write.
+func writeJSONAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// lineAndColumn converts a byte offset into data into a 1-indexed line and
+// column, for reporting JSON errors with human-readable location context.
+func lineAndColumn(data []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// unmarshalJSONWithContext wraps json.Unmarshal errors with filename and, for
+// offset-carrying errors, line/column context, so callers don't have to
+// puzzle out a bare "invalid character ... at offset N".
+func unmarshalJSONWithContext(filename string, data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	line, col := lineAndColumn(data, offset)
+	return fmt.Errorf("%s:%d:%d: %w", filename, line, col, err)
+}
+
+func loadAssignments(path string) (map[string][]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string][]string
+	if err := unmarshalJSONWithContext(path, raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func evaluateMain() {
+	predictedPath := flag.String("predicted", "", "Path to predicted assignments JSON (manager -> task IDs)")
+	truthPath := flag.String("truth", "./data/ground_truth.json", "Path to ground truth JSON (manager -> task IDs)")
+	outPath := flag.String("out", "", "Path to write the aggregate score report JSON (default: stdout)")
+	detailOutPath := flag.String("detail-out", "", "Optional path to write per-task correctness detail JSON")
+	flag.Parse()
+
+	if *predictedPath == "" {
+		log.Fatal("[evaluate] -predicted is required")
+	}
+
+	predicted, err := loadAssignments(*predictedPath)
+	if err != nil {
+		log.Fatalf("[evaluate] Failed to load predicted assignments: %v", err)
+	}
+	truth, err := loadAssignments(*truthPath)
+	if err != nil {
+		log.Fatalf("[evaluate] Failed to load ground truth: %v", err)
+	}
+
+	report := scoreAssignments(predicted, truth)
+	if *outPath != "" {
+		if err := writeJSONAtomic(*outPath, report); err != nil {
+			log.Fatalf("[evaluate] Failed to write report: %v", err)
+		}
+		log.Println("[evaluate] Wrote score report to", *outPath)
+	} else {
+		reportJSON, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(reportJSON))
+	}
+
+	if *detailOutPath != "" {
+		details := taskDetails(predicted, truth)
+		if err := writeJSONAtomic(*detailOutPath, details); err != nil {
+			log.Fatalf("[evaluate] Failed to write detail report: %v", err)
+		}
+		log.Println("[evaluate] Wrote per-task detail to", *detailOutPath)
+	}
+}
+
+func main() {
+	evaluateMain()
+}
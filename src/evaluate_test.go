@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTaskDetailsClassification(t *testing.T) {
+	predicted := Predicted{
+		"alice": {"task1", "task2"},
+		"bob":   {"task3"},
+	}
+	truth := GroundTruth{
+		"alice": {"task1"},
+		"carol": {"task2", "task4"},
+	}
+
+	details := taskDetails(predicted, truth)
+	byTask := make(map[string]TaskDetail, len(details))
+	for _, d := range details {
+		byTask[d.TaskID] = d
+	}
+
+	if got := byTask["task1"].Classification; got != "true_positive" {
+		t.Errorf("task1: expected true_positive, got %s", got)
+	}
+	if got := byTask["task2"].Classification; got != "false_positive" {
+		t.Errorf("task2: expected false_positive (predicted alice, truth carol), got %s", got)
+	}
+	if got := byTask["task3"].Classification; got != "false_positive" {
+		t.Errorf("task3: expected false_positive (no truth manager), got %s", got)
+	}
+	if got := byTask["task4"].Classification; got != "false_negative" {
+		t.Errorf("task4: expected false_negative (no prediction), got %s", got)
+	}
+}
+
+func TestScoreAssignments(t *testing.T) {
+	predicted := Predicted{"alice": {"task1", "task2"}}
+	truth := GroundTruth{"alice": {"task1"}, "carol": {"task4"}}
+
+	report := scoreAssignments(predicted, truth)
+	if report.TruePositives != 1 || report.FalsePositives != 1 || report.FalseNegatives != 1 {
+		t.Fatalf("unexpected confusion counts: %+v", report)
+	}
+	if report.Precision != 0.5 {
+		t.Errorf("expected precision 0.5, got %v", report.Precision)
+	}
+	if report.Recall != 0.5 {
+		t.Errorf("expected recall 0.5, got %v", report.Recall)
+	}
+}
+
+func TestScoreByManagerComputesIndependentPerManagerScores(t *testing.T) {
+	predicted := Predicted{
+		"alice": {"task1", "task2"},
+		"bob":   {"task3"},
+	}
+	truth := GroundTruth{
+		"alice": {"task1"},
+		"carol": {"task4"},
+	}
+
+	scores := scoreByManager(predicted, truth)
+	byManager := make(map[string]ManagerScore, len(scores))
+	for _, s := range scores {
+		byManager[s.Manager] = s
+	}
+
+	if len(scores) != 3 {
+		t.Fatalf("expected 3 managers (alice, bob, carol), got %+v", scores)
+	}
+	alice := byManager["alice"]
+	if alice.TruePositives != 1 || alice.FalsePositives != 1 || alice.FalseNegatives != 0 || alice.Precision != 0.5 {
+		t.Errorf("alice: unexpected score: %+v", alice)
+	}
+	bob := byManager["bob"]
+	if bob.TruePositives != 0 || bob.FalsePositives != 1 || bob.Precision != 0 {
+		t.Errorf("bob: expected an unmatched false positive, got %+v", bob)
+	}
+	carol := byManager["carol"]
+	if carol.TruePositives != 0 || carol.FalseNegatives != 1 || carol.Recall != 0 {
+		t.Errorf("carol: expected an unmatched false negative (no predictions at all), got %+v", carol)
+	}
+}
+
+func TestScoreAssignmentsIncludesMacroAverageAcrossManagers(t *testing.T) {
+	predicted := Predicted{"alice": {"task1"}, "bob": {}}
+	truth := GroundTruth{"alice": {"task1"}, "bob": {"task2"}}
+
+	report := scoreAssignments(predicted, truth)
+	if len(report.PerManager) != 2 {
+		t.Fatalf("expected per-manager scores for alice and bob, got %+v", report.PerManager)
+	}
+	// alice: precision=1, recall=1, f1=1. bob: precision=0 (undefined, no
+	// predictions), recall=0, f1=0. Macro average of the two is 0.5.
+	if report.MacroPrecision != 0.5 || report.MacroRecall != 0.5 || report.MacroF1 != 0.5 {
+		t.Fatalf("expected macro precision/recall/f1 of 0.5, got %+v", report)
+	}
+}
+
+func TestScoreByManagerHandlesManagersOnOnlyOneSideWithoutPanicking(t *testing.T) {
+	predicted := Predicted{"alice": {"task1"}}
+	truth := GroundTruth{"bob": {"task2"}}
+
+	scores := scoreByManager(predicted, truth)
+	if len(scores) != 2 {
+		t.Fatalf("expected both alice (predicted-only) and bob (truth-only) reported, got %+v", scores)
+	}
+}
+
+func TestLoadAssignmentsReportsFileAndLineOnBrokenJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "assignments.json")
+	broken := "{\n  \"alice\": [\"task1\",\n}"
+	if err := os.WriteFile(path, []byte(broken), 0644); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+
+	_, err := loadAssignments(path)
+	if err == nil {
+		t.Fatal("expected an error for malformed assignments JSON, got nil")
+	}
+	if !strings.Contains(err.Error(), "assignments.json:") {
+		t.Fatalf("expected error to name the file, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), ":3:") {
+		t.Fatalf("expected error to name line 3, got: %v", err)
+	}
+}
+
+func TestWriteJSONAtomicWritesValidJSONAndLeavesNoTempFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := writeJSONAtomic(path, ScoreReport{Precision: 0.5}); err != nil {
+		t.Fatalf("writeJSONAtomic failed: %v", err)
+	}
+
+	var decoded ScoreReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read written file: %v", err)
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode written file: %v", err)
+	}
+	if decoded.Precision != 0.5 {
+		t.Fatalf("expected Precision 0.5, got %+v", decoded)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected no leftover .tmp file, got err=%v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// pipelineConfig collects the flags needed to drive the categorize,
+// build-network, and serve stages, so a new user runs one command instead
+// of hand-matching swe_manager_task_distribution.go/baseline_network.go/viz.go's
+// separate flag sets and file-path conventions.
+type pipelineConfig struct {
+	SrcDir    string
+	InputCSV  string
+	NumAgents int
+	NumIssues int
+	Addr      string
+	Serve     bool
+}
+
+// runStage runs `go run <script> <args...>` in dir as a blocking
+// subprocess, streaming its stdout/stderr to ours, and wraps any failure
+// with name so a pipeline failure names which stage broke. `go run` forks
+// a separate compiled binary as its child, so the subprocess runs in its
+// own process group and ctx cancellation kills the whole group - killing
+// just the `go run` wrapper would leave that child (e.g. a viz server)
+// running and holding our output pipes open.
+func runStage(ctx context.Context, dir, name, script string, args ...string) error {
+	cmdArgs := append([]string{"run", script}, args...)
+	cmd := exec.CommandContext(ctx, "go", cmdArgs...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = 5 * time.Second
+	log.Printf("[pipeline] Running %s stage: go %v (dir=%s)", name, cmdArgs, dir)
+	if err := cmd.Run(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%s stage failed: %w", name, err)
+	}
+	return nil
+}
+
+// runPipeline runs the categorize and build-network stages in sequence
+// inside workDir, which must contain a "data/data.csv" input matching
+// every stage's default file-path conventions. If cfg.Serve is set, it
+// then launches viz.go in the foreground, blocking for as long as the
+// server runs; runPipeline returns only on error or ctx cancellation.
+func runPipeline(ctx context.Context, workDir string, cfg pipelineConfig) error {
+	if err := runStage(ctx, workDir, "categorize", filepath.Join(cfg.SrcDir, "swe_manager_task_distribution.go"),
+		"-input", cfg.InputCSV, "-num_agents", strconv.Itoa(cfg.NumAgents), "-num_issues", strconv.Itoa(cfg.NumIssues)); err != nil {
+		return err
+	}
+	if err := runStage(ctx, workDir, "build-network", filepath.Join(cfg.SrcDir, "baseline_network.go")); err != nil {
+		return err
+	}
+	if !cfg.Serve {
+		return nil
+	}
+	return runStage(ctx, workDir, "serve", filepath.Join(cfg.SrcDir, "viz.go"), "-addr", cfg.Addr)
+}
+
+func main() {
+	srcDir := flag.String("src-dir", ".", "Directory containing swe_manager_task_distribution.go, baseline_network.go, and viz.go")
+	inputCSV := flag.String("input", "./data/data.csv", "Input CSV file for the categorize stage")
+	numAgents := flag.Int("num_agents", 10, "Number of agents to create in the categorize stage")
+	numIssues := flag.Int("num_issues", 10, "Number of issues/tasks to process in the categorize stage")
+	addr := flag.String("addr", ":8081", "Address for the viz server when -serve is set")
+	serve := flag.Bool("serve", true, "Launch the viz server once generation completes")
+	flag.Parse()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("[pipeline] Failed to resolve working directory: %v", err)
+	}
+	cfg := pipelineConfig{SrcDir: *srcDir, InputCSV: *inputCSV, NumAgents: *numAgents, NumIssues: *numIssues, Addr: *addr, Serve: *serve}
+	if err := runPipeline(context.Background(), wd, cfg); err != nil {
+		log.Fatalf("[pipeline] %v", err)
+	}
+}
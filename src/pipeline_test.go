@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// freePort asks the OS for an unused TCP port on 127.0.0.1, for handing to
+// a subprocess we can't otherwise coordinate a port with.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func writePipelineFixtureCSV(t *testing.T, workDir string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(workDir, "data"), 0755); err != nil {
+		t.Fatalf("failed to create data dir: %v", err)
+	}
+	path := filepath.Join(workDir, "data", "data.csv")
+	csv := "question_id,variant,price,price_limit,prompt\n" +
+		"t1,swe_manager,100,200,\"Fix the login button that never responds to clicks\"\n"
+	if err := os.WriteFile(path, []byte(csv), 0644); err != nil {
+		t.Fatalf("failed to write fixture CSV: %v", err)
+	}
+	return path
+}
+
+// TestRunPipelineCategorizeAndBuildNetworkProduceANetworkFile runs the
+// categorize and build-network stages (a single agent, so build-network
+// makes no LLM calls - there are no pairs to query) against a tiny CSV and
+// confirms a baseline_network.json lands where viz.go expects it by
+// default.
+func TestRunPipelineCategorizeAndBuildNetworkProduceANetworkFile(t *testing.T) {
+	workDir := t.TempDir()
+	writePipelineFixtureCSV(t, workDir)
+
+	srcDir, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatalf("failed to resolve src dir: %v", err)
+	}
+	cfg := pipelineConfig{SrcDir: srcDir, InputCSV: "./data/data.csv", NumAgents: 1, NumIssues: 1, Serve: false}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if err := runPipeline(ctx, workDir, cfg); err != nil {
+		t.Fatalf("runPipeline failed: %v", err)
+	}
+
+	networkPath := filepath.Join(workDir, "data", "baseline_network.json")
+	data, err := os.ReadFile(networkPath)
+	if err != nil {
+		t.Fatalf("expected build-network to produce %s: %v", networkPath, err)
+	}
+	var network struct {
+		Nodes []json.RawMessage `json:"nodes"`
+	}
+	if err := json.Unmarshal(data, &network); err != nil {
+		t.Fatalf("failed to decode generated network: %v", err)
+	}
+	if len(network.Nodes) != 1 {
+		t.Fatalf("expected exactly the one generated agent node, got %+v", network.Nodes)
+	}
+}
+
+// TestRunPipelineServeExposesMarketMetrics runs the full pipeline
+// including -serve, then confirms /api/market-metrics on the launched viz
+// server responds successfully.
+func TestRunPipelineServeExposesMarketMetrics(t *testing.T) {
+	workDir := t.TempDir()
+	writePipelineFixtureCSV(t, workDir)
+
+	srcDir, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatalf("failed to resolve src dir: %v", err)
+	}
+	port := freePort(t)
+	cfg := pipelineConfig{
+		SrcDir: srcDir, InputCSV: "./data/data.csv",
+		NumAgents: 1, NumIssues: 1,
+		Serve: true, Addr: fmt.Sprintf("127.0.0.1:%d", port),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- runPipeline(ctx, workDir, cfg) }()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/market-metrics", port)
+	deadline := time.Now().Add(30 * time.Second)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(url)
+		if err == nil {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected 200 from %s, got %d", url, resp.StatusCode)
+			}
+			cancel()
+			<-done
+			return
+		}
+		lastErr = err
+		time.Sleep(200 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	t.Fatalf("viz server never became reachable at %s: %v", url, lastErr)
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -13,8 +15,10 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -35,11 +39,42 @@ type TaskBrief struct {
 
 type AgentSchema struct {
 	AgentID      string       `json:"agent_id"`
+	DisplayName  string       `json:"display_name"`
 	Specialities []Speciality `json:"specialities"`
 	Tasks        []TaskBrief  `json:"tasks"`
 }
 
+// verbose logs a per-task progress line via log.Println unless quiet
+// suppresses it; startup config, errors, and the final summary always log.
+func verbose(quiet bool, args ...interface{}) {
+	if quiet {
+		return
+	}
+	log.Println(args...)
+}
+
 // Call Ollama API to classify a task description into a specialty name
+// maxResponseBytes caps how much of an LLM response body classifySpecialtyOllama
+// will buffer, set from -max-response-bytes in main. A misbehaving model
+// returning more than this is treated as a classification failure so it
+// flows into the caller's existing retry/fallback logic instead of being
+// buffered in full.
+var maxResponseBytes int64 = 1 << 20 // 1MB
+
+// readLimitedBody reads up to maxBytes+1 bytes from body so callers can
+// detect truncation: a returned slice longer than maxBytes means the true
+// response exceeded the cap and was cut short.
+func readLimitedBody(body io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("response body exceeded the %d byte limit", maxBytes)
+	}
+	return data, nil
+}
+
 func classifySpecialtyOllama(prompt, ollamaURL, model string, specialties []Speciality) (string, error) {
 	type OllamaRequest struct {
 		Model  string `json:"model"`
@@ -70,9 +105,15 @@ func classifySpecialtyOllama(prompt, ollamaURL, model string, specialties []Spec
 	}
 	defer resp.Body.Close()
 
+	body, err := readLimitedBody(resp.Body, maxResponseBytes)
+	if err != nil {
+		log.Println("[Ollama] Response body exceeded limit:", err)
+		return "", err
+	}
+
 	// Read streaming response until end
 	full := ""
-	dec := json.NewDecoder(resp.Body)
+	dec := json.NewDecoder(bytes.NewReader(body))
 	for {
 		var msg OllamaResponse
 		if err := dec.Decode(&msg); err == io.EOF {
@@ -103,6 +144,457 @@ func classifySpecialtyOllama(prompt, ollamaURL, model string, specialties []Spec
 	return "Unknown", nil
 }
 
+// llmCacheEntry is the on-disk envelope for a cached LLM classification,
+// keyed by promptHash so a cache hit can be sanity-checked against the
+// model that produced it.
+type llmCacheEntry struct {
+	Model      string    `json:"model"`
+	PromptHash string    `json:"prompt_hash"`
+	Response   string    `json:"response"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// llmCacheKey hashes model+prompt with SHA-256 so the same (model, prompt)
+// pair always maps to the same filename, regardless of machine or run.
+func llmCacheKey(model, prompt string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// llmCacheFilePath returns the on-disk path for key under cacheDir, sharded
+// into a subdirectory by the first two hex characters so the cache doesn't
+// accumulate one huge flat directory of files.
+func llmCacheFilePath(cacheDir, key string) string {
+	return filepath.Join(cacheDir, key[:2], key+".json")
+}
+
+// readLLMCache returns the cached response for (model, prompt) under
+// cacheDir, if present and no older than maxAge (maxAge<=0 means entries
+// never expire). A missing, corrupt, or stale entry is treated as a cache
+// miss rather than an error.
+func readLLMCache(cacheDir, model, prompt string, maxAge time.Duration) (string, bool) {
+	if cacheDir == "" {
+		return "", false
+	}
+	key := llmCacheKey(model, prompt)
+	data, err := os.ReadFile(llmCacheFilePath(cacheDir, key))
+	if err != nil {
+		return "", false
+	}
+	var entry llmCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", false
+	}
+	if maxAge > 0 && time.Since(entry.Timestamp) > maxAge {
+		return "", false
+	}
+	return entry.Response, true
+}
+
+// writeLLMCache stores response for (model, prompt) under cacheDir. Failures
+// are non-fatal: caching is a performance optimization, not a correctness
+// requirement.
+func writeLLMCache(cacheDir, model, prompt, response string) error {
+	if cacheDir == "" {
+		return nil
+	}
+	key := llmCacheKey(model, prompt)
+	path := llmCacheFilePath(cacheDir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache shard directory for %s: %w", path, err)
+	}
+	entry := llmCacheEntry{
+		Model:      model,
+		PromptHash: key,
+		Response:   response,
+		Timestamp:  time.Now(),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshaling cache entry for %s: %w", path, err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// classifySpecialtyOllamaCached wraps classifySpecialtyOllama with an
+// on-disk, content-addressed cache: identical (model, prompt) pairs skip
+// the network round-trip entirely. Caching is skipped when cacheDir is "".
+func classifySpecialtyOllamaCached(prompt, ollamaURL, model string, specialties []Speciality, cacheDir string, maxAge time.Duration) (string, error) {
+	if cached, ok := readLLMCache(cacheDir, model, prompt, maxAge); ok {
+		return cached, nil
+	}
+	result, err := classifySpecialtyOllama(prompt, ollamaURL, model, specialties)
+	if err != nil {
+		return result, err
+	}
+	if err := writeLLMCache(cacheDir, model, prompt, result); err != nil {
+		log.Println("[LLMCache] Failed to write cache entry:", err)
+	}
+	return result, nil
+}
+
+// classifySpecialtyOpenAI classifies job.desc via an OpenAI-compatible
+// chat/completions endpoint (vLLM, LM Studio, OpenAI itself), sending the
+// prompt as a single user message and matching the result against the
+// specialty names the same way classifySpecialtyOllama does.
+func classifySpecialtyOpenAI(prompt, apiURL, apiKey, model string, specialties []Speciality) (string, error) {
+	type chatMessage struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	type chatRequest struct {
+		Model    string        `json:"model"`
+		Messages []chatMessage `json:"messages"`
+	}
+	type chatResponse struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+
+	promptText := prompt + "\n\nAvailable specialties:\n"
+	for _, s := range specialties {
+		promptText += fmt.Sprintf(" - %s: %s\n", s.Name, s.Description)
+	}
+	promptText += "\nReturn only the best matching specialty name from the above list. If none fit, pick the closest one."
+
+	reqBody := chatRequest{
+		Model:    model,
+		Messages: []chatMessage{{Role: "user", Content: promptText}},
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+	log.Println("[OpenAI] Calling chat/completions model:", model)
+
+	req, err := http.NewRequest("POST", apiURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Println("[OpenAI] HTTP POST failed:", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := readLimitedBody(resp.Body, maxResponseBytes)
+	if err != nil {
+		log.Println("[OpenAI] Response body exceeded limit:", err)
+		return "", err
+	}
+
+	var result chatResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		log.Println("[OpenAI] JSON decode failed:", err)
+		return "", err
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("openai response contained no choices")
+	}
+
+	full := cleanString(result.Choices[0].Message.Content)
+	log.Println("[OpenAI] Model returned:", full)
+	for _, s := range specialties {
+		if eqIgnoreCase(full, s.Name) {
+			return s.Name, nil
+		}
+	}
+	// Fallback: fuzzy match
+	for _, s := range specialties {
+		if containsIgnoreCase(full, s.Name) {
+			return s.Name, nil
+		}
+	}
+	return "Unknown", nil
+}
+
+// classifySpecialtyOpenAICached wraps classifySpecialtyOpenAI with the same
+// on-disk, content-addressed cache classifySpecialtyOllamaCached uses.
+func classifySpecialtyOpenAICached(prompt, apiURL, apiKey, model string, specialties []Speciality, cacheDir string, maxAge time.Duration) (string, error) {
+	if cached, ok := readLLMCache(cacheDir, model, prompt, maxAge); ok {
+		return cached, nil
+	}
+	result, err := classifySpecialtyOpenAI(prompt, apiURL, apiKey, model, specialties)
+	if err != nil {
+		return result, err
+	}
+	if err := writeLLMCache(cacheDir, model, prompt, result); err != nil {
+		log.Println("[LLMCache] Failed to write cache entry:", err)
+	}
+	return result, nil
+}
+
+// ollamaBaseURL strips the /api/... path off an Ollama API URL, leaving the
+// server root to probe for reachability.
+func ollamaBaseURL(apiURL string) string {
+	if i := strings.Index(apiURL, "/api/"); i != -1 {
+		return apiURL[:i] + "/"
+	}
+	return apiURL
+}
+
+// probeOllama reports whether the Ollama server at baseURL responds at all
+// within timeout. Any HTTP response counts as reachable; only a connection
+// failure (server down) counts as unreachable.
+func probeOllama(baseURL string, timeout time.Duration) bool {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(baseURL)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
+
+// classifySpecialtyKeyword picks the specialty whose description and example
+// share the most significant words with desc, used when Ollama is
+// unreachable. Falls back to the first specialty if nothing matches.
+func classifySpecialtyKeyword(desc string, specialties []Speciality) string {
+	lower := strings.ToLower(desc)
+	best, bestScore := specialties[0].Name, -1
+	for _, s := range specialties {
+		score := 0
+		for _, word := range strings.Fields(strings.ToLower(s.Description + " " + s.Example)) {
+			word = strings.Trim(word, ".,'\"")
+			if len(word) <= 3 {
+				continue
+			}
+			if strings.Contains(lower, word) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = s.Name, score
+		}
+	}
+	return best
+}
+
+// jaccardSimilarity returns the Jaccard index |intersection| / |union|
+// between two category sets. Two empty sets are considered fully agreeing.
+func jaccardSimilarity(a, b []string) float64 {
+	setA := make(map[string]bool, len(a))
+	for _, c := range a {
+		setA[c] = true
+	}
+	setB := make(map[string]bool, len(b))
+	for _, c := range b {
+		setB[c] = true
+	}
+	if len(setA) == 0 && len(setB) == 0 {
+		return 1
+	}
+
+	union := make(map[string]bool, len(setA)+len(setB))
+	intersection := 0
+	for c := range setA {
+		union[c] = true
+		if setB[c] {
+			intersection++
+		}
+	}
+	for c := range setB {
+		union[c] = true
+	}
+	return float64(intersection) / float64(len(union))
+}
+
+// CategorizationComparison reports how classifySpecialtyOllama (LLM) and
+// classifySpecialtyKeyword (keyword) categorized a single task, and how much
+// they agree.
+type CategorizationComparison struct {
+	TaskID            string   `json:"task_id"`
+	LLMCategories     []string `json:"llm_categories"`
+	KeywordCategories []string `json:"keyword_categories"`
+	Agreement         float64  `json:"agreement"`
+}
+
+// compareCategorizationMethods reports how classifySpecialtyOllama and
+// classifySpecialtyKeyword categorize the same task description, quantifying
+// their agreement as a Jaccard index over category sets. Both methods
+// currently return a single category, so the sets compared here are
+// singletons, but the comparison generalizes if either grows to return more
+// than one.
+func compareCategorizationMethods(taskID, llmCategory, keywordCategory string) CategorizationComparison {
+	var llmCats, keywordCats []string
+	if llmCategory != "" {
+		llmCats = []string{llmCategory}
+	}
+	if keywordCategory != "" {
+		keywordCats = []string{keywordCategory}
+	}
+	return CategorizationComparison{
+		TaskID:            taskID,
+		LLMCategories:     llmCats,
+		KeywordCategories: keywordCats,
+		Agreement:         jaccardSimilarity(llmCats, keywordCats),
+	}
+}
+
+// categorizationJob is one task queued for classification: everything
+// classifyTask needs, kept together so a worker pool result slot maps 1:1 to
+// a task regardless of which order goroutines finish in.
+type categorizationJob struct {
+	taskID     string
+	desc       string
+	price      float64
+	priceLimit float64
+}
+
+// categorizationResult is what classifyTask produces for one job: the
+// specialty it was assigned, and, when a categorization report was
+// requested, a comparison against keyword-only classification.
+type categorizationResult struct {
+	specialty  string
+	comparison *CategorizationComparison
+}
+
+// categorizationConfig bundles the flags and shared inputs classifyTask
+// needs, since it's called from a worker pool where each goroutine only
+// touches its own job.
+type categorizationConfig struct {
+	llmAvailable   bool
+	quiet          bool
+	llmRetries     int
+	backend        string
+	ollamaURL      string
+	openaiURL      string
+	openaiAPIKey   string
+	model          string
+	specialities   []Speciality
+	cacheDir       string
+	cacheMaxAge    time.Duration
+	wantComparison bool
+}
+
+// classifySpecialty dispatches to the LLM backend cfg.backend selects
+// ("ollama" or "openai"), so classifyTask's retry loop stays backend-agnostic.
+func classifySpecialty(prompt string, cfg categorizationConfig) (string, error) {
+	if cfg.backend == "openai" {
+		return classifySpecialtyOpenAICached(prompt, cfg.openaiURL, cfg.openaiAPIKey, cfg.model, cfg.specialities, cfg.cacheDir, cfg.cacheMaxAge)
+	}
+	return classifySpecialtyOllamaCached(prompt, cfg.ollamaURL, cfg.model, cfg.specialities, cfg.cacheDir, cfg.cacheMaxAge)
+}
+
+// classifyTask assigns job's specialty, either via keyword-only
+// classification when the LLM is unavailable, or via
+// classifySpecialtyOllamaCached with up to cfg.llmRetries attempts, falling
+// back to a random specialty on repeated failure. It touches no shared state
+// besides log output (goroutine-safe) and cfg.cacheDir (a filesystem cache
+// keyed by a content hash, safe for concurrent access), so it's safe to call
+// from multiple goroutines at once.
+func classifyTask(job categorizationJob, cfg categorizationConfig) categorizationResult {
+	if !cfg.llmAvailable {
+		specialty := classifySpecialtyKeyword(job.desc, cfg.specialities)
+		verbose(cfg.quiet, "[Main] Keyword-classified task", job.taskID, ":", specialty)
+		return categorizationResult{specialty: specialty}
+	}
+
+	var (
+		specialty   string
+		classifyErr error
+	)
+	for attempt := 0; attempt < cfg.llmRetries; attempt++ {
+		verbose(cfg.quiet, "[Main] Calling LLM for task", job.taskID, "(attempt", attempt+1, ")")
+		specialty, classifyErr = classifySpecialty(
+			fmt.Sprintf("Classify this software issue description into a specialty:\n%s", job.desc), cfg)
+		if classifyErr == nil && specialty != "" && specialty != "Unknown" {
+			break
+		}
+		verbose(cfg.quiet, "[Main] LLM failed or returned unknown specialty, retrying...")
+	}
+	if classifyErr != nil || specialty == "" || specialty == "Unknown" {
+		specialty = cfg.specialities[rand.Intn(len(cfg.specialities))].Name // fallback
+		verbose(cfg.quiet, "[WARN] LLM could not classify task", job.taskID, ", fallback to random specialty:", specialty)
+	} else {
+		verbose(cfg.quiet, "[Main] LLM assigned specialty for task", job.taskID, ":", specialty)
+	}
+
+	result := categorizationResult{specialty: specialty}
+	if cfg.wantComparison {
+		keywordSpecialty := classifySpecialtyKeyword(job.desc, cfg.specialities)
+		comparison := compareCategorizationMethods(job.taskID, specialty, keywordSpecialty)
+		result.comparison = &comparison
+	}
+	return result
+}
+
+// classifyTasksConcurrently runs classifyTask over jobs using up to workers
+// concurrent goroutines (workers < 1 is treated as 1, i.e. sequential),
+// returning results in the same order as jobs regardless of completion
+// order. Callers should keep workers modest to avoid overwhelming a local
+// Ollama instance.
+func classifyTasksConcurrently(jobs []categorizationJob, workers int, cfg categorizationConfig) []categorizationResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]categorizationResult, len(jobs))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, job categorizationJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = classifyTask(job, cfg)
+		}(i, job)
+	}
+	wg.Wait()
+	return results
+}
+
+// dominantSpeciality returns the name of the highest-weighted speciality in
+// specs, or "" if specs is empty.
+func dominantSpeciality(specs []Speciality) string {
+	if len(specs) == 0 {
+		return ""
+	}
+	best := specs[0]
+	for _, s := range specs[1:] {
+		if s.Weight > best.Weight {
+			best = s
+		}
+	}
+	return best.Name
+}
+
+// agentDisplayName names an agent for downstream consumers (baseline_network.go,
+// viz.go) that render Node.Label. With repeatableNames it always returns
+// "Agent N", stable across regenerations regardless of which specialities
+// randomSubsetSpecialitiesWithWeights happens to assign. Otherwise it names
+// the agent after its dominant speciality, which can change between runs as
+// speciality assignment is randomized.
+func agentDisplayName(index int, specs []Speciality, repeatableNames bool) string {
+	if repeatableNames {
+		return fmt.Sprintf("Agent %d", index)
+	}
+	if dominant := dominantSpeciality(specs); dominant != "" {
+		return fmt.Sprintf("%s Specialist #%d", dominant, index)
+	}
+	return fmt.Sprintf("Agent %d", index)
+}
+
+// writeJSONAtomic marshals v as indented JSON and writes it to path via a
+// temp-file-then-rename so a crash or write failure mid-write can't leave a
+// truncated file at path for a later tool to choke on: path either has its
+// old complete contents or its new complete contents, never a partial write.
+func writeJSONAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 func cleanString(s string) string {
 	s = strings.TrimSpace(s)
 	s = strings.Trim(s, "\"'` \n\t")
@@ -160,18 +652,285 @@ func mathRound(x float64, places int) float64 {
 	return float64(int(x*pow+0.5)) / pow
 }
 
+// specialityKey identifies an agent's specialty set independent of order or
+// weight, so agents that specialize identically can be detected for merging.
+func specialityKey(specs []Speciality) string {
+	names := make([]string, len(specs))
+	for i, s := range specs {
+		names[i] = s.Name
+	}
+	sort.Strings(names)
+	return strings.Join(names, "|")
+}
+
+// mergeTaskBriefs combines two agents' task lists, dropping duplicate task IDs.
+func mergeTaskBriefs(a, b []TaskBrief) []TaskBrief {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]TaskBrief, 0, len(a)+len(b))
+	for _, t := range append(append([]TaskBrief{}, a...), b...) {
+		if seen[t.ID] {
+			continue
+		}
+		seen[t.ID] = true
+		merged = append(merged, t)
+	}
+	return merged
+}
+
+// mergeIdenticalAgents combines agents that declare the same specialty set
+// (ignoring weight and order) into one, reassigning their tasks onto the
+// first agent encountered with that set.
+func mergeIdenticalAgents(agents []*AgentSchema) []*AgentSchema {
+	byKey := make(map[string]*AgentSchema)
+	order := make([]string, 0, len(agents))
+	for _, a := range agents {
+		key := specialityKey(a.Specialities)
+		if existing, ok := byKey[key]; ok {
+			existing.Tasks = mergeTaskBriefs(existing.Tasks, a.Tasks)
+			continue
+		}
+		byKey[key] = a
+		order = append(order, key)
+	}
+	merged := make([]*AgentSchema, 0, len(order))
+	for _, key := range order {
+		merged = append(merged, byKey[key])
+	}
+	return merged
+}
+
+// openInput returns a reader over path, which may be a local file path or an
+// http(s):// URL. Remote sources are fetched with timeout as the client
+// timeout; a non-200 response is reported as an error.
+// baseOutsourceRate is the flat probability that a task is additionally
+// outsourced to a second agent when price-weighted outsourcing is disabled.
+const baseOutsourceRate = 0.15
+
+// pricePercentile returns the fraction of prices strictly below price, i.e.
+// price's percentile rank in the distribution. Returns 0 for an empty
+// distribution.
+func pricePercentile(prices []float64, price float64) float64 {
+	if len(prices) == 0 {
+		return 0
+	}
+	below := 0
+	for _, p := range prices {
+		if p < price {
+			below++
+		}
+	}
+	return float64(below) / float64(len(prices))
+}
+
+// outsourceProbability returns the probability that a task is additionally
+// outsourced to a second agent. With priceWeighted disabled this is always
+// the flat baseOutsourceRate; enabled, it scales from that baseline up
+// toward 1 as the task's price percentile rises, so high-value tasks draw a
+// wider pool of bidders.
+func outsourceProbability(priceWeighted bool, percentile float64) float64 {
+	if !priceWeighted {
+		return baseOutsourceRate
+	}
+	return baseOutsourceRate + (1-baseOutsourceRate)*percentile
+}
+
+func openInput(path string, timeout time.Duration) (io.ReadCloser, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return os.Open(path)
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(path)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", path, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// configurableFlagNames lists the -config-loadable flag names for this
+// program, i.e. every flag except -config itself. -summary-only and
+// -output-dir are omitted since they are only aliases for -quiet and
+// -output respectively.
+var configurableFlagNames = []string{
+	"input", "output", "model", "ollama_url", "num_issues", "num_agents",
+	"llm_retries", "min-desc-len", "input-timeout", "merge-identical-agents",
+	"price-weighted-outsource", "llm-required", "ollama-health-timeout",
+	"repeatable-names", "categorization-report", "max-response-bytes", "quiet",
+	"cache-dir", "cache-max-age", "no-cache", "since-question-id", "since-row",
+	"workers", "seed", "backend", "openai_url", "openai-api-key-env",
+}
+
+// applyConfigFile reads a JSON object at path mapping flag names (as they
+// appear on the command line, without the leading "-") to values, and
+// applies them via flag.Set to any flag in configurableFlagNames that was
+// not already set explicitly on the command line - so an explicit
+// command-line flag always overrides the config file, regardless of the
+// order the two are given in. Only JSON is supported: no YAML library is
+// vendored in this module.
+func applyConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file %s: %w", path, err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for _, name := range configurableFlagNames {
+		value, ok := raw[name]
+		if !ok || explicit[name] {
+			continue
+		}
+		if err := flag.Set(name, fmt.Sprintf("%v", value)); err != nil {
+			return fmt.Errorf("applying config value for %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resumeMarkerScanner decides, one data row at a time as the CSV is streamed,
+// whether that row falls before the incremental-regeneration resume point:
+// -since-row skips that many rows outright, -since-question-id resumes just
+// after the row with that question_id. This assumes data.csv is only ever
+// appended to - never reordered or had rows removed - so a question_id's
+// position in the file stays stable across runs and marks a safe resume
+// point for the next run's tail. The two flags are mutually exclusive.
+//
+// Rows must be fed to Skip in file order; the scanner keeps only a running
+// row count and whether the marker has been seen, so a caller can decide
+// resume the CSV reader row-by-row instead of buffering the whole file to
+// look up the marker's position up front.
+type resumeMarkerScanner struct {
+	sinceQuestionID string
+	questionIDCol   int
+	sinceRow        int
+	rowIndex        int
+	markerFound     bool
+}
+
+// newResumeMarkerScanner validates the -since-question-id/-since-row flags
+// and returns a scanner ready to receive rows via Skip.
+func newResumeMarkerScanner(questionIDCol int, sinceQuestionID string, sinceRow int) (*resumeMarkerScanner, error) {
+	if sinceQuestionID != "" && sinceRow > 0 {
+		return nil, fmt.Errorf("-since-question-id and -since-row are mutually exclusive")
+	}
+	if sinceQuestionID != "" && questionIDCol == -1 {
+		return nil, fmt.Errorf("-since-question-id requires a question_id column in the input CSV")
+	}
+	return &resumeMarkerScanner{
+		sinceQuestionID: sinceQuestionID,
+		questionIDCol:   questionIDCol,
+		sinceRow:        sinceRow,
+		markerFound:     sinceQuestionID == "",
+	}, nil
+}
+
+// Skip reports whether record falls at or before the resume point and should
+// be excluded from processing. Rows must be passed in file order.
+func (s *resumeMarkerScanner) Skip(record []string) bool {
+	defer func() { s.rowIndex++ }()
+	if s.sinceQuestionID != "" {
+		if s.markerFound {
+			return false
+		}
+		if record[s.questionIDCol] == s.sinceQuestionID {
+			s.markerFound = true
+		}
+		return true
+	}
+	return s.rowIndex < s.sinceRow
+}
+
+// Done reports an error if -since-question-id was set but its marker was
+// never seen across every row passed to Skip.
+func (s *resumeMarkerScanner) Done() error {
+	if s.sinceQuestionID != "" && !s.markerFound {
+		return fmt.Errorf("-since-question-id %q not found in the input CSV", s.sinceQuestionID)
+	}
+	return nil
+}
+
 func main() {
-	rand.Seed(time.Now().UnixNano())
+	seed := flag.Int64("seed", time.Now().UnixNano(), "Random seed for agent specialty/weight generation and task assignment; defaults to a time-based seed so it varies per run unless set explicitly")
 
 	inputFile := flag.String("input", "./data/data.csv", "Input CSV file")
-	outputDir := flag.String("output", "./data/agents", "Output directory for agent JSON files")
+	var outputDirValue string
+	outputDir := &outputDirValue
+	flag.StringVar(outputDir, "output", "./data/agents", "Output directory for agent JSON files")
+	flag.StringVar(outputDir, "output-dir", "./data/agents", "Alias for -output")
 	model := flag.String("model", "cogito:14b", "LLM model name")
 	ollamaURL := flag.String("ollama_url", "http://localhost:11434/api/generate", "Ollama API URL")
+	backend := flag.String("backend", "ollama", "LLM backend to classify tasks against: \"ollama\" or \"openai\" (any OpenAI-compatible chat/completions endpoint - vLLM, LM Studio, OpenAI itself)")
+	openaiURL := flag.String("openai_url", "https://api.openai.com/v1/chat/completions", "OpenAI-compatible chat/completions endpoint, used when -backend=openai")
+	openaiAPIKeyEnv := flag.String("openai-api-key-env", "OPENAI_API_KEY", "Environment variable to read the API key from when -backend=openai")
 	numIssues := flag.Int("num_issues", 10, "Number of issues/tasks to process")
 	numAgents := flag.Int("num_agents", 10, "Number of agents to create")
 	llmRetries := flag.Int("llm_retries", 3, "Number of LLM call retries per task")
+	minDescLen := flag.Int("min-desc-len", 0, "Skip tasks whose cleaned description is shorter than this many characters")
+	inputTimeout := flag.Duration("input-timeout", 30*time.Second, "Timeout for fetching -input when it is an http(s):// URL")
+	mergeIdenticalAgentsFlag := flag.Bool("merge-identical-agents", false, "Merge agents with identical specialty sets, combining their tasks")
+	priceWeightedOutsource := flag.Bool("price-weighted-outsource", false, "Scale the probability of outsourcing a task to a second agent with its price percentile, instead of a flat 15%")
+	llmRequired := flag.Bool("llm-required", false, "Abort at startup if the Ollama server is unreachable, instead of falling back to keyword-only categorization")
+	ollamaHealthTimeout := flag.Duration("ollama-health-timeout", 2*time.Second, "Timeout for the startup Ollama reachability probe")
+	repeatableNames := flag.Bool("repeatable-names", false, "Use a stable \"Agent N\" display name instead of one derived from the agent's dominant speciality, so names stay identical across regenerations")
+	categorizationReportPath := flag.String("categorization-report", "", "Optional path to write a per-task comparison of LLM-assigned vs keyword-assigned specialty, with a Jaccard agreement score")
+	maxRespBytes := flag.Int64("max-response-bytes", maxResponseBytes, "Maximum bytes of an LLM response body to buffer before treating it as a classification failure")
+	cacheDir := flag.String("cache-dir", "", "Directory to cache LLM classifications in, keyed by a SHA-256 of (model, prompt); empty disables caching")
+	cacheMaxAge := flag.Duration("cache-max-age", 0, "Maximum age of a -cache-dir entry before it's treated as a miss; 0 means entries never expire")
+	noCache := flag.Bool("no-cache", false, "Bypass -cache-dir even if set, forcing every task through the LLM")
+	sinceQuestionID := flag.String("since-question-id", "", "Resume processing just after this question_id, skipping every row up to and including it; assumes data.csv is append-only. Mutually exclusive with -since-row")
+	sinceRow := flag.Int("since-row", 0, "Skip this many data rows (excluding the header) before processing; assumes data.csv is append-only. Mutually exclusive with -since-question-id")
+	workers := flag.Int("workers", 1, "Number of tasks to classify concurrently via Ollama; 1 means sequential. Keep this modest to avoid overwhelming a local Ollama instance")
+	configPath := flag.String("config", "", "Path to a JSON config file mapping flag names to values; explicit command-line flags override it")
+	var quiet bool
+	flag.BoolVar(&quiet, "quiet", false, "Suppress per-task log lines, printing only startup config and the final summary")
+	flag.BoolVar(&quiet, "summary-only", false, "Alias for -quiet")
 	flag.Parse()
 
+	if *configPath != "" {
+		if err := applyConfigFile(*configPath); err != nil {
+			log.Fatalf("[Main] Failed to apply -config: %v", err)
+		}
+	}
+	maxResponseBytes = *maxRespBytes
+	rand.Seed(*seed)
+
+	if *backend != "ollama" && *backend != "openai" {
+		log.Fatalf("[Main] Invalid -backend %q: must be \"ollama\" or \"openai\"", *backend)
+	}
+	openaiAPIKey := ""
+	if *backend == "openai" {
+		openaiAPIKey = os.Getenv(*openaiAPIKeyEnv)
+		if openaiAPIKey == "" {
+			log.Println("[WARN] -backend=openai but", *openaiAPIKeyEnv, "is empty; requests will be sent without an Authorization header")
+		}
+	}
+
+	log.Println("[Main] Starting with input:", *inputFile, "output:", *outputDir, "num_agents:", *numAgents, "num_issues:", *numIssues, "quiet:", quiet, "config:", *configPath, "cache-dir:", *cacheDir, "cache-max-age:", *cacheMaxAge, "no-cache:", *noCache, "since-question-id:", *sinceQuestionID, "since-row:", *sinceRow, "seed:", *seed, "backend:", *backend)
+
+	var llmAvailable bool
+	if *backend == "openai" {
+		llmAvailable = openaiAPIKey != ""
+		if !llmAvailable && *llmRequired {
+			log.Fatalf("[Main] -backend=openai but %s is empty and -llm-required is set", *openaiAPIKeyEnv)
+		}
+	} else {
+		llmAvailable = probeOllama(ollamaBaseURL(*ollamaURL), *ollamaHealthTimeout)
+		if !llmAvailable && *llmRequired {
+			log.Fatalf("[Main] Ollama unreachable at %s and -llm-required is set", *ollamaURL)
+		}
+	}
+	if !llmAvailable {
+		log.Println("[WARN] LLM backend unavailable - falling back to keyword-only categorization for this run")
+	}
+
 	// Define specialties using provided descriptions and examples
 	specialities := []Speciality{
 		{
@@ -213,29 +972,30 @@ func main() {
 
 	agents := make([]*AgentSchema, *numAgents)
 	for i := 0; i < *numAgents; i++ {
+		agentSpecialities := randomSubsetSpecialitiesWithWeights(specialities)
 		agents[i] = &AgentSchema{
 			AgentID:      fmt.Sprintf("agent%d", i+1),
-			Specialities: randomSubsetSpecialitiesWithWeights(specialities),
+			DisplayName:  agentDisplayName(i+1, agentSpecialities, *repeatableNames),
+			Specialities: agentSpecialities,
 			Tasks:        []TaskBrief{},
 		}
 	}
 	log.Println("[Main] Generated", *numAgents, "agents with random specialties and weights.")
 
-	csvFile, err := os.Open(*inputFile)
+	csvFile, err := openInput(*inputFile, *inputTimeout)
 	if err != nil {
 		log.Fatalf("[Main] Failed to open CSV: %v", err)
 	}
 	defer csvFile.Close()
 
 	reader := csv.NewReader(csvFile)
-	records, err := reader.ReadAll()
+	header, err := reader.Read()
 	if err != nil {
-		log.Fatalf("[Main] Failed to read CSV: %v", err)
-	}
-	if len(records) < 1 {
-		log.Fatal("[Main] Input CSV is empty")
+		if err == io.EOF {
+			log.Fatal("[Main] Input CSV is empty")
+		}
+		log.Fatalf("[Main] Failed to read CSV header: %v", err)
 	}
-	header := records[0]
 	col := func(name string) int {
 		for i, h := range header {
 			if h == name {
@@ -244,59 +1004,168 @@ func main() {
 		}
 		return -1
 	}
+	priceCol, priceLimitCol, questionIDCol, promptCol := col("price"), col("price_limit"), col("question_id"), col("prompt")
 
-	// Assign each task to a RANDOM agent, use Ollama to assign specialty
-	for i, record := range records[1:] {
-		if *numIssues > 0 && i >= *numIssues {
-			log.Println("[Main] Reached issue limit:", *numIssues)
+	// -since-question-id/-since-row let an incremental regeneration run (in
+	// -append mode) skip rows already processed by a prior run, so only the
+	// tail appended to data.csv since the marker is processed. taskPrices
+	// below is deliberately collected over the full file, not just the
+	// resumed tail, so outsourcing's price-percentile ranking stays
+	// consistent across incremental runs.
+	resumeScanner, err := newResumeMarkerScanner(questionIDCol, *sinceQuestionID, *sinceRow)
+	if err != nil {
+		log.Fatalf("[Main] Invalid resume marker: %v", err)
+	}
+
+	// Single streaming pass over the CSV's data rows: reader.Read() is called
+	// one record at a time instead of buffering the whole file with
+	// ReadAll(), so memory use stays bounded by one row rather than the
+	// entire dataset. taskPrices is still collected over every row (even
+	// ones skipped by the resume marker or past -num_issues) to match the
+	// full-file price-percentile ranking the non-streaming version computed.
+	var taskPrices []float64
+	skippedShort := 0
+	resumedRows := 0
+	loggedResume := false
+	loggedLimit := false
+	dataRowIndex := 0
+	consideredIndex := 0 // 0-based index into post-resume rows, mirrors the row-index cap -num_issues applies
+	var jobs []categorizationJob
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
 			break
 		}
-		taskID := record[col("question_id")]
-		desc := record[col("prompt")]
+		if err != nil {
+			log.Fatalf("[Main] Failed to read CSV row %d: %v", dataRowIndex+2, err)
+		}
+		dataRowIndex++
+
+		if priceCol != -1 && priceCol < len(record) {
+			if v, err := strconv.ParseFloat(record[priceCol], 64); err == nil {
+				taskPrices = append(taskPrices, v)
+			}
+		}
+
+		if resumeScanner.Skip(record) {
+			resumedRows++
+			continue
+		}
+		if resumedRows > 0 && !loggedResume {
+			log.Println("[Main] Resuming after row", resumedRows, "- skipping rows already processed by a prior run")
+			loggedResume = true
+		}
+
+		if *numIssues > 0 && consideredIndex >= *numIssues {
+			if !loggedLimit {
+				log.Println("[Main] Reached issue limit:", *numIssues)
+				loggedLimit = true
+			}
+			continue
+		}
+		consideredIndex++
+
+		taskID := record[questionIDCol]
+		desc := record[promptCol]
+
+		if cleaned := cleanString(desc); len(cleaned) < *minDescLen {
+			skippedShort++
+			verbose(quiet, "[Main] Skipping task", taskID, "- description shorter than", *minDescLen, "characters")
+			continue
+		}
 
 		price := 0.0
-		if pcol := col("price"); pcol != -1 {
-			if v, err := strconv.ParseFloat(record[pcol], 64); err == nil {
+		if priceCol != -1 && priceCol < len(record) {
+			if v, err := strconv.ParseFloat(record[priceCol], 64); err == nil {
 				price = v
 			}
 		}
 		priceLimit := 0.0
-		if plcol := col("price_limit"); plcol != -1 {
-			if v, err := strconv.ParseFloat(record[plcol], 64); err == nil {
+		if priceLimitCol != -1 && priceLimitCol < len(record) {
+			if v, err := strconv.ParseFloat(record[priceLimitCol], 64); err == nil {
 				priceLimit = v
 			}
 		}
+		jobs = append(jobs, categorizationJob{taskID: taskID, desc: desc, price: price, priceLimit: priceLimit})
+	}
+	if err := resumeScanner.Done(); err != nil {
+		log.Fatalf("[Main] Invalid resume marker: %v", err)
+	}
 
-		// Use Ollama to assign specialty for this task
-		var taskSpecialty string
-		var classifyErr error
-		for attempt := 0; attempt < *llmRetries; attempt++ {
-			log.Println("[Main] Calling LLM for task", taskID, "(attempt", attempt+1, ")")
-			taskSpecialty, classifyErr = classifySpecialtyOllama(
-				fmt.Sprintf("Classify this software issue description into a specialty:\n%s", desc),
-				*ollamaURL, *model, specialities)
-			if classifyErr == nil && taskSpecialty != "" && taskSpecialty != "Unknown" {
-				break
-			}
-			log.Println("[Main] LLM failed or returned unknown specialty, retrying...")
-		}
-		if classifyErr != nil || taskSpecialty == "" || taskSpecialty == "Unknown" {
-			taskSpecialty = specialities[rand.Intn(len(specialities))].Name // fallback
-			log.Println("[WARN] LLM could not classify task", taskID, ", fallback to random specialty:", taskSpecialty)
-		} else {
-			log.Println("[Main] LLM assigned specialty for task", taskID, ":", taskSpecialty)
+	effectiveCacheDir := *cacheDir
+	if *noCache {
+		effectiveCacheDir = ""
+	}
+
+	// Second pass: classify every job, using -workers goroutines when the
+	// LLM is involved so a large batch isn't bottlenecked on one
+	// classification call at a time.
+	results := classifyTasksConcurrently(jobs, *workers, categorizationConfig{
+		llmAvailable:   llmAvailable,
+		quiet:          quiet,
+		llmRetries:     *llmRetries,
+		backend:        *backend,
+		ollamaURL:      *ollamaURL,
+		openaiURL:      *openaiURL,
+		openaiAPIKey:   openaiAPIKey,
+		model:          *model,
+		specialities:   specialities,
+		cacheDir:       effectiveCacheDir,
+		cacheMaxAge:    *cacheMaxAge,
+		wantComparison: *categorizationReportPath != "",
+	})
+
+	// Third pass: assign each classified task to a random agent and
+	// optionally outsource it, sequentially so the shared agents slice and
+	// the global rand source are only ever touched from one goroutine.
+	outsourcedCount := 0
+	var categorizationComparisons []CategorizationComparison
+	for i, job := range jobs {
+		result := results[i]
+		if result.comparison != nil {
+			categorizationComparisons = append(categorizationComparisons, *result.comparison)
 		}
 
 		taskBrief := TaskBrief{
-			ID:        taskID,
-			Desc:      desc,
-			Specialty: taskSpecialty,
-			PriceMin:  price,
-			PriceMax:  priceLimit,
+			ID:        job.taskID,
+			Desc:      job.desc,
+			Specialty: result.specialty,
+			PriceMin:  job.price,
+			PriceMax:  job.priceLimit,
 		}
 		// Assign to a random agent
 		randomAgentIdx := rand.Intn(*numAgents)
 		agents[randomAgentIdx].Tasks = append(agents[randomAgentIdx].Tasks, taskBrief)
+
+		// Optionally outsource the task to a second agent too, modeling wider
+		// auction exposure; the probability of this rises with task price
+		// when -price-weighted-outsource is set.
+		if *numAgents > 1 {
+			percentile := pricePercentile(taskPrices, job.price)
+			if rand.Float64() < outsourceProbability(*priceWeightedOutsource, percentile) {
+				outsourceIdx := randomAgentIdx
+				for outsourceIdx == randomAgentIdx {
+					outsourceIdx = rand.Intn(*numAgents)
+				}
+				agents[outsourceIdx].Tasks = append(agents[outsourceIdx].Tasks, taskBrief)
+				outsourcedCount++
+			}
+		}
+	}
+	log.Println("[Main] Outsourced", outsourcedCount, "tasks to a second agent")
+
+	if *categorizationReportPath != "" {
+		if err := writeJSONAtomic(*categorizationReportPath, categorizationComparisons); err != nil {
+			log.Printf("[WARN] Failed to write categorization report to %s: %v", *categorizationReportPath, err)
+		} else {
+			log.Println("[Main] Wrote LLM-vs-keyword categorization report to", *categorizationReportPath)
+		}
+	}
+
+	if *mergeIdenticalAgentsFlag {
+		before := len(agents)
+		agents = mergeIdenticalAgents(agents)
+		log.Println("[Main] Merged identical-specialty agents:", before, "->", len(agents))
 	}
 
 	log.Println("[Main] Writing agent JSON files to", *outputDir)
@@ -311,15 +1180,17 @@ func main() {
 		if len(agent.Specialities) > 0 {
 			agent.Specialities[len(agent.Specialities)-1].Weight = mathRound(agent.Specialities[len(agent.Specialities)-1].Weight+diff, 2)
 		}
-		data, _ := json.MarshalIndent(agent, "", "  ")
 		outfile := filepath.Join(*outputDir, fmt.Sprintf("%s.json", agent.AgentID))
-		err := os.WriteFile(outfile, data, 0644)
+		err := writeJSONAtomic(outfile, agent)
 		if err != nil {
 			log.Println("[Main] Failed to write", outfile, ":", err)
 		} else {
-			log.Println("[Main] Wrote", outfile)
+			verbose(quiet, "[Main] Wrote", outfile)
 		}
 	}
+	if skippedShort > 0 {
+		log.Println("[Main] Skipped", skippedShort, "task(s) with description shorter than", *minDescLen, "characters")
+	}
 	log.Println("[Main] All agent files written.")
 	fmt.Printf("Agent JSON files written to %s/ (with LLM-assigned specialties, weights summing to 100%%, and task assignments)\n", *outputDir)
 }
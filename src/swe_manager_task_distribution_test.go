@@ -0,0 +1,589 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenInputOverHTTP(t *testing.T) {
+	const body = "question_id,variant,price,price_limit,prompt\nt1,swe_manager,100,200,hello\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	r, err := openInput(server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatalf("openInput over HTTP failed: %v", err)
+	}
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	if string(got) != body {
+		t.Fatalf("expected %q, got %q", body, string(got))
+	}
+}
+
+func TestMergeIdenticalAgentsCombinesTasksWithoutDuplication(t *testing.T) {
+	agents := []*AgentSchema{
+		{
+			AgentID:      "agent1",
+			Specialities: []Speciality{{Name: "UI/UX", Weight: 60}, {Name: "BugFixes", Weight: 40}},
+			Tasks:        []TaskBrief{{ID: "task1"}, {ID: "task2"}},
+		},
+		{
+			AgentID:      "agent2",
+			Specialities: []Speciality{{Name: "BugFixes", Weight: 50}, {Name: "UI/UX", Weight: 50}},
+			Tasks:        []TaskBrief{{ID: "task2"}, {ID: "task3"}},
+		},
+		{
+			AgentID:      "agent3",
+			Specialities: []Speciality{{Name: "ServerSideLogic", Weight: 100}},
+			Tasks:        []TaskBrief{{ID: "task4"}},
+		},
+	}
+
+	merged := mergeIdenticalAgents(agents)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 agents after merge, got %d", len(merged))
+	}
+
+	var uiAgent *AgentSchema
+	for _, a := range merged {
+		if a.AgentID == "agent1" {
+			uiAgent = a
+		}
+	}
+	if uiAgent == nil {
+		t.Fatal("expected agent1 to survive as the merge target")
+	}
+	if len(uiAgent.Tasks) != 3 {
+		t.Fatalf("expected 3 distinct tasks after merge, got %d: %+v", len(uiAgent.Tasks), uiAgent.Tasks)
+	}
+}
+
+func TestOpenInputOverHTTPNon200(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if _, err := openInput(server.URL, 5*time.Second); err == nil {
+		t.Fatal("expected an error for a non-200 response, got nil")
+	}
+}
+
+func TestOutsourceProbabilityScalesWithPricePercentile(t *testing.T) {
+	if got := outsourceProbability(false, 1.0); got != baseOutsourceRate {
+		t.Fatalf("expected flat baseOutsourceRate when priceWeighted is false, got %v", got)
+	}
+	low := outsourceProbability(true, 0.0)
+	high := outsourceProbability(true, 1.0)
+	if low != baseOutsourceRate {
+		t.Fatalf("expected 0th-percentile probability to equal the baseline, got %v", low)
+	}
+	if high <= low {
+		t.Fatalf("expected top-percentile probability %v to exceed bottom-percentile %v", high, low)
+	}
+}
+
+func TestHighPriceTasksOutsourceMoreOftenOnAverage(t *testing.T) {
+	prices := []float64{10, 20, 30, 1000}
+	lowProb := outsourceProbability(true, pricePercentile(prices, 10))
+	highProb := outsourceProbability(true, pricePercentile(prices, 1000))
+
+	rand.Seed(1)
+	const trials = 5000
+	lowCount, highCount := 0, 0
+	for i := 0; i < trials; i++ {
+		if rand.Float64() < lowProb {
+			lowCount++
+		}
+		if rand.Float64() < highProb {
+			highCount++
+		}
+	}
+	if highCount <= lowCount {
+		t.Fatalf("expected the high-priced task to accumulate more outsource edges on average: low=%d high=%d", lowCount, highCount)
+	}
+}
+
+func TestOllamaBaseURLStripsAPIPath(t *testing.T) {
+	if got := ollamaBaseURL("http://localhost:11434/api/generate"); got != "http://localhost:11434/" {
+		t.Fatalf("expected base URL to strip the api path, got %q", got)
+	}
+}
+
+func TestProbeOllamaFastFailsWhenServerUnreachable(t *testing.T) {
+	// No server listens on this address, so the probe must report false
+	// quickly rather than hanging for the full retry/timeout cycle.
+	if probeOllama("http://127.0.0.1:1", 200*time.Millisecond) {
+		t.Fatal("expected probeOllama to report false for an unreachable server")
+	}
+}
+
+func TestProbeOllamaReportsTrueWhenServerReachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Ollama is running"))
+	}))
+	defer server.Close()
+
+	if !probeOllama(server.URL, 2*time.Second) {
+		t.Fatal("expected probeOllama to report true for a reachable server")
+	}
+}
+
+func TestClassifySpecialtyKeywordMatchesRelevantSpecialty(t *testing.T) {
+	specialities := []Speciality{
+		{Name: "UI/UX", Description: "This agent's speciality includes design changes, layout, interaction improvements.", Example: "Overlay background color is different."},
+		{Name: "ServerSideLogic", Description: "API endpoints, DB queries, authentication, data processing-related tasks.", Example: "The tooltip displays the users' email instead of their display name when hovering over the counter in the split preview."},
+	}
+	got := classifySpecialtyKeyword("The API endpoint for authentication returns the wrong data processing result.", specialities)
+	if got != "ServerSideLogic" {
+		t.Fatalf("expected ServerSideLogic to match on keyword overlap, got %s", got)
+	}
+}
+
+func TestClassifyTasksConcurrentlyPreservesJobOrderRegardlessOfCompletionOrder(t *testing.T) {
+	specialities := []Speciality{
+		{Name: "UI/UX", Description: "design changes, layout, interaction improvements"},
+		{Name: "ServerSideLogic", Description: "API endpoints, DB queries, authentication, data processing"},
+	}
+	jobs := make([]categorizationJob, 20)
+	for i := range jobs {
+		jobs[i] = categorizationJob{taskID: fmt.Sprintf("t%d", i), desc: "The API endpoint for authentication returns the wrong result."}
+	}
+
+	cfg := categorizationConfig{llmAvailable: false, quiet: true, specialities: specialities}
+	results := classifyTasksConcurrently(jobs, 8, cfg)
+
+	if len(results) != len(jobs) {
+		t.Fatalf("expected %d results, got %d", len(jobs), len(results))
+	}
+	for i, r := range results {
+		if r.specialty != "ServerSideLogic" {
+			t.Fatalf("result %d: expected ServerSideLogic, got %q", i, r.specialty)
+		}
+	}
+}
+
+func TestClassifyTasksConcurrentlyProducesComparisonsOnlyWhenRequested(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": "UI/UX", "done": true}`))
+	}))
+	defer server.Close()
+
+	specialities := []Speciality{{Name: "UI/UX"}}
+	jobs := []categorizationJob{{taskID: "t1", desc: "some bug"}}
+	baseCfg := categorizationConfig{llmAvailable: true, quiet: true, llmRetries: 1, ollamaURL: server.URL, model: "cogito:14b", specialities: specialities}
+
+	withoutComparison := baseCfg
+	withoutComparison.wantComparison = false
+	results := classifyTasksConcurrently(jobs, 2, withoutComparison)
+	if results[0].comparison != nil {
+		t.Fatalf("expected no comparison when wantComparison is false, got %+v", results[0].comparison)
+	}
+
+	withComparison := baseCfg
+	withComparison.wantComparison = true
+	results = classifyTasksConcurrently(jobs, 2, withComparison)
+	if results[0].comparison == nil {
+		t.Fatalf("expected a comparison when wantComparison is true, got nil")
+	}
+}
+
+func TestDominantSpecialityReturnsHighestWeighted(t *testing.T) {
+	specs := []Speciality{{Name: "UI/UX", Weight: 30}, {Name: "BugFixes", Weight: 70}}
+	if got := dominantSpeciality(specs); got != "BugFixes" {
+		t.Fatalf("expected BugFixes as the dominant speciality, got %q", got)
+	}
+	if got := dominantSpeciality(nil); got != "" {
+		t.Fatalf("expected empty string for no specialities, got %q", got)
+	}
+}
+
+func TestAgentDisplayNameRepeatableUsesStableAgentNumber(t *testing.T) {
+	specs := []Speciality{{Name: "UI/UX", Weight: 100}}
+	if got := agentDisplayName(3, specs, true); got != "Agent 3" {
+		t.Fatalf("expected repeatable name \"Agent 3\", got %q", got)
+	}
+}
+
+func TestAgentDisplayNameNonRepeatableUsesDominantSpeciality(t *testing.T) {
+	specs := []Speciality{{Name: "UI/UX", Weight: 20}, {Name: "ServerSideLogic", Weight: 80}}
+	if got := agentDisplayName(3, specs, false); got != "ServerSideLogic Specialist #3" {
+		t.Fatalf("expected dominant-speciality name, got %q", got)
+	}
+	if got := agentDisplayName(1, nil, false); got != "Agent 1" {
+		t.Fatalf("expected fallback to \"Agent 1\" when specs is empty, got %q", got)
+	}
+}
+
+func TestClassifySpecialtyOllamaFailsOnOversizedResponse(t *testing.T) {
+	orig := maxResponseBytes
+	maxResponseBytes = 16
+	defer func() { maxResponseBytes = orig }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": "` + strings.Repeat("x", 1000) + `", "done": true}`))
+	}))
+	defer server.Close()
+
+	specs := []Speciality{{Name: "UI/UX"}}
+	if _, err := classifySpecialtyOllama("classify", server.URL, "cogito:14b", specs); err == nil {
+		t.Fatal("expected classifySpecialtyOllama to fail on an oversized response, got nil")
+	}
+}
+
+func TestLLMCacheFilePathIsShardedByFirstTwoHexChars(t *testing.T) {
+	key := llmCacheKey("cogito:14b", "classify this")
+	path := llmCacheFilePath("/cache", key)
+	want := filepath.Join("/cache", key[:2], key+".json")
+	if path != want {
+		t.Fatalf("expected sharded path %q, got %q", want, path)
+	}
+}
+
+func TestWriteLLMCacheThenReadLLMCacheRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeLLMCache(dir, "cogito:14b", "classify this", "UI/UX"); err != nil {
+		t.Fatalf("writeLLMCache failed: %v", err)
+	}
+	got, ok := readLLMCache(dir, "cogito:14b", "classify this", 0)
+	if !ok || got != "UI/UX" {
+		t.Fatalf("expected cache hit UI/UX, got %q (ok=%v)", got, ok)
+	}
+	if _, ok := readLLMCache(dir, "cogito:14b", "a different prompt", 0); ok {
+		t.Fatal("expected a cache miss for a different prompt")
+	}
+}
+
+func TestReadLLMCacheIgnoresEntriesOlderThanMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeLLMCache(dir, "cogito:14b", "classify this", "UI/UX"); err != nil {
+		t.Fatalf("writeLLMCache failed: %v", err)
+	}
+	key := llmCacheKey("cogito:14b", "classify this")
+	path := llmCacheFilePath(dir, key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read written cache entry: %v", err)
+	}
+	var entry llmCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("failed to unmarshal cache entry: %v", err)
+	}
+	entry.Timestamp = time.Now().Add(-1 * time.Hour)
+	staleData, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("failed to marshal stale cache entry: %v", err)
+	}
+	if err := os.WriteFile(path, staleData, 0644); err != nil {
+		t.Fatalf("failed to write stale cache entry: %v", err)
+	}
+
+	if _, ok := readLLMCache(dir, "cogito:14b", "classify this", time.Minute); ok {
+		t.Fatal("expected a stale entry past -cache-max-age to be treated as a miss")
+	}
+	if _, ok := readLLMCache(dir, "cogito:14b", "classify this", 0); !ok {
+		t.Fatal("expected a stale entry to still hit when maxAge is 0 (never expire)")
+	}
+}
+
+func TestClassifySpecialtyOllamaCachedSkipsTheServerOnASecondCall(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"response": "UI/UX", "done": true}`))
+	}))
+	defer server.Close()
+
+	specs := []Speciality{{Name: "UI/UX"}}
+	first, err := classifySpecialtyOllamaCached("classify this", server.URL, "cogito:14b", specs, dir, 0)
+	if err != nil || first != "UI/UX" {
+		t.Fatalf("expected first call to classify UI/UX, got %q, err=%v", first, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 server call after the first classification, got %d", calls)
+	}
+
+	second, err := classifySpecialtyOllamaCached("classify this", server.URL, "cogito:14b", specs, dir, 0)
+	if err != nil || second != "UI/UX" {
+		t.Fatalf("expected cached call to classify UI/UX, got %q, err=%v", second, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the second call to hit the cache without calling the server, got %d calls", calls)
+	}
+}
+
+func TestClassifySpecialtyOpenAIParsesChatCompletionsResponse(t *testing.T) {
+	var gotAuth, gotModel string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		var payload struct {
+			Model string `json:"model"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode request payload: %v", err)
+		}
+		gotModel = payload.Model
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "UI/UX"}}]}`))
+	}))
+	defer server.Close()
+
+	specs := []Speciality{{Name: "UI/UX"}}
+	got, err := classifySpecialtyOpenAI("classify", server.URL, "sk-test", "gpt-4o-mini", specs)
+	if err != nil || got != "UI/UX" {
+		t.Fatalf("expected UI/UX, got %q, err=%v", got, err)
+	}
+	if gotAuth != "Bearer sk-test" {
+		t.Fatalf("expected the API key to be sent as a bearer token, got %q", gotAuth)
+	}
+	if gotModel != "gpt-4o-mini" {
+		t.Fatalf("expected model gpt-4o-mini, got %q", gotModel)
+	}
+}
+
+func TestClassifySpecialtyOpenAIFailsOnEmptyChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices": []}`))
+	}))
+	defer server.Close()
+
+	specs := []Speciality{{Name: "UI/UX"}}
+	if _, err := classifySpecialtyOpenAI("classify", server.URL, "", "gpt-4o-mini", specs); err == nil {
+		t.Fatal("expected an error when the response has no choices")
+	}
+}
+
+func TestClassifySpecialtyDispatchesToTheSelectedBackend(t *testing.T) {
+	ollamaCalls, openaiCalls := 0, 0
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ollamaCalls++
+		w.Write([]byte(`{"response": "UI/UX", "done": true}`))
+	}))
+	defer ollamaServer.Close()
+	openaiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		openaiCalls++
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "UI/UX"}}]}`))
+	}))
+	defer openaiServer.Close()
+
+	specs := []Speciality{{Name: "UI/UX"}}
+	baseCfg := categorizationConfig{ollamaURL: ollamaServer.URL, openaiURL: openaiServer.URL, model: "m", specialities: specs}
+
+	if _, err := classifySpecialty("classify", baseCfg); err != nil {
+		t.Fatalf("classifySpecialty (ollama) failed: %v", err)
+	}
+	if ollamaCalls != 1 || openaiCalls != 0 {
+		t.Fatalf("expected the default backend to call Ollama only, got ollamaCalls=%d openaiCalls=%d", ollamaCalls, openaiCalls)
+	}
+
+	openaiCfg := baseCfg
+	openaiCfg.backend = "openai"
+	if _, err := classifySpecialty("classify", openaiCfg); err != nil {
+		t.Fatalf("classifySpecialty (openai) failed: %v", err)
+	}
+	if openaiCalls != 1 {
+		t.Fatalf("expected -backend=openai to call the OpenAI-compatible endpoint, got %d calls", openaiCalls)
+	}
+}
+
+func TestCompareCategorizationMethodsReportsLowAgreementOnDisagreement(t *testing.T) {
+	cmp := compareCategorizationMethods("task1", "UI/UX", "BugFixes")
+	if cmp.Agreement != 0 {
+		t.Fatalf("expected agreement 0 for disjoint categories, got %v", cmp.Agreement)
+	}
+	if len(cmp.LLMCategories) != 1 || cmp.LLMCategories[0] != "UI/UX" {
+		t.Fatalf("expected LLM category UI/UX, got %+v", cmp.LLMCategories)
+	}
+	if len(cmp.KeywordCategories) != 1 || cmp.KeywordCategories[0] != "BugFixes" {
+		t.Fatalf("expected keyword category BugFixes, got %+v", cmp.KeywordCategories)
+	}
+}
+
+func TestCompareCategorizationMethodsReportsFullAgreementOnMatch(t *testing.T) {
+	cmp := compareCategorizationMethods("task1", "UI/UX", "UI/UX")
+	if cmp.Agreement != 1 {
+		t.Fatalf("expected agreement 1 for matching categories, got %v", cmp.Agreement)
+	}
+}
+
+func TestJaccardSimilarityOfEmptySetsIsOne(t *testing.T) {
+	if got := jaccardSimilarity(nil, nil); got != 1 {
+		t.Fatalf("expected jaccardSimilarity(nil, nil) == 1, got %v", got)
+	}
+}
+
+func TestApplyConfigFileAppliesValuesAndCommandLineFlagsOverride(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configJSON := `{"num_agents": 25, "model": "config-model", "quiet": true}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	oldCommandLine := flag.CommandLine
+	flag.CommandLine = flag.NewFlagSet("swe_manager_test", flag.ContinueOnError)
+	defer func() { flag.CommandLine = oldCommandLine }()
+
+	numAgents := flag.Int("num_agents", 10, "")
+	model := flag.String("model", "cogito:14b", "")
+	var quiet bool
+	flag.BoolVar(&quiet, "quiet", false, "")
+
+	// Simulate an explicit command-line flag alongside -config: it should win
+	// over the config file's value for the same setting.
+	if err := flag.CommandLine.Parse([]string{"-model=explicit-model"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	if err := applyConfigFile(configPath); err != nil {
+		t.Fatalf("applyConfigFile returned error: %v", err)
+	}
+
+	if *numAgents != 25 {
+		t.Errorf("expected config value to be applied to -num_agents, got %d", *numAgents)
+	}
+	if *model != "explicit-model" {
+		t.Errorf("expected explicit -model=explicit-model to override config value, got %q", *model)
+	}
+	if !quiet {
+		t.Errorf("expected config value to be applied to -quiet")
+	}
+}
+
+func TestWriteJSONAtomicWritesValidJSONAndLeavesNoTempFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent1.json")
+	if err := writeJSONAtomic(path, AgentSchema{AgentID: "agent1"}); err != nil {
+		t.Fatalf("writeJSONAtomic failed: %v", err)
+	}
+
+	var decoded AgentSchema
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read written file: %v", err)
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode written file: %v", err)
+	}
+	if decoded.AgentID != "agent1" {
+		t.Fatalf("expected agent1, got %+v", decoded)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected no leftover .tmp file, got err=%v", err)
+	}
+}
+
+func TestVerboseSuppressesPerTaskLinesInQuietModeButSummaryStaysVisible(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(orig)
+
+	verbose(true, "[Main] Skipping task", "task1", "- description shorter than", 20, "characters")
+	log.Println("[Main] Outsourced", 3, "tasks to a second agent")
+
+	output := buf.String()
+	if strings.Contains(output, "Skipping task") {
+		t.Fatalf("expected the per-task line to be suppressed in quiet mode, got: %s", output)
+	}
+	if !strings.Contains(output, "Outsourced 3 tasks to a second agent") {
+		t.Fatalf("expected the summary line to remain present in quiet mode, got: %s", output)
+	}
+}
+
+func TestVerboseLogsWhenNotQuiet(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(orig)
+
+	verbose(false, "[Main] Skipping task", "task1")
+	if !strings.Contains(buf.String(), "Skipping task task1") {
+		t.Fatalf("expected the per-task line to be logged when not quiet, got: %s", buf.String())
+	}
+}
+
+func fixtureQuestionIDRecords() [][]string {
+	return [][]string{
+		{"question_id", "prompt"},
+		{"q1", "first"},
+		{"q2", "second"},
+		{"q3", "third"},
+	}
+}
+
+func TestResumeMarkerScannerBySinceQuestionIDSkipsUpToAndIncludingTheMarker(t *testing.T) {
+	records := fixtureQuestionIDRecords()
+	scanner, err := newResumeMarkerScanner(0, "q1", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var remaining [][]string
+	for _, record := range records[1:] {
+		if !scanner.Skip(record) {
+			remaining = append(remaining, record)
+		}
+	}
+	if err := scanner.Done(); err != nil {
+		t.Fatalf("unexpected error from Done: %v", err)
+	}
+	if len(remaining) != 2 || remaining[0][0] != "q2" || remaining[1][0] != "q3" {
+		t.Fatalf("expected only q2 and q3 to remain, got %v", remaining)
+	}
+}
+
+func TestResumeMarkerScannerBySinceRowSkipsThatManyRows(t *testing.T) {
+	records := fixtureQuestionIDRecords()
+	scanner, err := newResumeMarkerScanner(0, "", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var remaining [][]string
+	for _, record := range records[1:] {
+		if !scanner.Skip(record) {
+			remaining = append(remaining, record)
+		}
+	}
+	if len(remaining) != 1 || remaining[0][0] != "q3" {
+		t.Fatalf("expected only q3 to remain, got %v", remaining)
+	}
+}
+
+func TestResumeMarkerScannerErrorsWhenQuestionIDNotFound(t *testing.T) {
+	records := fixtureQuestionIDRecords()
+	scanner, err := newResumeMarkerScanner(0, "missing", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, record := range records[1:] {
+		scanner.Skip(record)
+	}
+	if err := scanner.Done(); err == nil {
+		t.Fatalf("expected an error for an unknown -since-question-id")
+	}
+}
+
+func TestResumeMarkerScannerErrorsWhenBothFlagsAreSet(t *testing.T) {
+	if _, err := newResumeMarkerScanner(0, "q1", 2); err == nil {
+		t.Fatalf("expected an error when -since-question-id and -since-row are both set")
+	}
+}
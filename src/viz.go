@@ -0,0 +1,3443 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// embeddedStatic is the default static bundle baked into the binary so it
+// can serve a visualization even when no on-disk static/ directory exists.
+//
+//go:embed embedded_static
+var embeddedStatic embed.FS
+
+type Speciality struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+type TaskSpeciality struct {
+	Name string `json:"name"`
+}
+
+type Node struct {
+	ID                 string          `json:"id"`
+	Type               string          `json:"type"`
+	Label              string          `json:"label"`
+	Group              string          `json:"group,omitempty"`
+	Avatar             string          `json:"avatar,omitempty"`
+	Specialities       []Speciality    `json:"specialities,omitempty"`
+	Speciality         *TaskSpeciality `json:"speciality,omitempty"`
+	PriceMin           float64         `json:"price_min,omitempty"`
+	PriceMax           float64         `json:"price_max,omitempty"`
+	NormalizedPriceMin float64         `json:"normalized_price_min,omitempty"`
+	NormalizedPriceMax float64         `json:"normalized_price_max,omitempty"`
+	Desc               string          `json:"desc,omitempty"`
+	Degree             int             `json:"degree,omitempty"`
+}
+
+type Edge struct {
+	Source             string  `json:"source"`
+	Target             string  `json:"target"`
+	Type               string  `json:"type"`
+	BidValue           float64 `json:"bid_value,omitempty"`
+	NormalizedBidValue float64 `json:"normalized_bid_value,omitempty"`
+}
+
+// UnmarshalJSON accepts "bid_value" (this repo's own field name) or, failing
+// that, "winning_bid" - a name other network sources have been seen to use
+// for the same figure - so an edge produced by a differently-named pipeline
+// still populates BidValue instead of silently reading as zero.
+func (e *Edge) UnmarshalJSON(data []byte) error {
+	type edgeAlias Edge
+	aux := struct {
+		WinningBid *float64 `json:"winning_bid"`
+		*edgeAlias
+	}{edgeAlias: (*edgeAlias)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if e.BidValue == 0 && aux.WinningBid != nil {
+		e.BidValue = *aux.WinningBid
+	}
+	return nil
+}
+
+type Network struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// networkMu guards concurrent access to currentNetwork, swapped out from
+// under in-flight request handlers by POST /api/load - every handler that
+// reads the live network must go through getNetwork rather than touching
+// currentNetwork directly, or a concurrent /api/load races it.
+var (
+	networkMu      sync.Mutex
+	currentNetwork Network
+)
+
+// getNetwork returns the current live network. The lock is held only long
+// enough to copy the struct - cheap, since Network holds only slice headers
+// - not for anything the caller does with the result.
+func getNetwork() Network {
+	networkMu.Lock()
+	defer networkMu.Unlock()
+	return currentNetwork
+}
+
+// setNetwork atomically swaps in n as the live network.
+func setNetwork(n Network) {
+	networkMu.Lock()
+	currentNetwork = n
+	networkMu.Unlock()
+}
+
+// lineAndColumn converts a byte offset into data into a 1-indexed line and
+// column, for reporting JSON errors with human-readable location context.
+func lineAndColumn(data []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// unmarshalJSONWithContext wraps json.Unmarshal errors with filename and, for
+// offset-carrying errors, line/column context, so callers don't have to
+// puzzle out a bare "invalid character ... at offset N".
+func unmarshalJSONWithContext(filename string, data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	line, col := lineAndColumn(data, offset)
+	return fmt.Errorf("%s:%d:%d: %w", filename, line, col, err)
+}
+
+// computeDegrees returns the degree of every node in n, counting each edge
+// endpoint once regardless of edge type.
+func computeDegrees(n Network) map[string]int {
+	degrees := make(map[string]int, len(n.Nodes))
+	for _, node := range n.Nodes {
+		degrees[node.ID] = 0
+	}
+	for _, e := range n.Edges {
+		if _, ok := degrees[e.Source]; ok {
+			degrees[e.Source]++
+		}
+		if _, ok := degrees[e.Target]; ok {
+			degrees[e.Target]++
+		}
+	}
+	return degrees
+}
+
+// reconcileDegrees sets each node's Degree from its actual edges, discarding
+// whatever value the source JSON carried, unless trustSource is set, in
+// which case the source's degree values are left untouched. A network JSON
+// from an external generator may carry stale or absent degree values, so
+// recomputation is the default.
+func reconcileDegrees(n *Network, trustSource bool) {
+	if trustSource {
+		return
+	}
+	degrees := computeDegrees(*n)
+	for i := range n.Nodes {
+		n.Nodes[i].Degree = degrees[n.Nodes[i].ID]
+	}
+}
+
+// defaultScope is the /api/filter scope used when the request omits
+// ?scope=; set from -exclude-outsourced in main().
+var defaultScope = "all"
+
+// edgesInScope drops "outsourced" edges when scope is "realized", since they
+// represent an intention to hand a task to a second agent rather than a
+// realized transaction, and otherwise inflate degree and density. Any other
+// scope value (including "all" or "") returns edges unchanged.
+func edgesInScope(edges []Edge, scope string) []Edge {
+	if scope != "realized" {
+		return edges
+	}
+	realized := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		if e.Type != "outsourced" {
+			realized = append(realized, e)
+		}
+	}
+	return realized
+}
+
+// networkInScope returns n with edgesInScope applied; nodes are unaffected.
+func networkInScope(n Network, scope string) Network {
+	return Network{Nodes: n.Nodes, Edges: edgesInScope(n.Edges, scope)}
+}
+
+// densityEdgeWeights maps edge type to the weight it contributes to
+// weightedNetworkDensity; nil (the default) weights every edge type 1,
+// matching a plain edge count. Set from -density-edges in main().
+var densityEdgeWeights map[string]float64
+
+// atkinsonEpsilon is the inequality aversion parameter passed to
+// calculateAtkinsonIndex for /api/market-metrics. Set from
+// -atkinson-epsilon in main().
+var atkinsonEpsilon float64
+
+// parseDensityEdgeWeights parses a comma-separated "type[:weight]" list,
+// e.g. "assigned:1,bid:0.5" - a bare type name with no ":weight" defaults to
+// weight 1. An empty string returns a nil map, i.e. "count every edge type
+// with weight 1".
+func parseDensityEdgeWeights(raw string) (map[string]float64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		typ, weightStr, hasWeight := strings.Cut(part, ":")
+		weight := 1.0
+		if hasWeight {
+			w, err := strconv.ParseFloat(weightStr, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid weight in %q: %w", part, err)
+			}
+			weight = w
+		}
+		weights[typ] = weight
+	}
+	return weights, nil
+}
+
+// weightedNetworkDensity is the weighted fraction of possible directed edges
+// that are present: (sum of each edge's weight) / (n*(n-1)). A nil weights
+// map weights every edge 1, matching a plain edge count; a non-nil map
+// weights an edge type absent from it 0, so only the listed types count.
+// Returns 0 for fewer than 2 nodes.
+func weightedNetworkDensity(n Network, weights map[string]float64) float64 {
+	if len(n.Nodes) <= 1 {
+		return 0
+	}
+	var total float64
+	for _, e := range n.Edges {
+		if weights == nil {
+			total++
+			continue
+		}
+		total += weights[e.Type]
+	}
+	return total / float64(len(n.Nodes)*(len(n.Nodes)-1))
+}
+
+// networkDensity is weightedNetworkDensity applied with the configured
+// -density-edges weighting (densityEdgeWeights).
+func networkDensity(n Network) float64 {
+	return weightedNetworkDensity(n, densityEdgeWeights)
+}
+
+// priceNormalization is the -normalize-prices method applied at load time;
+// "none" leaves the raw BidValue/PriceMin/PriceMax figures as the ones
+// monetary metrics compute over. Set from main().
+var priceNormalization = "none"
+
+// normalizeZScore rescales values to zero mean, unit standard deviation.
+// Returns a copy of values unchanged when there are fewer than 2 values or
+// they have zero variance, since z-scoring is undefined in that case.
+func normalizeZScore(values []float64) []float64 {
+	out := make([]float64, len(values))
+	copy(out, values)
+	if len(values) < 2 {
+		return out
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		d := v - mean
+		variance += d * d
+	}
+	variance /= float64(len(values))
+	stddev := math.Sqrt(variance)
+	if stddev == 0 {
+		return out
+	}
+	for i, v := range values {
+		out[i] = (v - mean) / stddev
+	}
+	return out
+}
+
+// normalizeMinMax rescales values into [0, 1]. Returns all zeros when values
+// have zero range (including the empty and single-value cases).
+func normalizeMinMax(values []float64) []float64 {
+	out := make([]float64, len(values))
+	if len(values) == 0 {
+		return out
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	span := hi - lo
+	if span == 0 {
+		return out
+	}
+	for i, v := range values {
+		out[i] = (v - lo) / span
+	}
+	return out
+}
+
+// rescale applies method ("zscore" or "minmax") to values; any other method
+// (including "none") returns them unchanged.
+func rescale(values []float64, method string) []float64 {
+	switch method {
+	case "zscore":
+		return normalizeZScore(values)
+	case "minmax":
+		return normalizeMinMax(values)
+	default:
+		out := make([]float64, len(values))
+		copy(out, values)
+		return out
+	}
+}
+
+// normalizePrices rescales n's edge bid values and task price bounds by
+// method, storing the result in NormalizedBidValue/NormalizedPriceMin/
+// NormalizedPriceMax alongside the untouched raw fields, so downstream code
+// can choose either via effectiveBidValue/effectivePriceMin/
+// effectivePriceMax depending on priceNormalization.
+func normalizePrices(n *Network, method string) {
+	bidValues := make([]float64, len(n.Edges))
+	for i, e := range n.Edges {
+		bidValues[i] = e.BidValue
+	}
+	normalizedBids := rescale(bidValues, method)
+	for i := range n.Edges {
+		n.Edges[i].NormalizedBidValue = normalizedBids[i]
+	}
+
+	priceMins := make([]float64, len(n.Nodes))
+	priceMaxes := make([]float64, len(n.Nodes))
+	for i, node := range n.Nodes {
+		priceMins[i] = node.PriceMin
+		priceMaxes[i] = node.PriceMax
+	}
+	normalizedMins := rescale(priceMins, method)
+	normalizedMaxes := rescale(priceMaxes, method)
+	for i := range n.Nodes {
+		n.Nodes[i].NormalizedPriceMin = normalizedMins[i]
+		n.Nodes[i].NormalizedPriceMax = normalizedMaxes[i]
+	}
+}
+
+// effectiveBidValue returns e's normalized bid value when priceNormalization
+// is enabled, otherwise its raw BidValue.
+func effectiveBidValue(e Edge) float64 {
+	if priceNormalization == "none" {
+		return e.BidValue
+	}
+	return e.NormalizedBidValue
+}
+
+// effectivePriceMin returns n's normalized PriceMin when priceNormalization
+// is enabled, otherwise its raw PriceMin.
+func effectivePriceMin(n Node) float64 {
+	if priceNormalization == "none" {
+		return n.PriceMin
+	}
+	return n.NormalizedPriceMin
+}
+
+// effectivePriceMax returns n's normalized PriceMax when priceNormalization
+// is enabled, otherwise its raw PriceMax.
+func effectivePriceMax(n Node) float64 {
+	if priceNormalization == "none" {
+		return n.PriceMax
+	}
+	return n.NormalizedPriceMax
+}
+
+// giantComponentOnly restricts every metric endpoint to n's largest
+// connected component when true, regardless of any ?component= query
+// parameter. Set from -giant-component in main().
+var giantComponentOnly = false
+
+// connectedComponents partitions n's nodes into connected components over
+// the undirected projection of its edges (direction and type are ignored).
+// Components, and the node IDs within each, are returned in ascending ID
+// order for determinism.
+func connectedComponents(n Network) [][]string {
+	adjacency := make(map[string]map[string]bool, len(n.Nodes))
+	for _, node := range n.Nodes {
+		adjacency[node.ID] = map[string]bool{}
+	}
+	for _, e := range n.Edges {
+		if _, ok := adjacency[e.Source]; !ok {
+			continue
+		}
+		if _, ok := adjacency[e.Target]; !ok {
+			continue
+		}
+		adjacency[e.Source][e.Target] = true
+		adjacency[e.Target][e.Source] = true
+	}
+
+	nodeIDs := make([]string, 0, len(n.Nodes))
+	for _, node := range n.Nodes {
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+	sort.Strings(nodeIDs)
+
+	visited := make(map[string]bool, len(n.Nodes))
+	var components [][]string
+	for _, start := range nodeIDs {
+		if visited[start] {
+			continue
+		}
+		var component []string
+		queue := []string{start}
+		visited[start] = true
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			component = append(component, cur)
+			neighbors := make([]string, 0, len(adjacency[cur]))
+			for neighbor := range adjacency[cur] {
+				neighbors = append(neighbors, neighbor)
+			}
+			sort.Strings(neighbors)
+			for _, neighbor := range neighbors {
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					queue = append(queue, neighbor)
+				}
+			}
+		}
+		sort.Strings(component)
+		components = append(components, component)
+	}
+	return components
+}
+
+// largestComponent returns the node IDs of n's largest connected component
+// and the fraction of n's nodes it accounts for. Ties are broken by
+// connectedComponents' deterministic ordering (lowest starting node ID
+// wins).
+func largestComponent(n Network) (map[string]bool, float64) {
+	components := connectedComponents(n)
+	if len(components) == 0 {
+		return map[string]bool{}, 0
+	}
+	largest := components[0]
+	for _, c := range components[1:] {
+		if len(c) > len(largest) {
+			largest = c
+		}
+	}
+	ids := make(map[string]bool, len(largest))
+	for _, id := range largest {
+		ids[id] = true
+	}
+	return ids, float64(len(largest)) / float64(len(n.Nodes))
+}
+
+// restrictToComponent returns n with only the nodes in ids, and only the
+// edges whose endpoints are both in ids.
+func restrictToComponent(n Network, ids map[string]bool) Network {
+	nodes := make([]Node, 0, len(ids))
+	for _, node := range n.Nodes {
+		if ids[node.ID] {
+			nodes = append(nodes, node)
+		}
+	}
+	edges := make([]Edge, 0, len(n.Edges))
+	for _, e := range n.Edges {
+		if ids[e.Source] && ids[e.Target] {
+			edges = append(edges, e)
+		}
+	}
+	return Network{Nodes: nodes, Edges: edges}
+}
+
+// componentInfo reports whether a metric computation was restricted to the
+// largest connected component, and how large that component was. Omitted
+// entirely (via a nil *componentInfo) when no restriction applied.
+type componentInfo struct {
+	Restricted    bool    `json:"restricted_to_giant_component"`
+	ComponentSize int     `json:"component_size"`
+	TotalNodes    int     `json:"total_nodes"`
+	SizeFraction  float64 `json:"component_size_fraction"`
+}
+
+// wantsGiantComponent reports whether a metric computation triggered by r
+// should be restricted to the largest connected component: either
+// -giant-component was set, or the request explicitly asks via
+// ?component=giant.
+func wantsGiantComponent(r *http.Request) bool {
+	if giantComponentOnly {
+		return true
+	}
+	return r.URL.Query().Get("component") == "giant"
+}
+
+// networkForMetrics returns n restricted to its largest connected component
+// when r requests it (see wantsGiantComponent), along with componentInfo
+// describing the restriction, or n and nil unchanged otherwise. /data is
+// exempt from this restriction: it always serves the full graph as loaded.
+func networkForMetrics(n Network, r *http.Request) (Network, *componentInfo) {
+	if !wantsGiantComponent(r) {
+		return n, nil
+	}
+	ids, fraction := largestComponent(n)
+	return restrictToComponent(n, ids), &componentInfo{
+		Restricted:    true,
+		ComponentSize: len(ids),
+		TotalNodes:    len(n.Nodes),
+		SizeFraction:  fraction,
+	}
+}
+
+// filterField is one field the /api/filter query language can reference,
+// paired with the comparison operators it accepts.
+type filterField struct {
+	validOps map[string]bool
+}
+
+var filterFields = map[string]filterField{
+	"role":       {validOps: map[string]bool{"=": true, "!=": true}},
+	"degree":     {validOps: map[string]bool{"=": true, "!=": true, ">": true, ">=": true, "<": true, "<=": true}},
+	"specialist": {validOps: map[string]bool{"=": true, "!=": true}},
+	"category":   {validOps: map[string]bool{"=": true, "!=": true}},
+}
+
+// filterComparison is a single "field op value" clause, e.g. "degree>5".
+type filterComparison struct {
+	field, op, value string
+}
+
+var queryTokenRe = regexp.MustCompile(`AND|OR|>=|<=|!=|=|>|<|[A-Za-z_][A-Za-z0-9_.\/-]*|[0-9]+(?:\.[0-9]+)?`)
+
+// parseFilterQuery parses a tiny query grammar over node fields: an OR of
+// AND-groups of "field op value" comparisons, e.g.
+// "role=agent AND degree>5 OR specialist=true". AND binds tighter than OR;
+// there is no parenthesization. Unknown fields or operators are rejected.
+func parseFilterQuery(q string) ([][]filterComparison, error) {
+	tokens := queryTokenRe.FindAllString(q, -1)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	var orGroups [][]filterComparison
+	var cur []filterComparison
+	i := 0
+	for {
+		if len(tokens)-i < 3 {
+			return nil, fmt.Errorf("malformed expression near %q", strings.Join(tokens[i:], " "))
+		}
+		field, op, value := tokens[i], tokens[i+1], tokens[i+2]
+		spec, ok := filterFields[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		if !spec.validOps[op] {
+			return nil, fmt.Errorf("operator %q is not valid for field %q", op, field)
+		}
+		cur = append(cur, filterComparison{field: field, op: op, value: value})
+		i += 3
+
+		if i == len(tokens) {
+			orGroups = append(orGroups, cur)
+			return orGroups, nil
+		}
+		switch tokens[i] {
+		case "AND":
+			i++
+		case "OR":
+			orGroups = append(orGroups, cur)
+			cur = nil
+			i++
+		default:
+			return nil, fmt.Errorf("expected AND/OR, got %q", tokens[i])
+		}
+	}
+}
+
+// nodeCategory returns the single category a node is matched on: a task's
+// declared speciality, or the first of an agent's declared specialities.
+func nodeCategory(n Node) string {
+	if n.Speciality != nil {
+		return n.Speciality.Name
+	}
+	if len(n.Specialities) > 0 {
+		return n.Specialities[0].Name
+	}
+	return ""
+}
+
+// evalComparison reports whether node n satisfies c, given its precomputed degree.
+func evalComparison(n Node, degree int, c filterComparison) (bool, error) {
+	switch c.field {
+	case "role":
+		return compareStrings(n.Type, c.op, c.value), nil
+	case "category":
+		return compareStrings(nodeCategory(n), c.op, c.value), nil
+	case "specialist":
+		want, err := strconv.ParseBool(c.value)
+		if err != nil {
+			return false, fmt.Errorf("invalid boolean value %q for field \"specialist\"", c.value)
+		}
+		isSpecialist := len(n.Specialities) > 0
+		if c.op == "!=" {
+			return isSpecialist != want, nil
+		}
+		return isSpecialist == want, nil
+	case "degree":
+		want, err := strconv.ParseFloat(c.value, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid numeric value %q for field \"degree\"", c.value)
+		}
+		return compareNumbers(float64(degree), c.op, want), nil
+	default:
+		return false, fmt.Errorf("unknown field %q", c.field)
+	}
+}
+
+func compareStrings(got, op, want string) bool {
+	if op == "!=" {
+		return got != want
+	}
+	return got == want
+}
+
+func compareNumbers(got float64, op string, want float64) bool {
+	switch op {
+	case "=":
+		return got == want
+	case "!=":
+		return got != want
+	case ">":
+		return got > want
+	case ">=":
+		return got >= want
+	case "<":
+		return got < want
+	case "<=":
+		return got <= want
+	}
+	return false
+}
+
+// evalFilterQuery reports whether node matches query: true if node satisfies
+// every comparison in at least one OR-group.
+func evalFilterQuery(n Node, degree int, query [][]filterComparison) (bool, error) {
+	for _, group := range query {
+		allMatch := true
+		for _, c := range group {
+			matched, err := evalComparison(n, degree, c)
+			if err != nil {
+				return false, err
+			}
+			if !matched {
+				allMatch = false
+				break
+			}
+		}
+		if allMatch {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// filterResponse is the body of /api/filter.
+type filterResponse struct {
+	Query     string         `json:"query"`
+	Scope     string         `json:"scope"`
+	Density   float64        `json:"density"`
+	Nodes     []Node         `json:"nodes"`
+	Component *componentInfo `json:"component,omitempty"`
+}
+
+// handleFilter evaluates the ?q= query language over the loaded network's
+// nodes and returns those that match. ?scope=realized excludes "outsourced"
+// edges from the degree and density figures it derives (defaulting to
+// -exclude-outsourced's setting), while the node list itself is unaffected -
+// use /data for the full edge set including outsourced links.
+// -giant-component or ?component=giant additionally restricts every figure,
+// and the candidate node set itself, to the largest connected component.
+// handleFilter streams its response directly into w via json.NewEncoder
+// rather than buffering the encoded JSON first, so filtering a large graph
+// doesn't require holding a second full copy of the result in memory.
+func handleFilter(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	query, err := parseFilterQuery(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scope := r.URL.Query().Get("scope")
+	if scope == "" {
+		scope = defaultScope
+	}
+	base, comp := networkForMetrics(getNetwork(), r)
+	scoped := networkInScope(base, scope)
+
+	degrees := computeDegrees(scoped)
+	var matched []Node
+	for _, n := range base.Nodes {
+		ok, err := evalFilterQuery(n, degrees[n.ID], query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if ok {
+			matched = append(matched, n)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	resp := filterResponse{Query: q, Scope: scope, Density: networkDensity(scoped), Nodes: matched, Component: comp}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleFilter] Failed to encode response: %v", err)
+	}
+}
+
+// bidsByTaskFor groups "bid" edges in n by their target task ID.
+func bidsByTaskFor(n Network) map[string][]Edge {
+	byTask := make(map[string][]Edge)
+	for _, e := range n.Edges {
+		if e.Type == "bid" {
+			byTask[e.Target] = append(byTask[e.Target], e)
+		}
+	}
+	return byTask
+}
+
+// bidsByTask is bidsByTaskFor applied to the current network.
+func bidsByTask() map[string][]Edge {
+	return bidsByTaskFor(getNetwork())
+}
+
+// assignedTasksFor returns the set of task IDs in n that have an "assigned"
+// edge, mapped to the value of the bid that won them.
+func assignedTasksFor(n Network) map[string]float64 {
+	bidValue := make(map[[2]string]float64)
+	for _, e := range n.Edges {
+		if e.Type == "bid" {
+			bidValue[[2]string{e.Source, e.Target}] = effectiveBidValue(e)
+		}
+	}
+	assigned := make(map[string]float64)
+	for _, e := range n.Edges {
+		if e.Type != "assigned" {
+			continue
+		}
+		if price, ok := bidValue[[2]string{e.Target, e.Source}]; ok {
+			assigned[e.Source] = price
+		}
+	}
+	return assigned
+}
+
+// assignedTasks is assignedTasksFor applied to the current network.
+func assignedTasks() map[string]float64 {
+	return assignedTasksFor(getNetwork())
+}
+
+// priceTier reports the index of the tier that price falls into, given
+// ascending tier boundaries, e.g. boundaries [1000,10000] yields tier 0 for
+// price<=1000, tier 1 for 1000<price<=10000, and tier 2 for price>10000.
+func priceTier(boundaries []float64, price float64) int {
+	for i, b := range boundaries {
+		if price <= b {
+			return i
+		}
+	}
+	return len(boundaries)
+}
+
+// parseTierBoundaries parses a comma-separated list of ascending price
+// boundaries, e.g. "1000,10000,50000".
+func parseTierBoundaries(raw string) ([]float64, error) {
+	parts := strings.Split(raw, ",")
+	boundaries := make([]float64, 0, len(parts))
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tier boundary %q: %w", p, err)
+		}
+		boundaries = append(boundaries, v)
+	}
+	for i := 1; i < len(boundaries); i++ {
+		if boundaries[i] <= boundaries[i-1] {
+			return nil, fmt.Errorf("tier boundaries must be strictly ascending, got %v", boundaries)
+		}
+	}
+	return boundaries, nil
+}
+
+// TierMetrics summarizes one price tier's tasks.
+type TierMetrics struct {
+	Tier             string  `json:"tier"`
+	NumTasks         int     `json:"num_tasks"`
+	AssignmentRate   float64 `json:"assignment_rate"`
+	AvgBidders       float64 `json:"avg_bidders"`
+	AvgClientSurplus float64 `json:"avg_client_surplus"`
+}
+
+// tierLabel names a tier by its lower and upper price boundaries, "min" and
+// "max" standing in for the open ends of the first and last tier.
+func tierLabel(boundaries []float64, tier int) string {
+	lo := "min"
+	if tier > 0 {
+		lo = strconv.FormatFloat(boundaries[tier-1], 'f', -1, 64)
+	}
+	hi := "max"
+	if tier < len(boundaries) {
+		hi = strconv.FormatFloat(boundaries[tier], 'f', -1, 64)
+	}
+	return lo + "-" + hi
+}
+
+// computeTierMetricsFor partitions n's task nodes into price tiers by
+// boundaries and reports each tier's assignment rate, average bidder count,
+// and average client surplus (the gap between a task's reserve price and the
+// price it was actually won at).
+func computeTierMetricsFor(n Network, boundaries []float64) []TierMetrics {
+	byTask := bidsByTaskFor(n)
+	winningPrice := assignedTasksFor(n)
+
+	numTasks := make([]int, len(boundaries)+1)
+	numAssigned := make([]int, len(boundaries)+1)
+	totalBidders := make([]int, len(boundaries)+1)
+	surplusSum := make([]float64, len(boundaries)+1)
+	surplusCount := make([]int, len(boundaries)+1)
+
+	for _, node := range n.Nodes {
+		if node.Type != "issue" {
+			continue
+		}
+		tier := priceTier(boundaries, effectivePriceMin(node))
+		numTasks[tier]++
+		totalBidders[tier] += len(byTask[node.ID])
+		if price, ok := winningPrice[node.ID]; ok {
+			numAssigned[tier]++
+			if priceMax := effectivePriceMax(node); priceMax > 0 {
+				surplusSum[tier] += priceMax - price
+				surplusCount[tier]++
+			}
+		}
+	}
+
+	metrics := make([]TierMetrics, len(boundaries)+1)
+	for tier := range metrics {
+		m := TierMetrics{Tier: tierLabel(boundaries, tier), NumTasks: numTasks[tier]}
+		if numTasks[tier] > 0 {
+			m.AssignmentRate = float64(numAssigned[tier]) / float64(numTasks[tier])
+			m.AvgBidders = float64(totalBidders[tier]) / float64(numTasks[tier])
+		}
+		if surplusCount[tier] > 0 {
+			m.AvgClientSurplus = surplusSum[tier] / float64(surplusCount[tier])
+		}
+		metrics[tier] = m
+	}
+	return metrics
+}
+
+// computeTierMetrics is computeTierMetricsFor applied to the current network.
+func computeTierMetrics(boundaries []float64) []TierMetrics {
+	return computeTierMetricsFor(getNetwork(), boundaries)
+}
+
+// tierMetricsResponse is the body of /api/tier-metrics.
+type tierMetricsResponse struct {
+	Tiers     []TierMetrics  `json:"tiers"`
+	Component *componentInfo `json:"component,omitempty"`
+}
+
+// handleTierMetrics partitions task nodes into price tiers given by the
+// ?tiers= comma-separated ascending boundary list and reports per-tier
+// metrics. -giant-component or ?component=giant restricts the tasks
+// considered to the largest connected component.
+func handleTierMetrics(w http.ResponseWriter, r *http.Request) {
+	tiersParam := r.URL.Query().Get("tiers")
+	if tiersParam == "" {
+		http.Error(w, "missing required \"tiers\" query parameter", http.StatusBadRequest)
+		return
+	}
+	boundaries, err := parseTierBoundaries(tiersParam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	base, comp := networkForMetrics(getNetwork(), r)
+	w.Header().Set("Content-Type", "application/json")
+	resp := tierMetricsResponse{Tiers: computeTierMetricsFor(base, boundaries), Component: comp}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleTierMetrics] Failed to encode response: %v", err)
+	}
+}
+
+// staticFileSystem serves staticDir from disk when it exists, falling back
+// to the embedded default bundle otherwise.
+func staticFileSystem(staticDir string) (http.FileSystem, error) {
+	if info, err := os.Stat(staticDir); err == nil && info.IsDir() {
+		return http.Dir(staticDir), nil
+	}
+	sub, err := fs.Sub(embeddedStatic, "embedded_static")
+	if err != nil {
+		return nil, err
+	}
+	return http.FS(sub), nil
+}
+
+// reportThresholds holds the configurable pass/warn/fail cutoffs used by the
+// /report market report card. Gini and HHI are "higher is worse" (warn/fail
+// are ceilings); density is "lower is worse" (warn/fail are floors).
+type reportThresholds struct {
+	GiniWarn    float64
+	GiniFail    float64
+	DensityWarn float64
+	DensityFail float64
+	HHIWarn     float64
+	HHIFail     float64
+}
+
+var thresholds = reportThresholds{
+	GiniWarn:    0.4,
+	GiniFail:    0.6,
+	DensityWarn: 0.1,
+	DensityFail: 0.02,
+	HHIWarn:     1500,
+	HHIFail:     2500,
+}
+
+// giniCoefficient computes the Gini coefficient of values, a measure of
+// inequality ranging from 0 (perfectly even) to just under 1 (maximally
+// concentrated). Returns 0 for an empty or all-zero input.
+func giniCoefficient(values []float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	sorted := make([]float64, n)
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var weighted, sum float64
+	for i, v := range sorted {
+		weighted += float64(2*(i+1)-n-1) * v
+		sum += v
+	}
+	if sum == 0 {
+		return 0
+	}
+	return weighted / (float64(n) * sum)
+}
+
+// lorenzPoint is one vertex of a Lorenz curve: the cumulative share of the
+// population (agents, sorted ascending by value) against the cumulative
+// share of value they hold at that point. A curve that hugs the y=x
+// diagonal is perfectly equal; the further it bows below the diagonal, the
+// more concentrated the distribution - the same concentration
+// giniCoefficient summarizes as a single number.
+type lorenzPoint struct {
+	PopulationShare float64 `json:"population_share"`
+	ValueShare      float64 `json:"value_share"`
+}
+
+// calculateLorenzCurve computes the Lorenz curve of values: values sorted
+// ascending, plotted as cumulative population share against cumulative
+// value share, starting from the origin (0, 0). Returns just the origin
+// point for an empty input; every point has a 0 value share for an
+// all-zero input.
+func calculateLorenzCurve(values []float64) []lorenzPoint {
+	n := len(values)
+	points := make([]lorenzPoint, 0, n+1)
+	points = append(points, lorenzPoint{})
+	if n == 0 {
+		return points
+	}
+
+	sorted := make([]float64, n)
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var total float64
+	for _, v := range sorted {
+		total += v
+	}
+
+	var cumulative float64
+	for i, v := range sorted {
+		cumulative += v
+		point := lorenzPoint{PopulationShare: float64(i+1) / float64(n)}
+		if total > 0 {
+			point.ValueShare = cumulative / total
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// hhiIndex computes the Herfindahl-Hirschman Index of values, a standard
+// economic measure of market concentration: the sum of squared market
+// shares, scaled to the conventional 0-10000 range (10000 = a single value
+// holds the entire market, near 0 = many equally-sized values). Shares are
+// computed only over positive values; values <= 0 are excluded from both the
+// numerator and the total. Returns 0 for an empty or all-non-positive input.
+func hhiIndex(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		if v > 0 {
+			total += v
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	var sumSquares float64
+	for _, v := range values {
+		if v <= 0 {
+			continue
+		}
+		share := v / total
+		sumSquares += share * share
+	}
+	return sumSquares * 10000
+}
+
+// calculateTheilIndex computes Theil's T statistic of values, an
+// entropy-based inequality measure ranging from 0 (perfectly even) upward,
+// with no fixed ceiling. Unlike the Gini coefficient, Theil's T is exactly
+// decomposable into within-group and between-group components. Values equal
+// to 0 contribute 0 to the sum (the limit of x*ln(x) as x approaches 0).
+// Returns 0 for an empty, all-zero, or single-value input.
+func calculateTheilIndex(values []float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	if sum == 0 {
+		return 0
+	}
+	mean := sum / float64(n)
+
+	var theil float64
+	for _, v := range values {
+		if v <= 0 {
+			continue
+		}
+		ratio := v / mean
+		theil += ratio * math.Log(ratio)
+	}
+	return theil / float64(n)
+}
+
+// calculateAtkinsonIndex computes the Atkinson index of values, an
+// inequality measure ranging from 0 (perfectly even) to just under 1
+// (maximally concentrated). epsilon is the inequality aversion parameter:
+// higher epsilon weights the index more heavily toward the low end of the
+// distribution. Returns 0 for an empty, all-zero, or single-value input.
+func calculateAtkinsonIndex(values []float64, epsilon float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	if sum == 0 {
+		return 0
+	}
+	mean := sum / float64(n)
+
+	if epsilon == 1 {
+		var sumLog float64
+		for _, v := range values {
+			if v <= 0 {
+				return 1
+			}
+			sumLog += math.Log(v)
+		}
+		geometricMean := math.Exp(sumLog / float64(n))
+		return 1 - geometricMean/mean
+	}
+
+	var sumPow float64
+	for _, v := range values {
+		if v < 0 {
+			return 1
+		}
+		sumPow += math.Pow(v, 1-epsilon)
+	}
+	avgPow := sumPow / float64(n)
+	return 1 - math.Pow(avgPow, 1/(1-epsilon))/mean
+}
+
+// agentWonValuesFor totals the winning bid value each agent has captured in
+// n, one entry per agent that has won at least one task. This is the
+// distribution the report card's Gini coefficient measures inequality over.
+func agentWonValuesFor(n Network) []float64 {
+	winningPrice := assignedTasksFor(n)
+	totals := make(map[string]float64)
+	for _, e := range n.Edges {
+		if e.Type != "assigned" {
+			continue
+		}
+		if price, ok := winningPrice[e.Source]; ok {
+			totals[e.Target] += price
+		}
+	}
+	values := make([]float64, 0, len(totals))
+	for _, v := range totals {
+		values = append(values, v)
+	}
+	return values
+}
+
+// agentWonValuesByGroup totals the winning bid value each agent in n has
+// captured, same as agentWonValuesFor, but split into two distributions by
+// whether the agent has any declared specialities: specialist agents (at
+// least one speciality) versus generalist agents (none). This is the
+// grouping /api/gini-decomposition attributes inequality to.
+func agentWonValuesByGroup(n Network) (specialist, generalist []float64) {
+	winningPrice := assignedTasksFor(n)
+	totals := make(map[string]float64)
+	for _, e := range n.Edges {
+		if e.Type != "assigned" {
+			continue
+		}
+		if price, ok := winningPrice[e.Source]; ok {
+			totals[e.Target] += price
+		}
+	}
+	isSpecialist := make(map[string]bool, len(n.Nodes))
+	for _, node := range n.Nodes {
+		isSpecialist[node.ID] = len(node.Specialities) > 0
+	}
+	for agentID, total := range totals {
+		if isSpecialist[agentID] {
+			specialist = append(specialist, total)
+		} else {
+			generalist = append(generalist, total)
+		}
+	}
+	return specialist, generalist
+}
+
+// agentBidCountsFor counts how many bids each agent in n has placed, win or
+// lose, one entry per agent that has placed at least one bid. This is the
+// distribution /api/lorenz-curve?basis=bids measures inequality over.
+func agentBidCountsFor(n Network) []float64 {
+	counts := make(map[string]float64)
+	for _, e := range n.Edges {
+		if e.Type == "bid" {
+			counts[e.Source]++
+		}
+	}
+	values := make([]float64, 0, len(counts))
+	for _, v := range counts {
+		values = append(values, v)
+	}
+	return values
+}
+
+// agentBidValuesFor totals the value each agent in n has bid across every
+// bid it placed, win or lose, one entry per agent that has placed at least
+// one bid. This is the distribution /api/lorenz-curve?basis=bidvalue
+// measures inequality over.
+func agentBidValuesFor(n Network) []float64 {
+	totals := make(map[string]float64)
+	for _, e := range n.Edges {
+		if e.Type == "bid" {
+			totals[e.Source] += effectiveBidValue(e)
+		}
+	}
+	values := make([]float64, 0, len(totals))
+	for _, v := range totals {
+		values = append(values, v)
+	}
+	return values
+}
+
+// meanOf returns the arithmetic mean of values, or 0 for an empty input.
+func meanOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// giniDecomposition splits total inequality over won task value into
+// within-group and between-group components, using the standard subgroup
+// decomposition: each group's within contribution is its population share
+// times its income share times its own Gini coefficient, and the between
+// contribution is the Gini coefficient of the pseudo-distribution where
+// every agent's value is replaced by its group's mean. The three components
+// sum to Total exactly when the groups' value ranges don't overlap; an
+// overlap (residual) term is possible in the general case and isn't
+// reported here.
+type giniDecompositionResponse struct {
+	Total            float64 `json:"total"`
+	WithinSpecialist float64 `json:"within_specialist"`
+	WithinGeneralist float64 `json:"within_generalist"`
+	Between          float64 `json:"between"`
+}
+
+func giniSubgroupDecomposition(specialist, generalist []float64) giniDecompositionResponse {
+	all := make([]float64, 0, len(specialist)+len(generalist))
+	all = append(all, specialist...)
+	all = append(all, generalist...)
+	total := giniCoefficient(all)
+
+	n := float64(len(all))
+	overallMean := meanOf(all)
+	if n == 0 || overallMean == 0 {
+		return giniDecompositionResponse{Total: total}
+	}
+
+	ns, ng := float64(len(specialist)), float64(len(generalist))
+	meanS, meanG := meanOf(specialist), meanOf(generalist)
+
+	var withinSpecialist, withinGeneralist float64
+	if ns > 0 {
+		withinSpecialist = (ns / n) * (meanS / overallMean) * giniCoefficient(specialist)
+	}
+	if ng > 0 {
+		withinGeneralist = (ng / n) * (meanG / overallMean) * giniCoefficient(generalist)
+	}
+
+	pseudo := make([]float64, 0, len(all))
+	for range specialist {
+		pseudo = append(pseudo, meanS)
+	}
+	for range generalist {
+		pseudo = append(pseudo, meanG)
+	}
+	between := giniCoefficient(pseudo)
+
+	return giniDecompositionResponse{
+		Total:            total,
+		WithinSpecialist: withinSpecialist,
+		WithinGeneralist: withinGeneralist,
+		Between:          between,
+	}
+}
+
+// handleGiniDecomposition serves /api/gini-decomposition, splitting the
+// inequality agentWonValuesFor measures into within-specialist,
+// within-generalist, and between-group components, so a caller can tell
+// whether the market's inequality mainly reflects specialists dominating
+// generalists (a high between component) or steep inequality within one of
+// the two groups. -giant-component or ?component=giant restricts the
+// computation to the largest connected component.
+func handleGiniDecomposition(w http.ResponseWriter, r *http.Request) {
+	base, _ := networkForMetrics(getNetwork(), r)
+	specialist, generalist := agentWonValuesByGroup(base)
+	resp := giniSubgroupDecomposition(specialist, generalist)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleGiniDecomposition] Failed to encode response: %v", err)
+	}
+}
+
+// lorenzCurveResponse is the body of GET /api/lorenz-curve.
+type lorenzCurveResponse struct {
+	Basis string        `json:"basis"`
+	Gini  float64       `json:"gini"`
+	Curve []lorenzPoint `json:"curve"`
+}
+
+// handleLorenzCurve serves /api/lorenz-curve, the Lorenz curve and Gini
+// coefficient of one of three per-agent distributions selected by
+// ?basis=wins|bids|bidvalue: wins (agentWonValuesFor, the default,
+// matching the report card's Gini coefficient) counts only value an agent
+// actually won; bids (agentBidCountsFor) counts how many bids an agent
+// placed, win or lose; bidvalue (agentBidValuesFor) totals the value an
+// agent bid across every bid it placed, win or lose. -giant-component or
+// ?component=giant restricts the computation to the largest connected
+// component.
+func handleLorenzCurve(w http.ResponseWriter, r *http.Request) {
+	base, _ := networkForMetrics(getNetwork(), r)
+
+	basis := r.URL.Query().Get("basis")
+	if basis == "" {
+		basis = "wins"
+	}
+	var values []float64
+	switch basis {
+	case "wins":
+		values = agentWonValuesFor(base)
+	case "bids":
+		values = agentBidCountsFor(base)
+	case "bidvalue":
+		values = agentBidValuesFor(base)
+	default:
+		http.Error(w, fmt.Sprintf("invalid basis %q: must be wins, bids, or bidvalue", basis), http.StatusBadRequest)
+		return
+	}
+
+	resp := lorenzCurveResponse{
+		Basis: basis,
+		Gini:  giniCoefficient(values),
+		Curve: calculateLorenzCurve(values),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleLorenzCurve] Failed to encode response: %v", err)
+	}
+}
+
+// neighborLink is one edge connecting a node to a neighbor, from that node's
+// perspective: the neighbor's ID, the link type, its weight (BidValue for
+// "bid"/"auction" edges, the winning bid for "assigned" edges), and, only
+// for "assigned" edges, the winning bid value again as BidValue for
+// clarity to a caller not tracking edge-type semantics.
+type neighborLink struct {
+	NodeID   string  `json:"node_id"`
+	Type     string  `json:"type"`
+	Weight   float64 `json:"weight"`
+	BidValue float64 `json:"bid_value,omitempty"`
+}
+
+// neighborsResponse is the body of GET /api/neighbors?id=<node id>.
+type neighborsResponse struct {
+	ID       string         `json:"id"`
+	Incoming []neighborLink `json:"incoming"`
+	Outgoing []neighborLink `json:"outgoing"`
+}
+
+// neighborsFor returns id's directly-connected nodes in n, split into edges
+// where id is the target (Incoming) and edges where id is the source
+// (Outgoing), mirroring the D3 modal's click-to-expand logic server-side.
+func neighborsFor(n Network, id string) neighborsResponse {
+	winningBid := assignedTasksFor(n)
+	resp := neighborsResponse{ID: id, Incoming: make([]neighborLink, 0), Outgoing: make([]neighborLink, 0)}
+	for _, e := range n.Edges {
+		weight := effectiveBidValue(e)
+		if e.Type == "assigned" {
+			weight = winningBid[e.Source]
+		}
+		switch id {
+		case e.Target:
+			resp.Incoming = append(resp.Incoming, neighborLink{NodeID: e.Source, Type: e.Type, Weight: weight, BidValue: weight})
+		case e.Source:
+			resp.Outgoing = append(resp.Outgoing, neighborLink{NodeID: e.Target, Type: e.Type, Weight: weight, BidValue: weight})
+		}
+	}
+	return resp
+}
+
+// handleNeighbors serves GET /api/neighbors?id=<node id>, listing the node's
+// directly-connected nodes with the connecting link type, weight, and bid
+// value, split into incoming and outgoing - the primitive behind
+// click-to-expand exploration. Returns 404 for an id not present in the
+// network.
+func handleNeighbors(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing required \"id\" query parameter", http.StatusBadRequest)
+		return
+	}
+
+	base := getNetwork()
+	found := false
+	for _, n := range base.Nodes {
+		if n.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("unknown node id %q", id), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(neighborsFor(base, id)); err != nil {
+		log.Printf("[handleNeighbors] Failed to encode response: %v", err)
+	}
+}
+
+// policyAllocation maps a task ID to the bid edge that wins it under a
+// given allocation policy.
+type policyAllocation map[string]Edge
+
+// allocateLowestBid assigns every task in byTask to its cheapest bid.
+func allocateLowestBid(byTask map[string][]Edge) policyAllocation {
+	alloc := make(policyAllocation, len(byTask))
+	for taskID, bids := range byTask {
+		if len(bids) == 0 {
+			continue
+		}
+		best := bids[0]
+		for _, b := range bids[1:] {
+			if effectiveBidValue(b) < effectiveBidValue(best) {
+				best = b
+			}
+		}
+		alloc[taskID] = best
+	}
+	return alloc
+}
+
+// taskRequiredSpeciality maps every issue node in n with a declared
+// Speciality to that speciality's name.
+func taskRequiredSpeciality(n Network) map[string]string {
+	required := make(map[string]string)
+	for _, node := range n.Nodes {
+		if node.Type == "issue" && node.Speciality != nil {
+			required[node.ID] = node.Speciality.Name
+		}
+	}
+	return required
+}
+
+// agentSpecialityNames maps every agent node in n to the set of its
+// declared speciality names.
+func agentSpecialityNames(n Network) map[string]map[string]bool {
+	names := make(map[string]map[string]bool)
+	for _, node := range n.Nodes {
+		if node.Type != "agent" {
+			continue
+		}
+		set := make(map[string]bool, len(node.Specialities))
+		for _, s := range node.Specialities {
+			set[s.Name] = true
+		}
+		names[node.ID] = set
+	}
+	return names
+}
+
+// allocateSpecialistPreferred assigns every task in byTask to its cheapest
+// bid from an agent whose declared specialities include the task's required
+// speciality, falling back to the cheapest bid overall when no bidder is a
+// specialist match (or the task has no declared required speciality).
+func allocateSpecialistPreferred(n Network, byTask map[string][]Edge) policyAllocation {
+	required := taskRequiredSpeciality(n)
+	isSpecialistIn := agentSpecialityNames(n)
+
+	alloc := make(policyAllocation, len(byTask))
+	for taskID, bids := range byTask {
+		if len(bids) == 0 {
+			continue
+		}
+		var bestAny, bestSpecialist *Edge
+		for i := range bids {
+			b := &bids[i]
+			if bestAny == nil || effectiveBidValue(*b) < effectiveBidValue(*bestAny) {
+				bestAny = b
+			}
+			if req := required[taskID]; req != "" && isSpecialistIn[b.Source][req] {
+				if bestSpecialist == nil || effectiveBidValue(*b) < effectiveBidValue(*bestSpecialist) {
+					bestSpecialist = b
+				}
+			}
+		}
+		if bestSpecialist != nil {
+			alloc[taskID] = *bestSpecialist
+		} else {
+			alloc[taskID] = *bestAny
+		}
+	}
+	return alloc
+}
+
+// skillMismatchSurcharge inflates a non-specialist bid's effective cost in
+// allocateSkillOptimal's assignment problem, so the Hungarian solver
+// prefers a slightly pricier specialist bid over a cheaper mismatched one -
+// modeling the rework/risk cost of an out-of-specialty assignment.
+const skillMismatchSurcharge = 1.2
+
+// noBidCost stands in for a (agent, task) pair with no bid in
+// allocateSkillOptimal's cost matrix: high enough that the solver only picks
+// it when an agent or task has no real option left, and such picks are
+// discarded (see allocateSkillOptimal) since no bid backs them.
+const noBidCost = 1e12
+
+// allocateSkillOptimal solves the assignment problem of matching bidding
+// agents to tasks one-to-one so as to minimize total (skill-adjusted) cost,
+// via the Hungarian algorithm. Costs are each bid's value, surcharged by
+// skillMismatchSurcharge when the bidder isn't a declared specialist in the
+// task's required speciality; (agent, task) pairs without a bid are
+// penalized at noBidCost so the solver avoids them whenever a real
+// alternative exists. Because the Hungarian algorithm is strictly
+// one-to-one, this policy - unlike the other two - can leave a bidding
+// agent unassigned even when their bid was the cheapest on a task, if a
+// better global assignment exists.
+func allocateSkillOptimal(n Network, byTask map[string][]Edge) policyAllocation {
+	taskIDs := make([]string, 0, len(byTask))
+	for taskID := range byTask {
+		taskIDs = append(taskIDs, taskID)
+	}
+	sort.Strings(taskIDs)
+
+	agentSet := make(map[string]bool)
+	bidByPair := make(map[[2]string]Edge)
+	for taskID, bids := range byTask {
+		for _, b := range bids {
+			agentSet[b.Source] = true
+			bidByPair[[2]string{b.Source, taskID}] = b
+		}
+	}
+	agentIDs := make([]string, 0, len(agentSet))
+	for a := range agentSet {
+		agentIDs = append(agentIDs, a)
+	}
+	sort.Strings(agentIDs)
+
+	if len(taskIDs) == 0 || len(agentIDs) == 0 {
+		return policyAllocation{}
+	}
+
+	required := taskRequiredSpeciality(n)
+	isSpecialistIn := agentSpecialityNames(n)
+
+	dim := max(len(agentIDs), len(taskIDs))
+	cost := make([][]float64, dim)
+	for i := range cost {
+		cost[i] = make([]float64, dim)
+	}
+	for i, agentID := range agentIDs {
+		for j, taskID := range taskIDs {
+			b, ok := bidByPair[[2]string{agentID, taskID}]
+			if !ok {
+				cost[i][j] = noBidCost
+				continue
+			}
+			value := effectiveBidValue(b)
+			if req := required[taskID]; req != "" && !isSpecialistIn[agentID][req] {
+				value *= skillMismatchSurcharge
+			}
+			cost[i][j] = value
+		}
+	}
+	// Any padding rows/columns beyond the real agents/tasks stay at their
+	// zero value, acting as free "no assignment" slots so the shorter side
+	// doesn't force a real match onto the longer one.
+
+	colForRow := hungarianMinCostAssignment(cost)
+	alloc := make(policyAllocation, len(taskIDs))
+	for i, col := range colForRow {
+		if i >= len(agentIDs) || col >= len(taskIDs) {
+			continue // matched to a padding row/column: no real assignment
+		}
+		if b, ok := bidByPair[[2]string{agentIDs[i], taskIDs[col]}]; ok {
+			alloc[taskIDs[col]] = b
+		}
+	}
+	return alloc
+}
+
+// hungarianMinCostAssignment solves the square assignment problem for an
+// n x n cost matrix, returning colForRow such that colForRow[i] is the
+// column assigned to row i, minimizing total cost. This is the classical
+// O(n^3) Kuhn-Munkres algorithm via row/column potentials.
+func hungarianMinCostAssignment(cost [][]float64) []int {
+	n := len(cost)
+	const inf = math.MaxFloat64 / 2
+	u := make([]float64, n+1)
+	v := make([]float64, n+1)
+	p := make([]int, n+1) // p[j] = 1-indexed row matched to column j
+	way := make([]int, n+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = inf
+		}
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := inf
+			j1 := -1
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	colForRow := make([]int, n)
+	for j := 1; j <= n; j++ {
+		if p[j] != 0 {
+			colForRow[p[j]-1] = j - 1
+		}
+	}
+	return colForRow
+}
+
+// policyMetrics summarizes one allocation policy's realized outcome: how
+// much it cost in total, how often it favored a declared specialist, how
+// unequally the winnings landed across agents, and how much client budget
+// went unspent relative to each task's reserve price.
+type policyMetrics struct {
+	Policy            string  `json:"policy"`
+	TotalCost         float64 `json:"total_cost"`
+	SpecialistWinRate float64 `json:"specialist_win_rate"`
+	Gini              float64 `json:"gini"`
+	ClientSurplus     float64 `json:"client_surplus"`
+}
+
+// metricsForAllocation computes policyMetrics for alloc, a set of winning
+// bids produced by one of the allocate* policies over n.
+func metricsForAllocation(n Network, policyName string, alloc policyAllocation) policyMetrics {
+	taskNodes := make(map[string]Node, len(n.Nodes))
+	for _, node := range n.Nodes {
+		if node.Type == "issue" {
+			taskNodes[node.ID] = node
+		}
+	}
+	isSpecialistIn := agentSpecialityNames(n)
+
+	wonByAgent := make(map[string]float64, len(alloc))
+	var totalCost, clientSurplus float64
+	specialistWins := 0
+	for taskID, bid := range alloc {
+		value := effectiveBidValue(bid)
+		totalCost += value
+		wonByAgent[bid.Source] += value
+		task, ok := taskNodes[taskID]
+		if !ok {
+			continue
+		}
+		if reserve := effectivePriceMax(task); reserve > 0 {
+			clientSurplus += reserve - value
+		}
+		if task.Speciality != nil && isSpecialistIn[bid.Source][task.Speciality.Name] {
+			specialistWins++
+		}
+	}
+
+	wonValues := make([]float64, 0, len(wonByAgent))
+	for _, v := range wonByAgent {
+		wonValues = append(wonValues, v)
+	}
+	specialistWinRate := 0.0
+	if len(alloc) > 0 {
+		specialistWinRate = float64(specialistWins) / float64(len(alloc))
+	}
+
+	return policyMetrics{
+		Policy:            policyName,
+		TotalCost:         totalCost,
+		SpecialistWinRate: specialistWinRate,
+		Gini:              giniCoefficient(wonValues),
+		ClientSurplus:     clientSurplus,
+	}
+}
+
+// policyComparisonResponse is the body of /api/policy-comparison.
+type policyComparisonResponse struct {
+	Policies []policyMetrics `json:"policies"`
+}
+
+// handlePolicyComparison serves /api/policy-comparison: for the loaded
+// bids, it computes realized outcomes under three allocation policies -
+// lowest-bid, specialist-preferred, and skill-optimal (a Hungarian-solved
+// assignment) - so a caller can compare total cost, specialist win rate,
+// inequality, and client surplus across allocation rules without re-running
+// generation under each one. -giant-component or ?component=giant restricts
+// the comparison to the largest connected component.
+func handlePolicyComparison(w http.ResponseWriter, r *http.Request) {
+	base, _ := networkForMetrics(getNetwork(), r)
+	byTask := bidsByTaskFor(base)
+
+	resp := policyComparisonResponse{
+		Policies: []policyMetrics{
+			metricsForAllocation(base, "lowest-bid", allocateLowestBid(byTask)),
+			metricsForAllocation(base, "specialist-preferred", allocateSpecialistPreferred(base, byTask)),
+			metricsForAllocation(base, "skill-optimal", allocateSkillOptimal(base, byTask)),
+		},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handlePolicyComparison] Failed to encode response: %v", err)
+	}
+}
+
+// badgeFor classifies value against warn/fail thresholds as "pass", "warn",
+// or "fail". When higherIsWorse is true, values at or above fail/warn are
+// worse (e.g. Gini); otherwise values at or below fail/warn are worse (e.g.
+// density).
+func badgeFor(value, warn, fail float64, higherIsWorse bool) string {
+	if higherIsWorse {
+		switch {
+		case value >= fail:
+			return "fail"
+		case value >= warn:
+			return "warn"
+		default:
+			return "pass"
+		}
+	}
+	switch {
+	case value <= fail:
+		return "fail"
+	case value <= warn:
+		return "warn"
+	default:
+		return "pass"
+	}
+}
+
+// giniInterpretation renders a plain-language inequality label for badge.
+func giniInterpretation(badge string) string {
+	switch badge {
+	case "fail":
+		return "high inequality"
+	case "warn":
+		return "moderate inequality"
+	default:
+		return "low inequality"
+	}
+}
+
+// densityInterpretation renders a plain-language market-depth label for badge.
+func densityInterpretation(badge string) string {
+	switch badge {
+	case "fail":
+		return "very thin market"
+	case "warn":
+		return "thin market"
+	default:
+		return "healthy market depth"
+	}
+}
+
+// hhiInterpretation renders a plain-language market-concentration label for
+// badge, using the U.S. DOJ/FTC merger-guideline bands the default
+// HHIWarn/HHIFail thresholds are drawn from.
+func hhiInterpretation(badge string) string {
+	switch badge {
+	case "fail":
+		return "highly concentrated market"
+	case "warn":
+		return "moderately concentrated market"
+	default:
+		return "unconcentrated market"
+	}
+}
+
+// reportMetric is one row of the /report market report card.
+type reportMetric struct {
+	Name           string
+	Value          string
+	Interpretation string
+	Badge          string
+}
+
+// reportCardData is the template input for /report.
+type reportCardData struct {
+	NumNodes      int
+	NumEdges      int
+	Metrics       []reportMetric
+	ComponentNote string
+}
+
+// componentNote renders a human-readable note describing a giant-component
+// restriction, or "" when none applied.
+func componentNote(comp *componentInfo) string {
+	if comp == nil || !comp.Restricted {
+		return ""
+	}
+	return fmt.Sprintf("Restricted to the largest connected component: %d/%d nodes (%.1f%%)", comp.ComponentSize, comp.TotalNodes, comp.SizeFraction*100)
+}
+
+// buildReportCard summarizes n's headline market-health metrics against th
+// into the data a stakeholder-facing /report page renders.
+func buildReportCard(n Network, th reportThresholds) reportCardData {
+	gini := giniCoefficient(agentWonValuesFor(n))
+	giniBadge := badgeFor(gini, th.GiniWarn, th.GiniFail, true)
+
+	density := networkDensity(n)
+	densityBadge := badgeFor(density, th.DensityWarn, th.DensityFail, false)
+
+	hhi := hhiIndex(agentWonValuesFor(n))
+	hhiBadge := badgeFor(hhi, th.HHIWarn, th.HHIFail, true)
+
+	return reportCardData{
+		NumNodes: len(n.Nodes),
+		NumEdges: len(n.Edges),
+		Metrics: []reportMetric{
+			{
+				Name:           "Gini Coefficient",
+				Value:          strconv.FormatFloat(gini, 'f', 2, 64),
+				Interpretation: giniInterpretation(giniBadge),
+				Badge:          giniBadge,
+			},
+			{
+				Name:           "Network Density",
+				Value:          strconv.FormatFloat(density, 'f', 3, 64),
+				Interpretation: densityInterpretation(densityBadge),
+				Badge:          densityBadge,
+			},
+			{
+				Name:           "HHI",
+				Value:          strconv.FormatFloat(hhi, 'f', 0, 64),
+				Interpretation: hhiInterpretation(hhiBadge),
+				Badge:          hhiBadge,
+			},
+		},
+	}
+}
+
+// reportCardHTML is a printable, stakeholder-facing summary of headline
+// market-health metrics - unlike the interactive dashboard, it needs no
+// script to render and is meant to be glanced at or printed.
+const reportCardHTML = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Market Report Card</title>
+  <style>
+    body { font-family: Arial, sans-serif; background: #f8fafc; color: #1e293b; margin: 0; padding: 40px; }
+    h1 { color: #2563eb; }
+    .summary { color: #475569; margin-bottom: 24px; }
+    table { border-collapse: collapse; width: 100%; max-width: 700px; }
+    th, td { text-align: left; padding: 10px 14px; border-bottom: 1px solid #e2e8f0; }
+    .badge { display: inline-block; padding: 3px 10px; border-radius: 12px; font-weight: 600; font-size: 0.9em; color: #fff; }
+    .badge-pass { background: #16a34a; }
+    .badge-warn { background: #d97706; }
+    .badge-fail { background: #dc2626; }
+    @media print { body { background: #fff; } }
+  </style>
+</head>
+<body>
+  <h1>Market Report Card</h1>
+  <div class="summary">{{.NumNodes}} nodes, {{.NumEdges}} edges</div>
+  {{if .ComponentNote}}<div class="summary">{{.ComponentNote}}</div>{{end}}
+  <table>
+    <tr><th>Metric</th><th>Value</th><th>Interpretation</th><th>Status</th></tr>
+    {{range .Metrics}}
+    <tr>
+      <td>{{.Name}}</td>
+      <td>{{.Value}}</td>
+      <td>{{.Value}} &mdash; {{.Interpretation}}</td>
+      <td><span class="badge badge-{{.Badge}}">{{.Badge}}</span></td>
+    </tr>
+    {{end}}
+  </table>
+</body>
+</html>
+`
+
+// handleReport serves the /report market report card summarizing the
+// current network's headline metrics for stakeholders who don't want to
+// explore the interactive dashboard. -giant-component or ?component=giant
+// restricts the metrics to the largest connected component.
+func handleReport(w http.ResponseWriter, r *http.Request) {
+	base, comp := networkForMetrics(getNetwork(), r)
+	data := buildReportCard(base, thresholds)
+	data.ComponentNote = componentNote(comp)
+	tmpl := template.Must(template.New("report").Parse(reportCardHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Printf("[handleReport] Failed to execute template: %v", err)
+	}
+}
+
+// dataResponse is the body of /data.
+type dataResponse struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// handleData serves the full network exactly as loaded, regardless of any
+// -giant-component or ?component=giant restriction applied elsewhere: that
+// scoping affects aggregate metric computation, never the graph data
+// endpoint itself.
+func handleData(w http.ResponseWriter, r *http.Request) {
+	base := getNetwork()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dataResponse{Nodes: base.Nodes, Edges: base.Edges}); err != nil {
+		log.Printf("[handleData] Failed to encode response: %v", err)
+	}
+}
+
+// cytoscapeElement is a single Cytoscape.js graph element: a node or an edge,
+// with every field nested under "data" as Cytoscape.js requires.
+type cytoscapeElement struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// cytoscapeResponse is the body of /api/cytoscape, matching the
+// {elements:{nodes:[...],edges:[...]}} shape Cytoscape.js expects.
+type cytoscapeResponse struct {
+	Elements struct {
+		Nodes []cytoscapeElement `json:"nodes"`
+		Edges []cytoscapeElement `json:"edges"`
+	} `json:"elements"`
+}
+
+// jsonFieldsOf round-trips v through JSON so every one of its JSON-tagged
+// fields survives into a plain map, ready to embed as a Cytoscape.js
+// element's "data" object.
+func jsonFieldsOf(v interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// buildCytoscapeResponse transforms n into Cytoscape.js's element format.
+// Nodes already carry an "id" field; edges get a synthesized stable id of
+// "source_target_type" since Cytoscape.js requires one and n's edges don't
+// otherwise have one. Every other field is preserved as-is so client-side
+// styling (e.g. by bid_value) keeps working.
+func buildCytoscapeResponse(n Network) (cytoscapeResponse, error) {
+	var resp cytoscapeResponse
+	for _, node := range n.Nodes {
+		data, err := jsonFieldsOf(node)
+		if err != nil {
+			return cytoscapeResponse{}, err
+		}
+		resp.Elements.Nodes = append(resp.Elements.Nodes, cytoscapeElement{Data: data})
+	}
+	for _, e := range n.Edges {
+		data, err := jsonFieldsOf(e)
+		if err != nil {
+			return cytoscapeResponse{}, err
+		}
+		data["id"] = fmt.Sprintf("%s_%s_%s", e.Source, e.Target, e.Type)
+		resp.Elements.Edges = append(resp.Elements.Edges, cytoscapeElement{Data: data})
+	}
+	return resp, nil
+}
+
+// handleCytoscape serves the current network in Cytoscape.js's element
+// format, leaving /data untouched for consumers that expect the original
+// shape.
+func handleCytoscape(w http.ResponseWriter, r *http.Request) {
+	resp, err := buildCytoscapeResponse(getNetwork())
+	if err != nil {
+		log.Printf("[handleCytoscape] Failed to build response: %v", err)
+		http.Error(w, "failed to build response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleCytoscape] Failed to encode response: %v", err)
+	}
+}
+
+// topEdge is one row of the /api/top-edges response: an edge plus its
+// endpoints' resolved display names.
+type topEdge struct {
+	Source     string  `json:"source"`
+	SourceName string  `json:"source_name"`
+	Target     string  `json:"target"`
+	TargetName string  `json:"target_name"`
+	Type       string  `json:"type"`
+	Value      float64 `json:"value"`
+}
+
+// topEdgesResponse is the body of /api/top-edges.
+type topEdgesResponse struct {
+	SortBy string    `json:"sort_by"`
+	Edges  []topEdge `json:"edges"`
+}
+
+// topEdgesFor returns the top limit edges of n (optionally restricted to
+// edgeType), ranked by sortBy: "weight" uses the edge's own BidValue,
+// "winning_bid" uses the winning bid price of the task an "assigned" edge
+// represents (0 for edges that aren't assignments). Ties break by source,
+// then target, then type, so the result is deterministic. limit < 0 returns
+// every matching edge.
+func topEdgesFor(n Network, edgeType string, sortBy string, limit int) []topEdge {
+	names := make(map[string]string, len(n.Nodes))
+	for _, node := range n.Nodes {
+		names[node.ID] = node.Label
+	}
+	winningBid := assignedTasksFor(n)
+
+	valueOf := func(e Edge) float64 {
+		if sortBy == "winning_bid" {
+			if e.Type != "assigned" {
+				return 0
+			}
+			return winningBid[e.Source]
+		}
+		return e.BidValue
+	}
+
+	var candidates []topEdge
+	for _, e := range n.Edges {
+		if edgeType != "" && e.Type != edgeType {
+			continue
+		}
+		candidates = append(candidates, topEdge{
+			Source:     e.Source,
+			SourceName: names[e.Source],
+			Target:     e.Target,
+			TargetName: names[e.Target],
+			Type:       e.Type,
+			Value:      valueOf(e),
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Value != candidates[j].Value {
+			return candidates[i].Value > candidates[j].Value
+		}
+		if candidates[i].Source != candidates[j].Source {
+			return candidates[i].Source < candidates[j].Source
+		}
+		if candidates[i].Target != candidates[j].Target {
+			return candidates[i].Target < candidates[j].Target
+		}
+		return candidates[i].Type < candidates[j].Type
+	})
+
+	if limit >= 0 && limit < len(candidates) {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// validateNetworkReferences returns a human-readable line for every edge
+// whose source or target doesn't reference an existing node ID in n, so an
+// upload with dangling references can be rejected with specifics instead of
+// a generic error.
+func validateNetworkReferences(n Network) []string {
+	ids := make(map[string]bool, len(n.Nodes))
+	for _, node := range n.Nodes {
+		ids[node.ID] = true
+	}
+	var dangling []string
+	for _, e := range n.Edges {
+		if !ids[e.Source] {
+			dangling = append(dangling, fmt.Sprintf("edge %s -> %s (%s): unknown source %q", e.Source, e.Target, e.Type, e.Source))
+		}
+		if !ids[e.Target] {
+			dangling = append(dangling, fmt.Sprintf("edge %s -> %s (%s): unknown target %q", e.Source, e.Target, e.Type, e.Target))
+		}
+	}
+	return dangling
+}
+
+// loadNetworkResponse is the 200 body of POST /api/load.
+type loadNetworkResponse struct {
+	Nodes int `json:"nodes"`
+	Edges int `json:"edges"`
+}
+
+// loadNetworkErrorResponse is the 400 body of POST /api/load.
+type loadNetworkErrorResponse struct {
+	Error         string   `json:"error"`
+	DanglingLinks []string `json:"dangling_links,omitempty"`
+}
+
+// handleLoadNetwork accepts POST /api/load with a Network JSON body (the
+// same shape /data returns), validates that every edge's source and target
+// reference an existing node, and atomically swaps it in as the live
+// network on success - so a client can script a comparison loop across
+// several generated networks via curl without restarting the server.
+func handleLoadNetwork(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var uploaded Network
+	if err := json.NewDecoder(r.Body).Decode(&uploaded); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(loadNetworkErrorResponse{Error: fmt.Sprintf("invalid JSON body: %v", err)})
+		return
+	}
+
+	if dangling := validateNetworkReferences(uploaded); len(dangling) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(loadNetworkErrorResponse{
+			Error:         "network has dangling edge references",
+			DanglingLinks: dangling,
+		})
+		return
+	}
+
+	reconcileDegrees(&uploaded, false)
+	normalizePrices(&uploaded, priceNormalization)
+
+	setNetwork(uploaded)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(loadNetworkResponse{Nodes: len(uploaded.Nodes), Edges: len(uploaded.Edges)}); err != nil {
+		log.Printf("[handleLoadNetwork] Failed to encode response: %v", err)
+	}
+}
+
+// networkDiffLinkKey identifies a link across two networks by
+// source+target+type, since that's the only stable identity a link has -
+// neither graph carries its own link IDs.
+type networkDiffLinkKey struct {
+	Source string
+	Target string
+	Type   string
+}
+
+// networkDiffLinkChange is a link present in both networks whose
+// winning_bid or weight changed between them.
+type networkDiffLinkChange struct {
+	Source           string  `json:"source"`
+	Target           string  `json:"target"`
+	Type             string  `json:"type"`
+	WeightBefore     float64 `json:"weight_before"`
+	WeightAfter      float64 `json:"weight_after"`
+	WinningBidBefore float64 `json:"winning_bid_before"`
+	WinningBidAfter  float64 `json:"winning_bid_after"`
+}
+
+// networkDiffResponse is the body of POST /api/diff.
+type networkDiffResponse struct {
+	NodesAdded   []Node                  `json:"nodes_added"`
+	NodesRemoved []Node                  `json:"nodes_removed"`
+	LinksAdded   []Edge                  `json:"links_added"`
+	LinksRemoved []Edge                  `json:"links_removed"`
+	LinksChanged []networkDiffLinkChange `json:"links_changed"`
+}
+
+func sortNodesByID(nodes []Node) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+}
+
+func sortEdgesBySourceTargetType(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		if edges[i].Target != edges[j].Target {
+			return edges[i].Target < edges[j].Target
+		}
+		return edges[i].Type < edges[j].Type
+	})
+}
+
+// diffNetworks compares before against after, keying nodes by ID and links
+// by source+target+type. A link's weight is its effectiveBidValue; its
+// winning_bid is assignedTasksFor's resolved winning price when the link is
+// an "assigned" edge, else 0. Every slice is sorted for a stable response.
+func diffNetworks(before, after Network) networkDiffResponse {
+	beforeNodes := make(map[string]Node, len(before.Nodes))
+	for _, n := range before.Nodes {
+		beforeNodes[n.ID] = n
+	}
+	afterNodes := make(map[string]Node, len(after.Nodes))
+	for _, n := range after.Nodes {
+		afterNodes[n.ID] = n
+	}
+
+	resp := networkDiffResponse{
+		NodesAdded:   make([]Node, 0),
+		NodesRemoved: make([]Node, 0),
+		LinksAdded:   make([]Edge, 0),
+		LinksRemoved: make([]Edge, 0),
+		LinksChanged: make([]networkDiffLinkChange, 0),
+	}
+	for id, n := range afterNodes {
+		if _, ok := beforeNodes[id]; !ok {
+			resp.NodesAdded = append(resp.NodesAdded, n)
+		}
+	}
+	for id, n := range beforeNodes {
+		if _, ok := afterNodes[id]; !ok {
+			resp.NodesRemoved = append(resp.NodesRemoved, n)
+		}
+	}
+	sortNodesByID(resp.NodesAdded)
+	sortNodesByID(resp.NodesRemoved)
+
+	beforeWinningBid := assignedTasksFor(before)
+	afterWinningBid := assignedTasksFor(after)
+	winningBidOf := func(winningBid map[string]float64, e Edge) float64 {
+		if e.Type != "assigned" {
+			return 0
+		}
+		return winningBid[e.Source]
+	}
+
+	beforeEdges := make(map[networkDiffLinkKey]Edge, len(before.Edges))
+	for _, e := range before.Edges {
+		beforeEdges[networkDiffLinkKey{e.Source, e.Target, e.Type}] = e
+	}
+	afterEdges := make(map[networkDiffLinkKey]Edge, len(after.Edges))
+	for _, e := range after.Edges {
+		afterEdges[networkDiffLinkKey{e.Source, e.Target, e.Type}] = e
+	}
+
+	for key, e := range afterEdges {
+		if _, ok := beforeEdges[key]; !ok {
+			resp.LinksAdded = append(resp.LinksAdded, e)
+		}
+	}
+	for key, e := range beforeEdges {
+		if _, ok := afterEdges[key]; !ok {
+			resp.LinksRemoved = append(resp.LinksRemoved, e)
+		}
+	}
+	for key, beforeEdge := range beforeEdges {
+		afterEdge, ok := afterEdges[key]
+		if !ok {
+			continue
+		}
+		weightBefore, weightAfter := effectiveBidValue(beforeEdge), effectiveBidValue(afterEdge)
+		winBefore, winAfter := winningBidOf(beforeWinningBid, beforeEdge), winningBidOf(afterWinningBid, afterEdge)
+		if weightBefore == weightAfter && winBefore == winAfter {
+			continue
+		}
+		resp.LinksChanged = append(resp.LinksChanged, networkDiffLinkChange{
+			Source: key.Source, Target: key.Target, Type: key.Type,
+			WeightBefore: weightBefore, WeightAfter: weightAfter,
+			WinningBidBefore: winBefore, WinningBidAfter: winAfter,
+		})
+	}
+	sortEdgesBySourceTargetType(resp.LinksAdded)
+	sortEdgesBySourceTargetType(resp.LinksRemoved)
+	sort.Slice(resp.LinksChanged, func(i, j int) bool {
+		a, b := resp.LinksChanged[i], resp.LinksChanged[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Type < b.Type
+	})
+
+	return resp
+}
+
+// diffRequest is the body of POST /api/diff. Before defaults to the live
+// network when omitted; BaselinePath, when set, loads Before from an
+// on-disk network JSON file instead (e.g. a saved baseline to compare a
+// freshly-generated network against). After defaults to the live network
+// when omitted, so a client that only wants "what changed from a saved
+// baseline to what's currently loaded" can send just baseline_path.
+type diffRequest struct {
+	Before       *Network `json:"before,omitempty"`
+	After        *Network `json:"after,omitempty"`
+	BaselinePath string   `json:"baseline_path,omitempty"`
+}
+
+// handleDiff serves POST /api/diff: given two network payloads (or a
+// baseline_path plus the live network), it reports which nodes and links
+// were added or removed, and which links kept their identity but changed
+// weight or winning_bid.
+func handleDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req diffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Before != nil && req.BaselinePath != "" {
+		http.Error(w, "specify either \"before\" or \"baseline_path\", not both", http.StatusBadRequest)
+		return
+	}
+
+	before := getNetwork()
+	if req.BaselinePath != "" {
+		raw, err := os.ReadFile(req.BaselinePath)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read baseline_path: %v", err), http.StatusBadRequest)
+			return
+		}
+		var baseline Network
+		if err := unmarshalJSONWithContext(req.BaselinePath, raw, &baseline); err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse baseline_path: %v", err), http.StatusBadRequest)
+			return
+		}
+		before = baseline
+	} else if req.Before != nil {
+		before = *req.Before
+	}
+
+	after := getNetwork()
+	if req.After != nil {
+		after = *req.After
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(diffNetworks(before, after)); err != nil {
+		log.Printf("[handleDiff] Failed to encode response: %v", err)
+	}
+}
+
+// handleTopEdges serves /api/top-edges?n=20&type=assigned&sort_by=weight,
+// the highest-value edges for a "key relationships" panel.
+func handleTopEdges(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if raw := r.URL.Query().Get("n"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			http.Error(w, "invalid \"n\" query parameter", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	sortBy := r.URL.Query().Get("sort_by")
+	if sortBy == "" {
+		sortBy = "weight"
+	}
+	if sortBy != "weight" && sortBy != "winning_bid" {
+		http.Error(w, "invalid \"sort_by\" query parameter: must be weight or winning_bid", http.StatusBadRequest)
+		return
+	}
+
+	resp := topEdgesResponse{
+		SortBy: sortBy,
+		Edges:  topEdgesFor(getNetwork(), r.URL.Query().Get("type"), sortBy, limit),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleTopEdges] Failed to encode response: %v", err)
+	}
+}
+
+// betweennessNode is one row of the /api/betweenness response.
+type betweennessNode struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Role        string  `json:"role"`
+	Betweenness float64 `json:"betweenness"`
+}
+
+// betweennessCentrality computes each node's betweenness centrality in n
+// using Brandes' algorithm over the undirected projection of n's edges
+// (direction and type are ignored). Shortest-path contributions only ever
+// accumulate between nodes that can actually reach one another, so
+// disconnected components contribute independently rather than being
+// treated as infinitely far apart - and a node with degree zero simply
+// contributes and receives nothing, appearing with betweenness 0 rather
+// than being dropped. Raw scores are normalized by (n-1)(n-2)/2, the
+// maximum possible score in an undirected graph of n nodes, so values are
+// comparable across graphs of different sizes.
+func betweennessCentrality(n Network) map[string]float64 {
+	adjacency := make(map[string]map[string]bool, len(n.Nodes))
+	for _, node := range n.Nodes {
+		adjacency[node.ID] = map[string]bool{}
+	}
+	for _, e := range n.Edges {
+		if _, ok := adjacency[e.Source]; !ok {
+			continue
+		}
+		if _, ok := adjacency[e.Target]; !ok {
+			continue
+		}
+		adjacency[e.Source][e.Target] = true
+		adjacency[e.Target][e.Source] = true
+	}
+
+	betweenness := make(map[string]float64, len(n.Nodes))
+	for _, node := range n.Nodes {
+		betweenness[node.ID] = 0
+	}
+
+	for _, s := range n.Nodes {
+		var stack []string
+		predecessors := make(map[string][]string)
+		sigma := map[string]float64{s.ID: 1}
+		dist := map[string]int{s.ID: 0}
+		queue := []string{s.ID}
+
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			neighbors := make([]string, 0, len(adjacency[v]))
+			for w := range adjacency[v] {
+				neighbors = append(neighbors, w)
+			}
+			sort.Strings(neighbors)
+			for _, w := range neighbors {
+				if _, seen := dist[w]; !seen {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					predecessors[w] = append(predecessors[w], v)
+				}
+			}
+		}
+
+		delta := make(map[string]float64)
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range predecessors[w] {
+				delta[v] += (sigma[v] / sigma[w]) * (1 + delta[w])
+			}
+			if w != s.ID {
+				betweenness[w] += delta[w]
+			}
+		}
+	}
+
+	numNodes := len(n.Nodes)
+	norm := float64((numNodes-1)*(numNodes-2)) / 2
+	for id := range betweenness {
+		// Every shortest path between a pair is discovered once from each
+		// endpoint in this undirected projection, so halve the raw total
+		// before normalizing.
+		betweenness[id] /= 2
+		if norm > 0 {
+			betweenness[id] /= norm
+		}
+	}
+	return betweenness
+}
+
+// handleBetweenness serves each node's normalized betweenness centrality,
+// sorted descending, identifying broker agents/tasks that sit on many
+// shortest paths between others. -giant-component or ?component=giant
+// restricts the computation to the largest connected component.
+func handleBetweenness(w http.ResponseWriter, r *http.Request) {
+	base, _ := networkForMetrics(getNetwork(), r)
+	scores := betweennessCentrality(base)
+
+	rows := make([]betweennessNode, 0, len(base.Nodes))
+	for _, node := range base.Nodes {
+		rows = append(rows, betweennessNode{ID: node.ID, Name: node.Label, Role: node.Type, Betweenness: scores[node.ID]})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Betweenness != rows[j].Betweenness {
+			return rows[i].Betweenness > rows[j].Betweenness
+		}
+		return rows[i].ID < rows[j].ID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rows); err != nil {
+		log.Printf("[handleBetweenness] Failed to encode response: %v", err)
+	}
+}
+
+// closenessNode is one row of the /api/closeness response.
+type closenessNode struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Role          string  `json:"role"`
+	Closeness     float64 `json:"closeness"`
+	ComponentSize int     `json:"component_size"`
+}
+
+// closenessCentrality computes each node's closeness centrality in n via a
+// BFS from every node over the undirected projection of n's edges (direction
+// and type are ignored, matching betweennessCentrality). Plain closeness -
+// (reachable-1)/sum_of_distances - would score a node in a small isolated
+// pair as perfectly central, so this applies the Wasserman-Faust correction,
+// scaling by the fraction of the whole graph the node can actually reach:
+// (reachable-1)/(N-1) * (reachable-1)/sum_of_distances. A node with no
+// reachable neighbors gets closeness 0 rather than dividing by zero.
+func closenessCentrality(n Network) map[string]float64 {
+	adjacency := make(map[string]map[string]bool, len(n.Nodes))
+	for _, node := range n.Nodes {
+		adjacency[node.ID] = map[string]bool{}
+	}
+	for _, e := range n.Edges {
+		if _, ok := adjacency[e.Source]; !ok {
+			continue
+		}
+		if _, ok := adjacency[e.Target]; !ok {
+			continue
+		}
+		adjacency[e.Source][e.Target] = true
+		adjacency[e.Target][e.Source] = true
+	}
+
+	closeness := make(map[string]float64, len(n.Nodes))
+	numNodes := len(n.Nodes)
+	for _, s := range n.Nodes {
+		dist := map[string]int{s.ID: 0}
+		queue := []string{s.ID}
+		sumDist := 0
+		reachable := 1
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			neighbors := make([]string, 0, len(adjacency[v]))
+			for w := range adjacency[v] {
+				neighbors = append(neighbors, w)
+			}
+			sort.Strings(neighbors)
+			for _, w := range neighbors {
+				if _, seen := dist[w]; !seen {
+					dist[w] = dist[v] + 1
+					sumDist += dist[w]
+					reachable++
+					queue = append(queue, w)
+				}
+			}
+		}
+
+		var score float64
+		if sumDist > 0 && numNodes > 1 {
+			score = (float64(reachable-1) / float64(sumDist)) * (float64(reachable-1) / float64(numNodes-1))
+		}
+		closeness[s.ID] = score
+	}
+	return closeness
+}
+
+// componentSizesFor maps each node ID in n to the size of its connected
+// component, using the same undirected reachability as closenessCentrality.
+func componentSizesFor(n Network) map[string]int {
+	sizes := make(map[string]int, len(n.Nodes))
+	for _, component := range connectedComponents(n) {
+		for _, id := range component {
+			sizes[id] = len(component)
+		}
+	}
+	return sizes
+}
+
+// handleCloseness serves each node's Wasserman-Faust-corrected closeness
+// centrality alongside the size of the connected component it belongs to.
+// -giant-component or ?component=giant restricts the computation to the
+// largest connected component.
+func handleCloseness(w http.ResponseWriter, r *http.Request) {
+	base, _ := networkForMetrics(getNetwork(), r)
+	scores := closenessCentrality(base)
+	sizes := componentSizesFor(base)
+
+	rows := make([]closenessNode, 0, len(base.Nodes))
+	for _, node := range base.Nodes {
+		rows = append(rows, closenessNode{
+			ID:            node.ID,
+			Name:          node.Label,
+			Role:          node.Type,
+			Closeness:     scores[node.ID],
+			ComponentSize: sizes[node.ID],
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Closeness != rows[j].Closeness {
+			return rows[i].Closeness > rows[j].Closeness
+		}
+		return rows[i].ID < rows[j].ID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rows); err != nil {
+		log.Printf("[handleCloseness] Failed to encode response: %v", err)
+	}
+}
+
+const (
+	defaultPageRankDamping    = 0.85
+	defaultPageRankIterations = 100
+	pageRankConvergence       = 1e-6
+)
+
+// pageRankNode is one row of the /api/pagerank response.
+type pageRankNode struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Role     string  `json:"role"`
+	PageRank float64 `json:"pagerank"`
+}
+
+// pageRank computes PageRank over n's edges treated as directed
+// source->target links (an "assigned" task->agent edge and a "bid"
+// agent->task edge each carry rank in their own direction, unlike
+// betweennessCentrality/closenessCentrality which ignore direction).
+// Dangling nodes - those with no outgoing edges, typically unassigned
+// tasks - redistribute their rank uniformly across every node rather than
+// leaking it out of the system. Iteration stops early once the L1 delta
+// between successive ranks drops below 1e-6, or after iterations rounds.
+// The result is normalized so all scores sum to 1.
+func pageRank(n Network, damping float64, iterations int) map[string]float64 {
+	numNodes := len(n.Nodes)
+	if numNodes == 0 {
+		return map[string]float64{}
+	}
+
+	outLinks := make(map[string][]string, numNodes)
+	for _, node := range n.Nodes {
+		outLinks[node.ID] = nil
+	}
+	for _, e := range n.Edges {
+		if _, ok := outLinks[e.Source]; !ok {
+			continue
+		}
+		if _, ok := outLinks[e.Target]; !ok {
+			continue
+		}
+		outLinks[e.Source] = append(outLinks[e.Source], e.Target)
+	}
+
+	ids := make([]string, 0, numNodes)
+	for _, node := range n.Nodes {
+		ids = append(ids, node.ID)
+	}
+	sort.Strings(ids)
+
+	rank := make(map[string]float64, numNodes)
+	for _, id := range ids {
+		rank[id] = 1 / float64(numNodes)
+	}
+
+	base := (1 - damping) / float64(numNodes)
+	for iter := 0; iter < iterations; iter++ {
+		next := make(map[string]float64, numNodes)
+		danglingSum := 0.0
+		for _, id := range ids {
+			if len(outLinks[id]) == 0 {
+				danglingSum += rank[id]
+			}
+			next[id] = base
+		}
+		danglingShare := damping * danglingSum / float64(numNodes)
+		for _, id := range ids {
+			next[id] += danglingShare
+		}
+		for _, id := range ids {
+			out := outLinks[id]
+			if len(out) == 0 {
+				continue
+			}
+			share := damping * rank[id] / float64(len(out))
+			for _, target := range out {
+				next[target] += share
+			}
+		}
+
+		delta := 0.0
+		for _, id := range ids {
+			delta += math.Abs(next[id] - rank[id])
+		}
+		rank = next
+		if delta < pageRankConvergence {
+			break
+		}
+	}
+
+	total := 0.0
+	for _, v := range rank {
+		total += v
+	}
+	if total > 0 {
+		for id := range rank {
+			rank[id] /= total
+		}
+	}
+	return rank
+}
+
+// handlePageRank serves each node's PageRank, sorted descending. ?damping
+// overrides the damping factor (default 0.85, applied when the query param
+// is absent or invalid) and ?iters caps the iteration count (default 100).
+func handlePageRank(w http.ResponseWriter, r *http.Request) {
+	damping := defaultPageRankDamping
+	if raw := r.URL.Query().Get("damping"); raw != "" {
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			damping = v
+		}
+	}
+	iterations := defaultPageRankIterations
+	if raw := r.URL.Query().Get("iters"); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil {
+			iterations = v
+		}
+	}
+
+	base, _ := networkForMetrics(getNetwork(), r)
+	scores := pageRank(base, damping, iterations)
+
+	rows := make([]pageRankNode, 0, len(base.Nodes))
+	for _, node := range base.Nodes {
+		rows = append(rows, pageRankNode{ID: node.ID, Name: node.Label, Role: node.Type, PageRank: scores[node.ID]})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].PageRank != rows[j].PageRank {
+			return rows[i].PageRank > rows[j].PageRank
+		}
+		return rows[i].ID < rows[j].ID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rows); err != nil {
+		log.Printf("[handlePageRank] Failed to encode response: %v", err)
+	}
+}
+
+// whatIfRequest is the body of POST /api/what-if: nodes to add and node IDs
+// to remove, previewed against a copy of the loaded graph without mutating
+// it.
+type whatIfRequest struct {
+	Add    []Node   `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+// whatIfMetrics is a snapshot of the headline market-health metrics used to
+// compare a what-if scenario's before/after state.
+type whatIfMetrics struct {
+	NumNodes int     `json:"num_nodes"`
+	NumEdges int     `json:"num_edges"`
+	Density  float64 `json:"density"`
+	Gini     float64 `json:"gini"`
+}
+
+// whatIfResponse is the body of POST /api/what-if.
+type whatIfResponse struct {
+	Before whatIfMetrics `json:"before"`
+	After  whatIfMetrics `json:"after"`
+}
+
+func snapshotWhatIfMetrics(n Network) whatIfMetrics {
+	return whatIfMetrics{
+		NumNodes: len(n.Nodes),
+		NumEdges: len(n.Edges),
+		Density:  networkDensity(n),
+		Gini:     giniCoefficient(agentWonValuesFor(n)),
+	}
+}
+
+// applyWhatIf returns a copy of n with req.Remove's nodes (and every edge
+// touching them) deleted, followed by req.Add's nodes appended. It does not
+// mutate n.
+func applyWhatIf(n Network, req whatIfRequest) Network {
+	removed := make(map[string]bool, len(req.Remove))
+	for _, id := range req.Remove {
+		removed[id] = true
+	}
+
+	out := Network{
+		Nodes: make([]Node, 0, len(n.Nodes)+len(req.Add)),
+		Edges: make([]Edge, 0, len(n.Edges)),
+	}
+	for _, node := range n.Nodes {
+		if !removed[node.ID] {
+			out.Nodes = append(out.Nodes, node)
+		}
+	}
+	for _, e := range n.Edges {
+		if removed[e.Source] || removed[e.Target] {
+			continue
+		}
+		out.Edges = append(out.Edges, e)
+	}
+	out.Nodes = append(out.Nodes, req.Add...)
+	return out
+}
+
+// handleWhatIf previews the metric impact of adding and/or removing agents
+// or tasks, without touching the loaded network: it validates the request,
+// applies it to a copy of the graph, and returns the before/after density
+// and Gini coefficient.
+func handleWhatIf(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req whatIfRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	base := getNetwork()
+	existing := make(map[string]bool, len(base.Nodes))
+	for _, node := range base.Nodes {
+		existing[node.ID] = true
+	}
+	for _, id := range req.Remove {
+		if !existing[id] {
+			http.Error(w, fmt.Sprintf("cannot remove unknown node id %q", id), http.StatusBadRequest)
+			return
+		}
+	}
+	seenAdd := make(map[string]bool, len(req.Add))
+	for _, node := range req.Add {
+		if node.ID == "" {
+			http.Error(w, "added node is missing an id", http.StatusBadRequest)
+			return
+		}
+		if node.Type != "agent" && node.Type != "issue" {
+			http.Error(w, fmt.Sprintf("added node %q has invalid type %q, want \"agent\" or \"issue\"", node.ID, node.Type), http.StatusBadRequest)
+			return
+		}
+		if existing[node.ID] || seenAdd[node.ID] {
+			http.Error(w, fmt.Sprintf("added node id %q already exists in the network", node.ID), http.StatusBadRequest)
+			return
+		}
+		seenAdd[node.ID] = true
+	}
+
+	after := applyWhatIf(base, req)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(whatIfResponse{
+		Before: snapshotWhatIfMetrics(base),
+		After:  snapshotWhatIfMetrics(after),
+	}); err != nil {
+		log.Printf("[handleWhatIf] Failed to encode response: %v", err)
+	}
+}
+
+// clusteringCoefficient measures how tightly agents cluster around shared
+// tasks. The underlying graph is bipartite (agents and tasks only connect to
+// each other, never to their own kind), so clustering is computed over the
+// one-mode projection onto agent nodes: two agents are linked in the
+// projection if they're both connected (by any edge, bid or assigned) to at
+// least one common task. Local coefficients are the standard Watts-Strogatz
+// ratio - 2*(links among an agent's projected neighbors)/(k*(k-1)) - with
+// agents of projected degree < 2 fixed at 0 rather than dividing by zero.
+// The second return value is the unweighted mean of every agent's local
+// coefficient (task nodes and agents with no shared-task neighbors count as
+// 0 in that average).
+func clusteringCoefficient(n Network) (map[string]float64, float64) {
+	agentsByTask := make(map[string]map[string]bool)
+	isAgent := make(map[string]bool)
+	for _, node := range n.Nodes {
+		if node.Type == "agent" {
+			isAgent[node.ID] = true
+		}
+	}
+	for _, e := range n.Edges {
+		var agentID, taskID string
+		switch {
+		case isAgent[e.Source]:
+			agentID, taskID = e.Source, e.Target
+		case isAgent[e.Target]:
+			agentID, taskID = e.Target, e.Source
+		default:
+			continue
+		}
+		if agentsByTask[taskID] == nil {
+			agentsByTask[taskID] = make(map[string]bool)
+		}
+		agentsByTask[taskID][agentID] = true
+	}
+
+	projection := make(map[string]map[string]bool, len(isAgent))
+	for agentID := range isAgent {
+		projection[agentID] = make(map[string]bool)
+	}
+	for _, agents := range agentsByTask {
+		ids := make([]string, 0, len(agents))
+		for id := range agents {
+			ids = append(ids, id)
+		}
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				projection[ids[i]][ids[j]] = true
+				projection[ids[j]][ids[i]] = true
+			}
+		}
+	}
+
+	local := make(map[string]float64, len(n.Nodes))
+	sum := 0.0
+	count := 0
+	for _, node := range n.Nodes {
+		if node.Type != "agent" {
+			continue
+		}
+		count++
+		neighbors := projection[node.ID]
+		k := len(neighbors)
+		if k < 2 {
+			local[node.ID] = 0
+			continue
+		}
+		links := 0
+		for a := range neighbors {
+			for b := range neighbors {
+				if a < b && projection[a][b] {
+					links++
+				}
+			}
+		}
+		coeff := 2 * float64(links) / float64(k*(k-1))
+		local[node.ID] = coeff
+		sum += coeff
+	}
+
+	var global float64
+	if count > 0 {
+		global = sum / float64(count)
+	}
+	return local, global
+}
+
+// marketMetricsResponse is the body of /api/market-metrics.
+type marketMetricsResponse struct {
+	Density               float64            `json:"density"`
+	DensityEdges          map[string]float64 `json:"density_edges,omitempty"`
+	Component             *componentInfo     `json:"component,omitempty"`
+	ClusteringCoefficient float64            `json:"clustering_coefficient"`
+	HHI                   float64            `json:"hhi"`
+	TheilIndex            float64            `json:"theil_index"`
+	AtkinsonIndex         float64            `json:"atkinson_index"`
+}
+
+// handleMarketMetrics serves headline network-wide metrics - density, the
+// global agent clustering coefficient, the Herfindahl-Hirschman
+// concentration index of won task value, and the Theil and Atkinson
+// inequality indices of that same distribution - alongside the
+// -density-edges weighting configuration that produced the density figure,
+// so a caller can tell what counts as a "connection" without re-deriving it
+// from server flags. -giant-component or ?component=giant restricts the
+// computation to the largest connected component. The Atkinson index uses
+// the inequality aversion parameter set by -atkinson-epsilon.
+func handleMarketMetrics(w http.ResponseWriter, r *http.Request) {
+	base, comp := networkForMetrics(getNetwork(), r)
+	_, globalClustering := clusteringCoefficient(base)
+	wonValues := agentWonValuesFor(base)
+	resp := marketMetricsResponse{
+		Density:               networkDensity(base),
+		DensityEdges:          densityEdgeWeights,
+		Component:             comp,
+		ClusteringCoefficient: globalClustering,
+		HHI:                   hhiIndex(wonValues),
+		TheilIndex:            calculateTheilIndex(wonValues),
+		AtkinsonIndex:         calculateAtkinsonIndex(wonValues, atkinsonEpsilon),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleMarketMetrics] Failed to encode response: %v", err)
+	}
+}
+
+// taxonomyCategories is the canonical, stable set of specialty categories
+// swe_manager_task_distribution.go classifies agents and tasks into -
+// mirroring dashboard.go's sparklineCategories. Duplicated here per this
+// repo's per-file convention, since viz.go and dashboard.go are independent
+// standalone programs that don't share package-level state.
+var taxonomyCategories = []string{
+	"ApplicationLogic",
+	"ServerSideLogic",
+	"BugFixes",
+	"UI/UX",
+	"SystemWideQualityAndReliability",
+	"NewFeaturesOrEnhancements",
+	"ReliabilityImprovements",
+}
+
+// taxonomyResponse is the body of /api/taxonomy: the category -> skills
+// vocabulary a client uses to build filter or legend UIs. This repo's
+// specialty model is flat - a Speciality's Name is both its own category and
+// its own skill - so each category maps to a single-element skill list
+// carrying its own name.
+type taxonomyResponse struct {
+	Categories []string            `json:"categories"`
+	Skills     map[string][]string `json:"skills"`
+}
+
+// handleTaxonomy serves the fixed category/skill vocabulary from
+// taxonomyCategories, so clients don't have to hardcode it themselves.
+func handleTaxonomy(w http.ResponseWriter, r *http.Request) {
+	skills := make(map[string][]string, len(taxonomyCategories))
+	for _, category := range taxonomyCategories {
+		skills[category] = []string{category}
+	}
+	resp := taxonomyResponse{
+		Categories: taxonomyCategories,
+		Skills:     skills,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleTaxonomy] Failed to encode response: %v", err)
+	}
+}
+
+// graphMLKey declares one <data> attribute a GraphML consumer (Gephi, yEd)
+// should expect on nodes or edges.
+type graphMLKey struct {
+	XMLName  xml.Name `xml:"key"`
+	ID       string   `xml:"id,attr"`
+	For      string   `xml:"for,attr"`
+	AttrName string   `xml:"attr.name,attr"`
+	AttrType string   `xml:"attr.type,attr"`
+}
+
+// graphMLData is one <data key="...">value</data> element.
+type graphMLData struct {
+	XMLName xml.Name `xml:"data"`
+	Key     string   `xml:"key,attr"`
+	Value   string   `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	XMLName xml.Name      `xml:"node"`
+	ID      string        `xml:"id,attr"`
+	Data    []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	XMLName xml.Name      `xml:"edge"`
+	Source  string        `xml:"source,attr"`
+	Target  string        `xml:"target,attr"`
+	Data    []graphMLData `xml:"data"`
+}
+
+type graphMLGraph struct {
+	XMLName     xml.Name      `xml:"graph"`
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+// writeGraphML serializes n as GraphML for tools like Gephi and yEd.
+// edgedefault is "directed" since a bid/assigned/auction edge's source ->
+// target carries meaning. Node fields map to this schema's real equivalents
+// of group/name/role/degree (Group, Label, Type, Degree) plus a "specialist"
+// flag (whether the node declares any specialities at all). Edge fields map
+// type and weight (BidValue) directly; "assigned" edges also carry
+// winning_bid and bid_count, derived from the task's bids via
+// assignedTasksFor/bidsByTaskFor since this schema has no such fields
+// directly. encoding/xml escapes every attribute and chardata value.
+func writeGraphML(n Network, w io.Writer) error {
+	winningBid := assignedTasksFor(n)
+	bidsByTask := bidsByTaskFor(n)
+
+	doc := graphMLDocument{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "d0", For: "node", AttrName: "group", AttrType: "string"},
+			{ID: "d1", For: "node", AttrName: "name", AttrType: "string"},
+			{ID: "d2", For: "node", AttrName: "role", AttrType: "string"},
+			{ID: "d3", For: "node", AttrName: "specialist", AttrType: "boolean"},
+			{ID: "d4", For: "node", AttrName: "degree", AttrType: "int"},
+			{ID: "d5", For: "edge", AttrName: "type", AttrType: "string"},
+			{ID: "d6", For: "edge", AttrName: "weight", AttrType: "double"},
+			{ID: "d7", For: "edge", AttrName: "winning_bid", AttrType: "double"},
+			{ID: "d8", For: "edge", AttrName: "bid_count", AttrType: "int"},
+		},
+		Graph: graphMLGraph{ID: "G", EdgeDefault: "directed"},
+	}
+
+	for _, node := range n.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: node.ID,
+			Data: []graphMLData{
+				{Key: "d0", Value: node.Group},
+				{Key: "d1", Value: node.Label},
+				{Key: "d2", Value: node.Type},
+				{Key: "d3", Value: strconv.FormatBool(len(node.Specialities) > 0)},
+				{Key: "d4", Value: strconv.Itoa(node.Degree)},
+			},
+		})
+	}
+
+	for _, e := range n.Edges {
+		data := []graphMLData{
+			{Key: "d5", Value: e.Type},
+			{Key: "d6", Value: strconv.FormatFloat(e.BidValue, 'f', -1, 64)},
+		}
+		if e.Type == "assigned" {
+			if price, ok := winningBid[e.Source]; ok {
+				data = append(data,
+					graphMLData{Key: "d7", Value: strconv.FormatFloat(price, 'f', -1, 64)},
+					graphMLData{Key: "d8", Value: strconv.Itoa(len(bidsByTask[e.Source]))},
+				)
+			}
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.Source, Target: e.Target, Data: data})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// handleGraphML serves the current network as GraphML for import into
+// Gephi, yEd, and similar tools.
+func handleGraphML(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/xml")
+	if err := writeGraphML(getNetwork(), w); err != nil {
+		log.Printf("[handleGraphML] Failed to write GraphML: %v", err)
+	}
+}
+
+// dotUnquotedID matches DOT identifiers that are legal unquoted: letters,
+// digits, and underscores, not starting with a digit.
+var dotUnquotedID = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// dotID renders id as a DOT identifier, quoting and escaping it if it
+// contains characters illegal in a bare DOT identifier.
+func dotID(id string) string {
+	if dotUnquotedID.MatchString(id) {
+		return id
+	}
+	escaped := strings.ReplaceAll(id, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	return `"` + escaped + `"`
+}
+
+// writeDOT serializes n as a Graphviz DOT digraph for `dot -Tpng` and
+// similar rendering. Agent nodes are filled blue, task ("issue") nodes
+// filled purple. Edges are dashed for "bid" edges, bold for "assigned"
+// edges, and plain otherwise (e.g. "auction"), each labeled with its
+// BidValue as weight. Node IDs containing characters illegal in a bare DOT
+// identifier are quoted via dotID.
+func writeDOT(n Network, w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph network {"); err != nil {
+		return err
+	}
+	for _, node := range n.Nodes {
+		color := "lightgray"
+		switch node.Type {
+		case "agent":
+			color = "lightblue"
+		case "issue":
+			color = "plum"
+		}
+		if _, err := fmt.Fprintf(w, "  %s [style=filled, fillcolor=%s];\n", dotID(node.ID), color); err != nil {
+			return err
+		}
+	}
+	for _, e := range n.Edges {
+		style := "solid"
+		switch e.Type {
+		case "bid":
+			style = "dashed"
+		case "assigned":
+			style = "bold"
+		}
+		label := strconv.FormatFloat(e.BidValue, 'f', -1, 64)
+		if _, err := fmt.Fprintf(w, "  %s -> %s [style=%s, label=%q];\n", dotID(e.Source), dotID(e.Target), style, label); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// handleDOT serves the current network as Graphviz DOT source, e.g. for
+// `curl .../api/dot | dot -Tpng -o network.png`.
+func handleDOT(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/vnd.graphviz")
+	if err := writeDOT(getNetwork(), w); err != nil {
+		log.Printf("[handleDOT] Failed to write DOT: %v", err)
+	}
+}
+
+// truthDiffAgent is one agent's predicted-vs-ground-truth assignment diff.
+type truthDiffAgent struct {
+	AgentID   string   `json:"agent_id"`
+	Correct   []string `json:"correct"`
+	Missed    []string `json:"missed"`
+	Spurious  []string `json:"spurious"`
+	Precision float64  `json:"precision"`
+	Recall    float64  `json:"recall"`
+}
+
+// truthDiffResponse is the body of /api/truth-diff.
+type truthDiffResponse struct {
+	Agents []truthDiffAgent `json:"agents"`
+}
+
+// loadTruthAssignments reads a ground-truth assignment file: a JSON object
+// mapping task_id to the agent_id it should have been assigned to, e.g.
+// {"task1": "agent1", "task2": "agent3"}. This repo has no other ground-truth
+// schema to map onto, so task and agent IDs are assumed to already match n's
+// node IDs directly.
+func loadTruthAssignments(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var truth map[string]string
+	if err := json.Unmarshal(data, &truth); err != nil {
+		return nil, err
+	}
+	return truth, nil
+}
+
+// diffAssignments compares n's actual "assigned" edges against truth
+// (task_id -> agent_id), grouping each agent's correctly-predicted, missed,
+// and spuriously-assigned tasks, and computing precision (correct/predicted)
+// and recall (correct/ground-truth) - both 0 when their denominator is
+// empty. Every agent appearing in either n's assignments or truth is
+// included, sorted by agent ID; each task list is sorted for a stable
+// response.
+func diffAssignments(n Network, truth map[string]string) []truthDiffAgent {
+	predicted := make(map[string]map[string]bool)
+	for _, e := range n.Edges {
+		if e.Type != "assigned" {
+			continue
+		}
+		if predicted[e.Target] == nil {
+			predicted[e.Target] = make(map[string]bool)
+		}
+		predicted[e.Target][e.Source] = true
+	}
+	groundTruth := make(map[string]map[string]bool)
+	for taskID, agentID := range truth {
+		if groundTruth[agentID] == nil {
+			groundTruth[agentID] = make(map[string]bool)
+		}
+		groundTruth[agentID][taskID] = true
+	}
+
+	agentIDs := make(map[string]bool, len(predicted)+len(groundTruth))
+	for id := range predicted {
+		agentIDs[id] = true
+	}
+	for id := range groundTruth {
+		agentIDs[id] = true
+	}
+
+	rows := make([]truthDiffAgent, 0, len(agentIDs))
+	for agentID := range agentIDs {
+		pred := predicted[agentID]
+		truthSet := groundTruth[agentID]
+
+		var correct, missed, spurious []string
+		for taskID := range pred {
+			if truthSet[taskID] {
+				correct = append(correct, taskID)
+			} else {
+				spurious = append(spurious, taskID)
+			}
+		}
+		for taskID := range truthSet {
+			if !pred[taskID] {
+				missed = append(missed, taskID)
+			}
+		}
+		sort.Strings(correct)
+		sort.Strings(missed)
+		sort.Strings(spurious)
+
+		var precision, recall float64
+		if len(pred) > 0 {
+			precision = float64(len(correct)) / float64(len(pred))
+		}
+		if len(truthSet) > 0 {
+			recall = float64(len(correct)) / float64(len(truthSet))
+		}
+
+		rows = append(rows, truthDiffAgent{
+			AgentID:   agentID,
+			Correct:   correct,
+			Missed:    missed,
+			Spurious:  spurious,
+			Precision: precision,
+			Recall:    recall,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].AgentID < rows[j].AgentID })
+	return rows
+}
+
+// handleTruthDiff serves a per-agent diff of the current network's actual
+// assignments against a ground-truth file named by the required ?truth=
+// query parameter (see loadTruthAssignments for its format).
+func handleTruthDiff(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("truth")
+	if path == "" {
+		http.Error(w, "missing required \"truth\" query parameter", http.StatusBadRequest)
+		return
+	}
+	truth, err := loadTruthAssignments(path)
+	if err != nil {
+		log.Printf("[handleTruthDiff] Failed to load truth file %s: %v", path, err)
+		http.Error(w, "failed to load truth file", http.StatusBadRequest)
+		return
+	}
+
+	resp := truthDiffResponse{Agents: diffAssignments(getNetwork(), truth)}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[handleTruthDiff] Failed to encode response: %v", err)
+	}
+}
+
+func main() {
+	staticDir := flag.String("static-dir", "./static", "On-disk directory of static assets; falls back to the embedded bundle if absent")
+	networkFile := flag.String("network", "data/baseline_network.json", "Path to the network JSON backing /api/filter")
+	addr := flag.String("addr", ":8081", "Address to listen on")
+	trustDegrees := flag.Bool("trust-degrees", false, "Trust the degree values in the source network JSON instead of recomputing them from edges")
+	excludeOutsourced := flag.Bool("exclude-outsourced", false, "Default /api/filter to the \"realized\" scope, excluding outsourced edges from degree and density")
+	giniWarn := flag.Float64("gini-warn-threshold", thresholds.GiniWarn, "/report Gini coefficient at or above this is a \"warn\" badge")
+	giniFail := flag.Float64("gini-fail-threshold", thresholds.GiniFail, "/report Gini coefficient at or above this is a \"fail\" badge")
+	densityWarn := flag.Float64("density-warn-threshold", thresholds.DensityWarn, "/report network density at or below this is a \"warn\" badge")
+	densityFail := flag.Float64("density-fail-threshold", thresholds.DensityFail, "/report network density at or below this is a \"fail\" badge")
+	hhiWarn := flag.Float64("hhi-warn-threshold", thresholds.HHIWarn, "/report HHI at or above this is a \"warn\" badge")
+	hhiFail := flag.Float64("hhi-fail-threshold", thresholds.HHIFail, "/report HHI at or above this is a \"fail\" badge")
+	normalizePricesFlag := flag.String("normalize-prices", "none", "Rescale bid values and task price bounds before computing monetary metrics: none|zscore|minmax")
+	giantComponent := flag.Bool("giant-component", false, "Restrict all metric computation (/data is exempt) to the largest connected component")
+	densityEdgesFlag := flag.String("density-edges", "", "Comma-separated edge_type[:weight] list controlling which edge types count toward network density and how heavily, e.g. \"assigned,bid:0.5\" (default: every edge type counted with weight 1)")
+	atkinsonEpsilonFlag := flag.Float64("atkinson-epsilon", 0.5, "Inequality aversion parameter for the Atkinson index reported by /api/market-metrics; higher values weight the index more heavily toward the low end of the distribution")
+	flag.Parse()
+
+	if *excludeOutsourced {
+		defaultScope = "realized"
+	}
+	thresholds = reportThresholds{
+		GiniWarn:    *giniWarn,
+		GiniFail:    *giniFail,
+		DensityWarn: *densityWarn,
+		DensityFail: *densityFail,
+		HHIWarn:     *hhiWarn,
+		HHIFail:     *hhiFail,
+	}
+	switch *normalizePricesFlag {
+	case "none", "zscore", "minmax":
+		priceNormalization = *normalizePricesFlag
+	default:
+		log.Fatalf("[viz] Invalid -normalize-prices %q: must be none, zscore, or minmax", *normalizePricesFlag)
+	}
+	giantComponentOnly = *giantComponent
+	parsedDensityWeights, err := parseDensityEdgeWeights(*densityEdgesFlag)
+	if err != nil {
+		log.Fatalf("[viz] Invalid -density-edges %q: %v", *densityEdgesFlag, err)
+	}
+	densityEdgeWeights = parsedDensityWeights
+	atkinsonEpsilon = *atkinsonEpsilonFlag
+
+	fsys, err := staticFileSystem(*staticDir)
+	if err != nil {
+		log.Fatalf("[viz] Failed to set up static file system: %v", err)
+	}
+
+	raw, err := os.ReadFile(*networkFile)
+	if err != nil {
+		log.Fatalf("[viz] Failed to read %s: %v", *networkFile, err)
+	}
+	var loaded Network
+	if err := unmarshalJSONWithContext(*networkFile, raw, &loaded); err != nil {
+		log.Fatalf("[viz] Failed to unmarshal %s: %v", *networkFile, err)
+	}
+	reconcileDegrees(&loaded, *trustDegrees)
+	normalizePrices(&loaded, priceNormalization)
+	setNetwork(loaded)
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(fsys)))
+	mux.HandleFunc("/api/filter", handleFilter)
+	mux.HandleFunc("/api/tier-metrics", handleTierMetrics)
+	mux.HandleFunc("/report", handleReport)
+	mux.HandleFunc("/data", handleData)
+	mux.HandleFunc("/api/betweenness", handleBetweenness)
+	mux.HandleFunc("/api/closeness", handleCloseness)
+	mux.HandleFunc("/api/pagerank", handlePageRank)
+	mux.HandleFunc("/api/what-if", handleWhatIf)
+	mux.HandleFunc("/api/market-metrics", handleMarketMetrics)
+	mux.HandleFunc("/api/taxonomy", handleTaxonomy)
+	mux.HandleFunc("/api/graphml", handleGraphML)
+	mux.HandleFunc("/api/dot", handleDOT)
+	mux.HandleFunc("/api/truth-diff", handleTruthDiff)
+	mux.HandleFunc("/api/cytoscape", handleCytoscape)
+	mux.HandleFunc("/api/top-edges", handleTopEdges)
+	mux.HandleFunc("/api/load", handleLoadNetwork)
+	mux.HandleFunc("/api/diff", handleDiff)
+	mux.HandleFunc("/api/gini-decomposition", handleGiniDecomposition)
+	mux.HandleFunc("/api/neighbors", handleNeighbors)
+	mux.HandleFunc("/api/policy-comparison", handlePolicyComparison)
+	mux.HandleFunc("/api/lorenz-curve", handleLorenzCurve)
+	log.Println("[viz] Visualization server running at http://localhost" + *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
@@ -0,0 +1,2150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func fixtureFilterNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent", Specialities: []Speciality{{Name: "UI/UX"}}},
+			{ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "UI/UX"}},
+			{ID: "task2", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid"},
+			{Source: "agent1", Target: "task1", Type: "assigned"},
+			{Source: "agent2", Target: "task2", Type: "bid"},
+		},
+	}
+}
+
+func runFilterQuery(t *testing.T, q string) (*http.Response, []byte) {
+	t.Helper()
+	setNetwork(fixtureFilterNetwork())
+	req := httptest.NewRequest(http.MethodGet, "/api/filter?q="+url.QueryEscape(q), nil)
+	rec := httptest.NewRecorder()
+	handleFilter(rec, req)
+	resp := rec.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	return resp, body
+}
+
+func TestHandleFilterSimpleComparison(t *testing.T) {
+	_, body := runFilterQuery(t, "role=agent")
+	if !strings.Contains(string(body), `"agent1"`) || !strings.Contains(string(body), `"agent2"`) {
+		t.Fatalf("expected both agents to match role=agent, got %s", body)
+	}
+	if strings.Contains(string(body), `"task1"`) {
+		t.Fatalf("expected no tasks to match role=agent, got %s", body)
+	}
+}
+
+func TestHandleFilterAndPrecedence(t *testing.T) {
+	// agent1 has degree 2 (one bid + one assigned edge); agent2 has degree 1.
+	_, body := runFilterQuery(t, "role=agent AND degree>1")
+	if !strings.Contains(string(body), `"agent1"`) {
+		t.Fatalf("expected agent1 (degree 2) to match, got %s", body)
+	}
+	if strings.Contains(string(body), `"agent2"`) {
+		t.Fatalf("expected agent2 (degree 1) not to match, got %s", body)
+	}
+}
+
+func TestHandleFilterOrBindsLooserThanAnd(t *testing.T) {
+	// Should read as (role=issue AND degree>1) OR specialist=true, matching
+	// task1 (degree 2) via the first clause and agent1 via the second, but
+	// not agent2 or task2.
+	_, body := runFilterQuery(t, "role=issue AND degree>1 OR specialist=true")
+	for _, want := range []string{`"task1"`, `"agent1"`} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("expected %s to match, got %s", want, body)
+		}
+	}
+	for _, notWant := range []string{`"agent2"`, `"task2"`} {
+		if strings.Contains(string(body), notWant) {
+			t.Fatalf("expected %s not to match, got %s", notWant, body)
+		}
+	}
+}
+
+func TestHandleFilterRejectsUnknownField(t *testing.T) {
+	resp, body := runFilterQuery(t, "bogus=agent")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown field, got %d: %s", resp.StatusCode, body)
+	}
+}
+
+func fixtureTierNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agentA", Type: "agent"},
+			{ID: "agentB", Type: "agent"},
+			{ID: "agentC", Type: "agent"},
+			{ID: "agentD", Type: "agent"},
+			{ID: "agentE", Type: "agent"},
+			{ID: "agentF", Type: "agent"},
+			{ID: "taskLow", Type: "issue", PriceMin: 500, PriceMax: 1000},
+			{ID: "taskMid", Type: "issue", PriceMin: 5000, PriceMax: 8000},
+			{ID: "taskHigh", Type: "issue", PriceMin: 20000, PriceMax: 25000},
+		},
+		Edges: []Edge{
+			{Source: "agentA", Target: "taskLow", Type: "bid", BidValue: 800},
+			{Source: "taskLow", Target: "agentA", Type: "assigned"},
+
+			{Source: "agentB", Target: "taskMid", Type: "bid", BidValue: 6000},
+			{Source: "agentC", Target: "taskMid", Type: "bid", BidValue: 7000},
+
+			{Source: "agentD", Target: "taskHigh", Type: "bid", BidValue: 22000},
+			{Source: "agentE", Target: "taskHigh", Type: "bid", BidValue: 24000},
+			{Source: "agentF", Target: "taskHigh", Type: "bid", BidValue: 23000},
+			{Source: "taskHigh", Target: "agentD", Type: "assigned"},
+		},
+	}
+}
+
+func TestComputeTierMetricsPartitionsTasksAcrossTiers(t *testing.T) {
+	setNetwork(fixtureTierNetwork())
+	metrics := computeTierMetrics([]float64{1000, 10000})
+	if len(metrics) != 3 {
+		t.Fatalf("expected 3 tiers, got %d: %+v", len(metrics), metrics)
+	}
+
+	low, mid, high := metrics[0], metrics[1], metrics[2]
+
+	if low.NumTasks != 1 || low.AssignmentRate != 1.0 || low.AvgBidders != 1 || low.AvgClientSurplus != 200 {
+		t.Fatalf("unexpected low tier metrics: %+v", low)
+	}
+	if mid.NumTasks != 1 || mid.AssignmentRate != 0 || mid.AvgBidders != 2 || mid.AvgClientSurplus != 0 {
+		t.Fatalf("unexpected mid tier metrics: %+v", mid)
+	}
+	if high.NumTasks != 1 || high.AssignmentRate != 1.0 || high.AvgBidders != 3 || high.AvgClientSurplus != 3000 {
+		t.Fatalf("unexpected high tier metrics: %+v", high)
+	}
+}
+
+func TestHandleTierMetricsRejectsNonAscendingBoundaries(t *testing.T) {
+	setNetwork(fixtureTierNetwork())
+	req := httptest.NewRequest(http.MethodGet, "/api/tier-metrics?tiers=10000,1000", nil)
+	rec := httptest.NewRecorder()
+	handleTierMetrics(rec, req)
+	if rec.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for non-ascending tier boundaries, got %d", rec.Result().StatusCode)
+	}
+}
+
+// fixtureFragmentedNetwork has two connected components: a 3-node giant
+// component {agent1, agent2, task1} and a 2-node component {agent3, task2}.
+func fixtureFragmentedNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"},
+			{ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue", PriceMin: 100, PriceMax: 200},
+			{ID: "agent3", Type: "agent"},
+			{ID: "task2", Type: "issue", PriceMin: 100, PriceMax: 200},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 150},
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "agent2", Target: "task1", Type: "bid", BidValue: 180},
+			{Source: "agent3", Target: "task2", Type: "bid", BidValue: 120},
+		},
+	}
+}
+
+func TestLargestComponentIdentifiesGiantComponentAndSizeFraction(t *testing.T) {
+	ids, fraction := largestComponent(fixtureFragmentedNetwork())
+	if len(ids) != 3 || !ids["agent1"] || !ids["agent2"] || !ids["task1"] {
+		t.Fatalf("expected giant component {agent1,agent2,task1}, got %v", ids)
+	}
+	if fraction != 0.6 {
+		t.Fatalf("expected size fraction 0.6, got %v", fraction)
+	}
+}
+
+func TestWantsGiantComponentHonorsPackageVarAndQueryParam(t *testing.T) {
+	defer func() { giantComponentOnly = false }()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/filter", nil)
+	if wantsGiantComponent(req) {
+		t.Fatalf("expected false by default")
+	}
+
+	giantComponentOnly = true
+	if !wantsGiantComponent(req) {
+		t.Fatalf("expected true when -giant-component is set")
+	}
+
+	giantComponentOnly = false
+	reqParam := httptest.NewRequest(http.MethodGet, "/api/filter?component=giant", nil)
+	if !wantsGiantComponent(reqParam) {
+		t.Fatalf("expected true when ?component=giant is set")
+	}
+}
+
+func TestHandleTierMetricsRestrictsToGiantComponentWhenRequested(t *testing.T) {
+	setNetwork(fixtureFragmentedNetwork())
+	req := httptest.NewRequest(http.MethodGet, "/api/tier-metrics?tiers=1000&component=giant", nil)
+	rec := httptest.NewRecorder()
+	handleTierMetrics(rec, req)
+
+	var resp tierMetricsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Tiers[0].NumTasks != 1 {
+		t.Fatalf("expected only task1 counted once restricted to the giant component, got %+v", resp.Tiers)
+	}
+	if resp.Component == nil || !resp.Component.Restricted || resp.Component.ComponentSize != 3 || resp.Component.TotalNodes != 5 {
+		t.Fatalf("expected component info reflecting the giant component restriction, got %+v", resp.Component)
+	}
+}
+
+func TestHandleDataAlwaysReturnsFullGraphRegardlessOfGiantComponentSetting(t *testing.T) {
+	setNetwork(fixtureFragmentedNetwork())
+	giantComponentOnly = true
+	defer func() { giantComponentOnly = false }()
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	rec := httptest.NewRecorder()
+	handleData(rec, req)
+
+	var resp dataResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode /data response: %v", err)
+	}
+	if len(resp.Nodes) != 5 || len(resp.Edges) != 4 {
+		t.Fatalf("expected /data to return the full graph (5 nodes, 4 edges), got %d nodes, %d edges", len(resp.Nodes), len(resp.Edges))
+	}
+}
+
+// fixtureBetweennessPathNetwork is a 3-node path agent1-task1-agent2, so
+// task1 is the sole broker on the only shortest path between the agents.
+func fixtureBetweennessPathNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent", Label: "Agent One"},
+			{ID: "task1", Type: "issue", Label: "Task One"},
+			{ID: "agent2", Type: "agent", Label: "Agent Two"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid"},
+			{Source: "task1", Target: "agent2", Type: "assigned"},
+		},
+	}
+}
+
+func TestBetweennessCentralityScoresSoleBrokerOfAPathAsOne(t *testing.T) {
+	scores := betweennessCentrality(fixtureBetweennessPathNetwork())
+	if scores["task1"] != 1 {
+		t.Fatalf("expected task1 (the sole broker) to have normalized betweenness 1, got %v", scores["task1"])
+	}
+	if scores["agent1"] != 0 || scores["agent2"] != 0 {
+		t.Fatalf("expected endpoint nodes to have betweenness 0, got agent1=%v agent2=%v", scores["agent1"], scores["agent2"])
+	}
+}
+
+func TestBetweennessCentralityIncludesZeroDegreeNodes(t *testing.T) {
+	n := fixtureBetweennessPathNetwork()
+	n.Nodes = append(n.Nodes, Node{ID: "isolated", Type: "agent"})
+	scores := betweennessCentrality(n)
+	if v, ok := scores["isolated"]; !ok || v != 0 {
+		t.Fatalf("expected isolated node to appear with betweenness 0, got %v (present=%v)", v, ok)
+	}
+}
+
+func TestBetweennessCentralitySumsContributionsWithinEachDisconnectedComponent(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "a1", Type: "agent"}, {ID: "b1", Type: "issue"}, {ID: "c1", Type: "agent"},
+			{ID: "a2", Type: "agent"}, {ID: "b2", Type: "issue"}, {ID: "c2", Type: "agent"},
+		},
+		Edges: []Edge{
+			{Source: "a1", Target: "b1", Type: "bid"},
+			{Source: "b1", Target: "c1", Type: "assigned"},
+			{Source: "a2", Target: "b2", Type: "bid"},
+			{Source: "b2", Target: "c2", Type: "assigned"},
+		},
+	}
+	scores := betweennessCentrality(n)
+	// (n-1)(n-2)/2 = 5*4/2 = 10; each component's broker sits on the one
+	// shortest path within its own component.
+	want := 1.0 / 10
+	if math.Abs(scores["b1"]-want) > 1e-9 || math.Abs(scores["b2"]-want) > 1e-9 {
+		t.Fatalf("expected each component's broker to score %v, got b1=%v b2=%v", want, scores["b1"], scores["b2"])
+	}
+	for _, id := range []string{"a1", "c1", "a2", "c2"} {
+		if scores[id] != 0 {
+			t.Fatalf("expected endpoint node %s to have betweenness 0, got %v", id, scores[id])
+		}
+	}
+}
+
+func TestHandleBetweennessServesNodesSortedDescendingByScore(t *testing.T) {
+	setNetwork(fixtureBetweennessPathNetwork())
+	req := httptest.NewRequest(http.MethodGet, "/api/betweenness", nil)
+	rec := httptest.NewRecorder()
+	handleBetweenness(rec, req)
+
+	var rows []betweennessNode
+	if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d: %+v", len(rows), rows)
+	}
+	if rows[0].ID != "task1" || rows[0].Betweenness != 1 {
+		t.Fatalf("expected task1 to be the top-ranked broker, got %+v", rows[0])
+	}
+	if rows[0].Name != "Task One" || rows[0].Role != "issue" {
+		t.Fatalf("expected task1's name/role to come from the node, got %+v", rows[0])
+	}
+}
+
+func TestClosenessCentralityScoresCenterNodeHighestOnAPath(t *testing.T) {
+	scores := closenessCentrality(fixtureBetweennessPathNetwork())
+	if math.Abs(scores["task1"]-1) > 1e-9 {
+		t.Fatalf("expected task1 (the center of the path) to have closeness 1, got %v", scores["task1"])
+	}
+	want := 2.0 / 3.0
+	if math.Abs(scores["agent1"]-want) > 1e-9 || math.Abs(scores["agent2"]-want) > 1e-9 {
+		t.Fatalf("expected endpoint nodes to have closeness %v, got agent1=%v agent2=%v", want, scores["agent1"], scores["agent2"])
+	}
+}
+
+// TestClosenessCentralityAppliesWassermanFaustCorrection combines the
+// 3-node path fixture with an isolated 2-node pair. Without correction the
+// pair's nodes would each score a perfect 1 (they reach their only possible
+// neighbor at distance 1); the correction scales that down by how much of
+// the whole graph they actually reach, so the well-connected path center
+// still outranks them.
+func TestClosenessCentralityAppliesWassermanFaustCorrection(t *testing.T) {
+	n := fixtureBetweennessPathNetwork()
+	n.Nodes = append(n.Nodes, Node{ID: "agent3", Type: "agent"}, Node{ID: "task3", Type: "issue"})
+	n.Edges = append(n.Edges, Edge{Source: "agent3", Target: "task3", Type: "bid"})
+
+	scores := closenessCentrality(n)
+	if scores["agent3"] >= 1 {
+		t.Fatalf("expected the isolated pair's closeness to be corrected below 1, got %v", scores["agent3"])
+	}
+	if scores["task1"] <= scores["agent3"] {
+		t.Fatalf("expected the well-connected path center (%v) to outrank the isolated pair (%v)", scores["task1"], scores["agent3"])
+	}
+}
+
+func TestComponentSizesForMatchesConnectedComponentMembership(t *testing.T) {
+	n := fixtureBetweennessPathNetwork()
+	n.Nodes = append(n.Nodes, Node{ID: "isolated", Type: "agent"})
+	sizes := componentSizesFor(n)
+	if sizes["task1"] != 3 || sizes["agent1"] != 3 || sizes["agent2"] != 3 {
+		t.Fatalf("expected path nodes to report component size 3, got %+v", sizes)
+	}
+	if sizes["isolated"] != 1 {
+		t.Fatalf("expected isolated node to report component size 1, got %v", sizes["isolated"])
+	}
+}
+
+func TestHandleClosenessServesComponentSizeAndSortedRows(t *testing.T) {
+	setNetwork(fixtureBetweennessPathNetwork())
+	req := httptest.NewRequest(http.MethodGet, "/api/closeness", nil)
+	rec := httptest.NewRecorder()
+	handleCloseness(rec, req)
+
+	var rows []closenessNode
+	if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d: %+v", len(rows), rows)
+	}
+	if rows[0].ID != "task1" || math.Abs(rows[0].Closeness-1) > 1e-9 {
+		t.Fatalf("expected task1 to be the top-ranked node, got %+v", rows[0])
+	}
+	if rows[0].ComponentSize != 3 {
+		t.Fatalf("expected component size 3, got %d", rows[0].ComponentSize)
+	}
+	if rows[0].Name != "Task One" || rows[0].Role != "issue" {
+		t.Fatalf("expected task1's name/role to come from the node, got %+v", rows[0])
+	}
+}
+
+func TestPageRankSumsToOne(t *testing.T) {
+	n := fixtureBetweennessPathNetwork()
+	scores := pageRank(n, 0.85, 100)
+	total := 0.0
+	for _, v := range scores {
+		total += v
+	}
+	if math.Abs(total-1) > 1e-6 {
+		t.Fatalf("expected pagerank scores to sum to 1, got %v (%+v)", total, scores)
+	}
+}
+
+// TestPageRankRedistributesDanglingRankUniformly builds a 3-node network
+// where task1 has no outgoing edges (a dangling node): agent1 bids on
+// task1, and task1 is assigned to agent2. Without dangling redistribution,
+// task1's rank would leak out of the system rather than flowing onward to
+// agent1 and agent2.
+func TestPageRankRedistributesDanglingRankUniformly(t *testing.T) {
+	n := Network{
+		Nodes: []Node{{ID: "agent1"}, {ID: "task1"}, {ID: "agent2"}},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid"},
+			{Source: "task1", Target: "agent2", Type: "assigned"},
+		},
+	}
+	scores := pageRank(n, 0.85, 100)
+	total := 0.0
+	for _, v := range scores {
+		total += v
+	}
+	if math.Abs(total-1) > 1e-6 {
+		t.Fatalf("expected pagerank to conserve total rank across the dangling node, got %v", total)
+	}
+	if scores["agent2"] <= 0 {
+		t.Fatalf("expected agent2 to receive rank flowing through the dangling task1, got %v", scores["agent2"])
+	}
+}
+
+func TestHandlePageRankDefaultsDampingWhenAbsentOrInvalid(t *testing.T) {
+	setNetwork(fixtureBetweennessPathNetwork())
+
+	for _, raw := range []string{"", "not-a-number"} {
+		url := "/api/pagerank"
+		if raw != "" {
+			url += "?damping=" + raw
+		}
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		handlePageRank(rec, req)
+
+		var rows []pageRankNode
+		if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil {
+			t.Fatalf("failed to decode response for damping=%q: %v", raw, err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("expected 3 rows for damping=%q, got %d", raw, len(rows))
+		}
+		total := 0.0
+		for _, row := range rows {
+			total += row.PageRank
+		}
+		if math.Abs(total-1) > 1e-6 {
+			t.Fatalf("expected pagerank rows to sum to 1 for damping=%q, got %v", raw, total)
+		}
+	}
+}
+
+func TestApplyWhatIfRemovesNodeAndItsEdgesWithoutMutatingTheOriginal(t *testing.T) {
+	n := fixtureBetweennessPathNetwork()
+	before := len(n.Nodes)
+
+	after := applyWhatIf(n, whatIfRequest{Remove: []string{"agent1"}})
+
+	if len(n.Nodes) != before {
+		t.Fatalf("expected applyWhatIf not to mutate its input, got %d nodes (want %d)", len(n.Nodes), before)
+	}
+	if len(after.Nodes) != 2 {
+		t.Fatalf("expected 2 nodes after removing agent1, got %d", len(after.Nodes))
+	}
+	for _, e := range after.Edges {
+		if e.Source == "agent1" || e.Target == "agent1" {
+			t.Fatalf("expected no edges touching removed node agent1, found %+v", e)
+		}
+	}
+}
+
+func TestApplyWhatIfAppendsAddedNodes(t *testing.T) {
+	n := fixtureBetweennessPathNetwork()
+	after := applyWhatIf(n, whatIfRequest{Add: []Node{{ID: "agent3", Type: "agent"}}})
+	if len(after.Nodes) != len(n.Nodes)+1 {
+		t.Fatalf("expected one extra node, got %d (started with %d)", len(after.Nodes), len(n.Nodes))
+	}
+}
+
+func TestHandleWhatIfRejectsRemovalOfUnknownNode(t *testing.T) {
+	setNetwork(fixtureBetweennessPathNetwork())
+	body := strings.NewReader(`{"remove": ["does-not-exist"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/what-if", body)
+	rec := httptest.NewRecorder()
+	handleWhatIf(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for removing an unknown node, got %d", rec.Code)
+	}
+}
+
+func TestHandleWhatIfRejectsGet(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/what-if", nil)
+	rec := httptest.NewRecorder()
+	handleWhatIf(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for a GET request, got %d", rec.Code)
+	}
+}
+
+// TestHandleWhatIfRemovingHighDegreeAgentShiftsDensityAndGini removes agent2,
+// who wins two tasks worth more than agent1's single win, from a network
+// with both agents winning. Removing agent2 both lowers density (fewer
+// surviving edges) and collapses the winner-value distribution down to
+// agent1's single win, changing the Gini coefficient.
+func TestHandleWhatIfRemovingHighDegreeAgentShiftsDensityAndGini(t *testing.T) {
+	setNetwork(Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"}, {ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"}, {ID: "task2", Type: "issue"}, {ID: "task3", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 50},
+			{Source: "agent2", Target: "task1", Type: "bid", BidValue: 60},
+			{Source: "task1", Target: "agent2", Type: "assigned"},
+			{Source: "agent2", Target: "task2", Type: "bid", BidValue: 70},
+			{Source: "task2", Target: "agent2", Type: "assigned"},
+			{Source: "agent1", Target: "task3", Type: "bid", BidValue: 40},
+			{Source: "task3", Target: "agent1", Type: "assigned"},
+		},
+	})
+
+	body := strings.NewReader(`{"remove": ["agent2"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/what-if", body)
+	rec := httptest.NewRecorder()
+	handleWhatIf(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp whatIfResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.After.NumNodes != 4 {
+		t.Fatalf("expected 4 nodes remaining after removing agent2, got %d", resp.After.NumNodes)
+	}
+	if resp.After.Density >= resp.Before.Density {
+		t.Fatalf("expected density to drop after removing a high-degree agent, got before=%v after=%v", resp.Before.Density, resp.After.Density)
+	}
+	if resp.After.Gini == resp.Before.Gini {
+		t.Fatalf("expected the Gini coefficient to shift after removing agent2's wins, got %v unchanged", resp.After.Gini)
+	}
+}
+
+// TestClusteringCoefficientProjectsAgentsSharingATaskAsLinked builds two
+// agents both bidding on task1 (linking them in the one-mode projection)
+// and a third agent bidding on an unshared task2 - so agent1 and agent2's
+// local coefficients are both 0 (projected degree 1, below the guard), and
+// once a third agent is added to task1 they form a fully-connected triangle
+// with local coefficient 1.
+func TestClusteringCoefficientProjectsAgentsSharingATaskAsLinked(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"}, {ID: "agent2", Type: "agent"}, {ID: "agent3", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid"},
+			{Source: "agent2", Target: "task1", Type: "bid"},
+			{Source: "agent3", Target: "task1", Type: "bid"},
+		},
+	}
+	local, global := clusteringCoefficient(n)
+	for _, id := range []string{"agent1", "agent2", "agent3"} {
+		if local[id] != 1 {
+			t.Fatalf("expected %s in a fully-connected 3-agent triangle to have local coefficient 1, got %v", id, local[id])
+		}
+	}
+	if global != 1 {
+		t.Fatalf("expected global average 1 when every agent's local coefficient is 1, got %v", global)
+	}
+}
+
+func TestClusteringCoefficientGuardsDegreeBelowTwo(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"}, {ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"}, {ID: "task2", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid"},
+			{Source: "agent2", Target: "task2", Type: "bid"},
+		},
+	}
+	local, global := clusteringCoefficient(n)
+	if local["agent1"] != 0 || local["agent2"] != 0 {
+		t.Fatalf("expected agents with no shared-task neighbor to have local coefficient 0, got %+v", local)
+	}
+	if global != 0 {
+		t.Fatalf("expected global average 0 when no agent shares a task, got %v", global)
+	}
+}
+
+func TestHandleMarketMetricsIncludesClusteringCoefficient(t *testing.T) {
+	setNetwork(Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"}, {ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid"},
+			{Source: "agent2", Target: "task1", Type: "bid"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/market-metrics", nil)
+	rec := httptest.NewRecorder()
+	handleMarketMetrics(rec, req)
+
+	var resp marketMetricsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ClusteringCoefficient != 0 {
+		t.Fatalf("expected clustering coefficient 0 for two agents each of projected degree 1, got %v", resp.ClusteringCoefficient)
+	}
+}
+
+// fixtureDensityWeightingNetwork has 4 nodes, 2 "assigned" edges and 3 "bid"
+// edges, so density visibly changes depending on which types are weighted.
+func fixtureDensityWeightingNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"},
+			{ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+			{ID: "task2", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "task2", Target: "agent2", Type: "assigned"},
+			{Source: "agent1", Target: "task1", Type: "bid"},
+			{Source: "agent2", Target: "task2", Type: "bid"},
+			{Source: "agent1", Target: "task2", Type: "bid"},
+		},
+	}
+}
+
+func TestParseDensityEdgeWeightsParsesTypeAndOptionalWeight(t *testing.T) {
+	weights, err := parseDensityEdgeWeights("assigned,bid:0.5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if weights["assigned"] != 1 || weights["bid"] != 0.5 {
+		t.Fatalf("expected assigned=1 (default), bid=0.5, got %+v", weights)
+	}
+}
+
+func TestParseDensityEdgeWeightsRejectsInvalidWeight(t *testing.T) {
+	if _, err := parseDensityEdgeWeights("assigned:notanumber"); err == nil {
+		t.Fatalf("expected an error for a non-numeric weight")
+	}
+}
+
+func TestWeightedNetworkDensityIncreasesWhenBidEdgesAreIncluded(t *testing.T) {
+	n := fixtureDensityWeightingNetwork()
+	assignedOnly := weightedNetworkDensity(n, map[string]float64{"assigned": 1})
+	withBids := weightedNetworkDensity(n, map[string]float64{"assigned": 1, "bid": 1})
+	if withBids <= assignedOnly {
+		t.Fatalf("expected including bid edges to increase density, got assignedOnly=%v withBids=%v", assignedOnly, withBids)
+	}
+}
+
+func TestHandleMarketMetricsReflectsConfiguredEdgeWeightsAndDensityChangesWithBids(t *testing.T) {
+	setNetwork(fixtureDensityWeightingNetwork())
+	defer func() { densityEdgeWeights = nil }()
+
+	densityEdgeWeights = map[string]float64{"assigned": 1}
+	req := httptest.NewRequest(http.MethodGet, "/api/market-metrics", nil)
+	rec := httptest.NewRecorder()
+	handleMarketMetrics(rec, req)
+
+	var resp marketMetricsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.DensityEdges["assigned"] != 1 {
+		t.Fatalf("expected the response to echo the configured edge weights, got %+v", resp.DensityEdges)
+	}
+
+	densityEdgeWeights["bid"] = 1
+	req2 := httptest.NewRequest(http.MethodGet, "/api/market-metrics", nil)
+	rec2 := httptest.NewRecorder()
+	handleMarketMetrics(rec2, req2)
+	var resp2 marketMetricsResponse
+	if err := json.Unmarshal(rec2.Body.Bytes(), &resp2); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp2.Density <= resp.Density {
+		t.Fatalf("expected density to increase once bid edges are included, got %v then %v", resp.Density, resp2.Density)
+	}
+}
+
+func TestReconcileDegreesOverwritesStaleValuesByDefault(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agent1", Degree: 99},
+			{ID: "task1", Degree: 0},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid"},
+		},
+	}
+	reconcileDegrees(&n, false)
+	if n.Nodes[0].Degree != 1 || n.Nodes[1].Degree != 1 {
+		t.Fatalf("expected stale degrees to be recomputed to 1, got %+v", n.Nodes)
+	}
+}
+
+func TestReconcileDegreesTrustsSourceWhenFlagSet(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agent1", Degree: 99},
+			{ID: "task1", Degree: 0},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid"},
+		},
+	}
+	reconcileDegrees(&n, true)
+	if n.Nodes[0].Degree != 99 || n.Nodes[1].Degree != 0 {
+		t.Fatalf("expected source degrees to be left untouched, got %+v", n.Nodes)
+	}
+}
+
+func TestEdgeUnmarshalJSONFallsBackToWinningBid(t *testing.T) {
+	var e Edge
+	if err := json.Unmarshal([]byte(`{"source":"agent1","target":"task1","type":"bid","winning_bid":150}`), &e); err != nil {
+		t.Fatalf("failed to unmarshal edge: %v", err)
+	}
+	if e.BidValue != 150 {
+		t.Fatalf("expected BidValue to fall back to winning_bid, got %v", e.BidValue)
+	}
+}
+
+func TestEdgeUnmarshalJSONPrefersBidValueOverWinningBid(t *testing.T) {
+	var e Edge
+	if err := json.Unmarshal([]byte(`{"source":"agent1","target":"task1","type":"bid","bid_value":100,"winning_bid":150}`), &e); err != nil {
+		t.Fatalf("failed to unmarshal edge: %v", err)
+	}
+	if e.BidValue != 100 {
+		t.Fatalf("expected BidValue to prefer bid_value, got %v", e.BidValue)
+	}
+}
+
+func fixtureOutsourcedNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"},
+			{ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid"},
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "agent2", Target: "task1", Type: "outsourced"},
+		},
+	}
+}
+
+func TestEdgesInScopeRealizedDropsOutsourcedEdges(t *testing.T) {
+	n := fixtureOutsourcedNetwork()
+	realized := edgesInScope(n.Edges, "realized")
+	if len(realized) != 2 {
+		t.Fatalf("expected 2 realized edges, got %d: %+v", len(realized), realized)
+	}
+	for _, e := range realized {
+		if e.Type == "outsourced" {
+			t.Fatalf("expected no outsourced edges in realized scope, got %+v", e)
+		}
+	}
+
+	all := edgesInScope(n.Edges, "all")
+	if len(all) != 3 {
+		t.Fatalf("expected all 3 edges in \"all\" scope, got %d", len(all))
+	}
+}
+
+func TestHandleFilterRealizedScopeLowersDegreeAndDensity(t *testing.T) {
+	setNetwork(fixtureOutsourcedNetwork())
+
+	allReq := httptest.NewRequest(http.MethodGet, "/api/filter?q="+url.QueryEscape("role=agent"), nil)
+	allRec := httptest.NewRecorder()
+	handleFilter(allRec, allReq)
+	allBody, err := io.ReadAll(allRec.Result().Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	if !strings.Contains(string(allBody), `"density":0.5`) {
+		t.Fatalf("expected default scope density 0.5 (3 edges / (3*2)), got %s", allBody)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/filter?q="+url.QueryEscape("role=agent")+"&scope=realized", nil)
+	rec := httptest.NewRecorder()
+	handleFilter(rec, req)
+	realizedBody, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	if !strings.Contains(string(realizedBody), `"scope":"realized"`) {
+		t.Fatalf("expected scope=realized to be echoed back, got %s", realizedBody)
+	}
+	if !strings.Contains(string(realizedBody), `"density":0.3333333333333333`) {
+		t.Fatalf("expected realized-scope density 1/3 (2 edges / (3*2)), got %s", realizedBody)
+	}
+
+	// agent2 only appears via the outsourced edge, so under the realized
+	// scope it drops to degree 0 and no longer matches "degree>0".
+	req = httptest.NewRequest(http.MethodGet, "/api/filter?q="+url.QueryEscape("role=agent AND degree>0")+"&scope=realized", nil)
+	rec = httptest.NewRecorder()
+	handleFilter(rec, req)
+	degreeBody, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	if strings.Contains(string(degreeBody), `"agent2"`) {
+		t.Fatalf("expected agent2 to drop out once outsourced edges are excluded, got %s", degreeBody)
+	}
+	if !strings.Contains(string(degreeBody), `"agent1"`) {
+		t.Fatalf("expected agent1 (degree 2 via bid+assigned) to still match, got %s", degreeBody)
+	}
+}
+
+func TestNetworkDensityHandlesTrivialNetworks(t *testing.T) {
+	if got := networkDensity(Network{}); got != 0 {
+		t.Fatalf("expected density 0 for an empty network, got %v", got)
+	}
+	if got := networkDensity(Network{Nodes: []Node{{ID: "a"}}}); got != 0 {
+		t.Fatalf("expected density 0 for a single-node network, got %v", got)
+	}
+}
+
+func TestStaticFileSystemFallsBackToEmbedded(t *testing.T) {
+	missingDir := filepath.Join(t.TempDir(), "does-not-exist")
+
+	fsys, err := staticFileSystem(missingDir)
+	if err != nil {
+		t.Fatalf("staticFileSystem failed: %v", err)
+	}
+	f, err := fsys.Open("index.html")
+	if err != nil {
+		t.Fatalf("expected embedded index.html to be served, got error: %v", err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("failed to read embedded index.html: %v", err)
+	}
+	if !strings.Contains(string(content), "SWEChain Visualization") {
+		t.Fatalf("expected embedded default content, got %q", string(content))
+	}
+}
+
+func fixtureLopsidedMarketNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"},
+			{ID: "agent2", Type: "agent"},
+			{ID: "agent3", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+			{ID: "task2", Type: "issue"},
+			{ID: "task3", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 1000},
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "agent2", Target: "task2", Type: "bid", BidValue: 1},
+			{Source: "task2", Target: "agent2", Type: "assigned"},
+			{Source: "agent3", Target: "task3", Type: "bid", BidValue: 1},
+			{Source: "task3", Target: "agent3", Type: "assigned"},
+		},
+	}
+}
+
+func TestGiniCoefficientOfEvenSplitIsZero(t *testing.T) {
+	if got := giniCoefficient([]float64{50, 50, 50}); got != 0 {
+		t.Fatalf("expected 0 for an even split, got %v", got)
+	}
+	if got := giniCoefficient(nil); got != 0 {
+		t.Fatalf("expected 0 for no values, got %v", got)
+	}
+}
+
+func TestHHIIndexOfEvenSplitIsMinimal(t *testing.T) {
+	// Four equal shares: HHI = 4 * (25^2) = 2500.
+	if got := hhiIndex([]float64{50, 50, 50, 50}); got != 2500 {
+		t.Fatalf("expected 2500 for a four-way even split, got %v", got)
+	}
+	if got := hhiIndex(nil); got != 0 {
+		t.Fatalf("expected 0 for no values, got %v", got)
+	}
+}
+
+func TestHHIIndexOfMonopolyIsMaximal(t *testing.T) {
+	if got := hhiIndex([]float64{100}); got != 10000 {
+		t.Fatalf("expected 10000 for a single value, got %v", got)
+	}
+}
+
+func TestHHIIndexExcludesNonPositiveValues(t *testing.T) {
+	// agent3's 0 share shouldn't count toward the total or the sum of squares.
+	if got := hhiIndex([]float64{50, 50, 0}); got != 5000 {
+		t.Fatalf("expected 0-value entries excluded (5000 for the remaining even split), got %v", got)
+	}
+	if got := hhiIndex([]float64{0, 0}); got != 0 {
+		t.Fatalf("expected 0 when no value is positive, got %v", got)
+	}
+}
+
+func TestCalculateTheilIndexOfEvenSplitIsZero(t *testing.T) {
+	if got := calculateTheilIndex([]float64{50, 50, 50}); got != 0 {
+		t.Fatalf("expected 0 for an even split, got %v", got)
+	}
+	if got := calculateTheilIndex(nil); got != 0 {
+		t.Fatalf("expected 0 for no values, got %v", got)
+	}
+	if got := calculateTheilIndex([]float64{0, 0}); got != 0 {
+		t.Fatalf("expected 0 for an all-zero split, got %v", got)
+	}
+	if got := calculateTheilIndex([]float64{100}); got != 0 {
+		t.Fatalf("expected 0 for a single value, got %v", got)
+	}
+}
+
+func TestCalculateTheilIndexOfUnevenSplitIsPositive(t *testing.T) {
+	if got := calculateTheilIndex([]float64{100, 0}); got <= 0 {
+		t.Fatalf("expected a positive Theil index for an uneven split, got %v", got)
+	}
+}
+
+func TestCalculateAtkinsonIndexOfEvenSplitIsZero(t *testing.T) {
+	if got := calculateAtkinsonIndex([]float64{50, 50, 50}, 0.5); math.Abs(got) > 1e-9 {
+		t.Fatalf("expected ~0 for an even split, got %v", got)
+	}
+	if got := calculateAtkinsonIndex(nil, 0.5); got != 0 {
+		t.Fatalf("expected 0 for no values, got %v", got)
+	}
+	if got := calculateAtkinsonIndex([]float64{0, 0}, 0.5); got != 0 {
+		t.Fatalf("expected 0 for an all-zero split, got %v", got)
+	}
+	if got := calculateAtkinsonIndex([]float64{100}, 0.5); got != 0 {
+		t.Fatalf("expected 0 for a single value, got %v", got)
+	}
+}
+
+func TestCalculateAtkinsonIndexOfUnevenSplitIsPositive(t *testing.T) {
+	if got := calculateAtkinsonIndex([]float64{100, 0}, 0.5); got <= 0 || got >= 1 {
+		t.Fatalf("expected an Atkinson index strictly between 0 and 1 for an uneven split, got %v", got)
+	}
+}
+
+func TestCalculateAtkinsonIndexHandlesEpsilonOfOne(t *testing.T) {
+	if got := calculateAtkinsonIndex([]float64{100, 0}, 1); got != 1 {
+		t.Fatalf("expected the geometric mean of a distribution containing 0 to yield 1 at epsilon=1, got %v", got)
+	}
+	if got := calculateAtkinsonIndex([]float64{50, 50}, 1); got != 0 {
+		t.Fatalf("expected 0 for an even split at epsilon=1, got %v", got)
+	}
+}
+
+func TestHandleMarketMetricsIncludesHHI(t *testing.T) {
+	setNetwork(Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"}, {ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"}, {ID: "task2", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 50},
+			{Source: "task2", Target: "agent2", Type: "assigned"},
+			{Source: "agent2", Target: "task2", Type: "bid", BidValue: 50},
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/market-metrics", nil)
+	rec := httptest.NewRecorder()
+	handleMarketMetrics(rec, req)
+
+	var resp marketMetricsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.HHI != 5000 {
+		t.Fatalf("expected HHI 5000 for a two-way even split of won value, got %v", resp.HHI)
+	}
+	if resp.TheilIndex != 0 {
+		t.Fatalf("expected Theil index 0 for a two-way even split of won value, got %v", resp.TheilIndex)
+	}
+	if math.Abs(resp.AtkinsonIndex) > 1e-9 {
+		t.Fatalf("expected Atkinson index ~0 for a two-way even split of won value, got %v", resp.AtkinsonIndex)
+	}
+}
+
+func TestCalculateLorenzCurveOfEmptyInputIsJustTheOrigin(t *testing.T) {
+	got := calculateLorenzCurve(nil)
+	if len(got) != 1 || got[0] != (lorenzPoint{}) {
+		t.Fatalf("expected only the origin point for no values, got %+v", got)
+	}
+}
+
+func TestCalculateLorenzCurveOfEvenSplitIsTheDiagonal(t *testing.T) {
+	got := calculateLorenzCurve([]float64{50, 50, 50, 50})
+	if len(got) != 5 {
+		t.Fatalf("expected the origin plus one point per value, got %+v", got)
+	}
+	for i, p := range got {
+		wantShare := float64(i) / 4
+		if p.PopulationShare != wantShare || p.ValueShare != wantShare {
+			t.Fatalf("expected point %d on the diagonal at %v, got %+v", i, wantShare, p)
+		}
+	}
+}
+
+func TestCalculateLorenzCurveOfMonopolyBowsToTheAxis(t *testing.T) {
+	got := calculateLorenzCurve([]float64{0, 0, 0, 100})
+	last := got[len(got)-1]
+	if last.PopulationShare != 1 || last.ValueShare != 1 {
+		t.Fatalf("expected the curve to close at (1, 1), got %+v", last)
+	}
+	if got[3].ValueShare != 0 {
+		t.Fatalf("expected the three zero-value agents to hold no share of value, got %+v", got[3])
+	}
+}
+
+func TestCalculateLorenzCurveOfAllZeroInputHasNoValueShare(t *testing.T) {
+	got := calculateLorenzCurve([]float64{0, 0})
+	for _, p := range got {
+		if p.ValueShare != 0 {
+			t.Fatalf("expected 0 value share throughout an all-zero distribution, got %+v", got)
+		}
+	}
+}
+
+func TestHandleLorenzCurveDefaultsToWinsBasis(t *testing.T) {
+	setNetwork(Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"}, {ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"}, {ID: "task2", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 90},
+			{Source: "agent2", Target: "task1", Type: "bid", BidValue: 10},
+			{Source: "task2", Target: "agent2", Type: "assigned"},
+			{Source: "agent2", Target: "task2", Type: "bid", BidValue: 10},
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/lorenz-curve", nil)
+	rec := httptest.NewRecorder()
+	handleLorenzCurve(rec, req)
+
+	var resp lorenzCurveResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Basis != "wins" {
+		t.Fatalf("expected the default basis to be wins, got %q", resp.Basis)
+	}
+	if resp.Gini != giniCoefficient(agentWonValuesFor(getNetwork())) {
+		t.Fatalf("expected the reported Gini to match agentWonValuesFor's Gini, got %v", resp.Gini)
+	}
+}
+
+func TestHandleLorenzCurveBidsBasisCountsEveryBidWinOrLose(t *testing.T) {
+	setNetwork(Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"}, {ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 90},
+			{Source: "agent2", Target: "task1", Type: "bid", BidValue: 10},
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/lorenz-curve?basis=bids", nil)
+	rec := httptest.NewRecorder()
+	handleLorenzCurve(rec, req)
+
+	var resp lorenzCurveResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Basis != "bids" {
+		t.Fatalf("expected basis bids, got %q", resp.Basis)
+	}
+	// Both agents placed exactly one bid each, so the bid-count
+	// distribution is perfectly even even though agent2 lost.
+	if resp.Gini != 0 {
+		t.Fatalf("expected Gini 0 for an even bid count, got %v", resp.Gini)
+	}
+}
+
+func TestHandleLorenzCurveRejectsUnknownBasis(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/lorenz-curve?basis=nonsense", nil)
+	rec := httptest.NewRecorder()
+	handleLorenzCurve(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown basis, got %d", rec.Code)
+	}
+}
+
+func TestGiniSubgroupDecompositionDominatedByBetweenWhenGroupsAreCleanlySeparated(t *testing.T) {
+	// Specialists all win 100, generalists all win 10: no inequality within
+	// either group, so any measured inequality must be between them.
+	specialist := []float64{100, 100, 100}
+	generalist := []float64{10, 10, 10}
+
+	got := giniSubgroupDecomposition(specialist, generalist)
+	if got.WithinSpecialist != 0 || got.WithinGeneralist != 0 {
+		t.Fatalf("expected zero within-group inequality for equal-valued groups, got %+v", got)
+	}
+	if got.Total == 0 {
+		t.Fatalf("expected nonzero total inequality across two differently-valued groups, got %+v", got)
+	}
+	if got.Between != got.Total {
+		t.Fatalf("expected the between component to account for all inequality, got %+v", got)
+	}
+}
+
+func TestGiniSubgroupDecompositionSplitsWithinInequalityByGroup(t *testing.T) {
+	// Equal group means (30 each) so the between component is 0, but the
+	// specialist group has internal spread while the generalist group doesn't.
+	specialist := []float64{10, 50}
+	generalist := []float64{30, 30}
+
+	got := giniSubgroupDecomposition(specialist, generalist)
+	if got.Between != 0 {
+		t.Fatalf("expected zero between-group inequality for equal group means, got %+v", got)
+	}
+	if got.WithinSpecialist == 0 {
+		t.Fatalf("expected nonzero within-specialist inequality, got %+v", got)
+	}
+	if got.WithinGeneralist != 0 {
+		t.Fatalf("expected zero within-generalist inequality for an even split, got %+v", got)
+	}
+}
+
+func TestHandleGiniDecompositionSplitsWonValueBySpecialistStatus(t *testing.T) {
+	setNetwork(Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent", Specialities: []Speciality{{Name: "UI/UX", Weight: 1}}},
+			{ID: "agent2", Type: "agent", Specialities: []Speciality{{Name: "UI/UX", Weight: 1}}},
+			{ID: "agent3", Type: "agent"},
+			{ID: "agent4", Type: "agent"},
+			{ID: "task1", Type: "issue"}, {ID: "task2", Type: "issue"},
+			{ID: "task3", Type: "issue"}, {ID: "task4", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 100},
+			{Source: "task2", Target: "agent2", Type: "assigned"},
+			{Source: "agent2", Target: "task2", Type: "bid", BidValue: 100},
+			{Source: "task3", Target: "agent3", Type: "assigned"},
+			{Source: "agent3", Target: "task3", Type: "bid", BidValue: 10},
+			{Source: "task4", Target: "agent4", Type: "assigned"},
+			{Source: "agent4", Target: "task4", Type: "bid", BidValue: 10},
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/gini-decomposition", nil)
+	rec := httptest.NewRecorder()
+	handleGiniDecomposition(rec, req)
+
+	var resp giniDecompositionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.WithinSpecialist != 0 || resp.WithinGeneralist != 0 {
+		t.Fatalf("expected zero within-group inequality (each group wins equal amounts), got %+v", resp)
+	}
+	if resp.Between == 0 || resp.Between != resp.Total {
+		t.Fatalf("expected the between component to account for all inequality, got %+v", resp)
+	}
+}
+
+func TestHandleNeighborsSplitsIncomingAndOutgoingByEdgeDirection(t *testing.T) {
+	setNetwork(Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"}, {ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 40},
+			{Source: "agent2", Target: "task1", Type: "bid", BidValue: 30},
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/neighbors?id=task1", nil)
+	rec := httptest.NewRecorder()
+	handleNeighbors(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp neighborsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Incoming) != 2 {
+		t.Fatalf("expected task1 to have 2 incoming bids, got %+v", resp.Incoming)
+	}
+	if len(resp.Outgoing) != 1 || resp.Outgoing[0].NodeID != "agent1" || resp.Outgoing[0].Type != "assigned" {
+		t.Fatalf("expected task1's one outgoing assigned edge to agent1, got %+v", resp.Outgoing)
+	}
+}
+
+func TestHandleNeighborsReturns404ForUnknownID(t *testing.T) {
+	setNetwork(Network{Nodes: []Node{{ID: "agent1", Type: "agent"}}})
+	req := httptest.NewRequest(http.MethodGet, "/api/neighbors?id=does-not-exist", nil)
+	rec := httptest.NewRecorder()
+	handleNeighbors(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown node id, got %d", rec.Code)
+	}
+}
+
+func TestHandlePolicyComparisonLowestBidHasTheLowestTotalCost(t *testing.T) {
+	setNetwork(Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent", Specialities: []Speciality{{Name: "Security", Weight: 1}}},
+			{ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue", Speciality: &TaskSpeciality{Name: "Security"}},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 100},
+			{Source: "agent2", Target: "task1", Type: "bid", BidValue: 90},
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/policy-comparison", nil)
+	rec := httptest.NewRecorder()
+	handlePolicyComparison(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp policyComparisonResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Policies) != 3 {
+		t.Fatalf("expected 3 policies, got %+v", resp.Policies)
+	}
+
+	byPolicy := make(map[string]policyMetrics, len(resp.Policies))
+	for _, p := range resp.Policies {
+		byPolicy[p.Policy] = p
+	}
+	lowestBid, ok := byPolicy["lowest-bid"]
+	if !ok {
+		t.Fatalf("expected a lowest-bid policy entry, got %+v", resp.Policies)
+	}
+	for _, p := range resp.Policies {
+		if p.TotalCost < lowestBid.TotalCost {
+			t.Fatalf("expected lowest-bid (%v) to have the lowest total cost, but %s scored %v", lowestBid.TotalCost, p.Policy, p.TotalCost)
+		}
+	}
+	if lowestBid.TotalCost != 90 {
+		t.Fatalf("expected lowest-bid to clear task1 at agent2's cheaper bid of 90, got %v", lowestBid.TotalCost)
+	}
+	if specialist := byPolicy["specialist-preferred"]; specialist.TotalCost != 100 {
+		t.Fatalf("expected specialist-preferred to favor agent1's specialist bid of 100, got %v", specialist.TotalCost)
+	}
+}
+
+func TestHandleTaxonomyServesAllSevenCategoriesAndTheirSkills(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/taxonomy", nil)
+	rec := httptest.NewRecorder()
+	handleTaxonomy(rec, req)
+
+	var resp taxonomyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	wantCategories := []string{
+		"ApplicationLogic",
+		"ServerSideLogic",
+		"BugFixes",
+		"UI/UX",
+		"SystemWideQualityAndReliability",
+		"NewFeaturesOrEnhancements",
+		"ReliabilityImprovements",
+	}
+	if len(resp.Categories) != len(wantCategories) {
+		t.Fatalf("expected %d categories, got %d: %v", len(wantCategories), len(resp.Categories), resp.Categories)
+	}
+	for i, want := range wantCategories {
+		if resp.Categories[i] != want {
+			t.Fatalf("expected category %d to be %q, got %q", i, want, resp.Categories[i])
+		}
+		skills, ok := resp.Skills[want]
+		if !ok || len(skills) != 1 || skills[0] != want {
+			t.Fatalf("expected category %q to map to a single skill of its own name, got %v", want, skills)
+		}
+	}
+}
+
+func TestWriteGraphMLProducesValidDirectedGraphWithMappedFields(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent", Label: "Agent One", Group: "agents", Specialities: []Speciality{{Name: "UI/UX"}}, Degree: 2},
+			{ID: "task1", Type: "issue", Label: "Task <One>", Group: "tasks", Degree: 2},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 100},
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeGraphML(n, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc graphMLDocument
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Graph.EdgeDefault != "directed" {
+		t.Fatalf("expected edgedefault=\"directed\", got %q", doc.Graph.EdgeDefault)
+	}
+	if len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 2 {
+		t.Fatalf("expected 2 nodes and 2 edges, got %d nodes, %d edges", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+	if !strings.Contains(buf.String(), "Task &lt;One&gt;") {
+		t.Fatalf("expected the node label's angle brackets to be XML-escaped, got: %s", buf.String())
+	}
+
+	var agentNode *graphMLNode
+	for i := range doc.Graph.Nodes {
+		if doc.Graph.Nodes[i].ID == "agent1" {
+			agentNode = &doc.Graph.Nodes[i]
+		}
+	}
+	if agentNode == nil {
+		t.Fatalf("expected to find agent1 among nodes")
+	}
+	dataByKey := make(map[string]string)
+	for _, d := range agentNode.Data {
+		dataByKey[d.Key] = d.Value
+	}
+	if dataByKey["d3"] != "true" {
+		t.Fatalf("expected agent1's specialist data to be \"true\" since it declares a speciality, got %+v", dataByKey)
+	}
+
+	var assignedEdge *graphMLEdge
+	for i := range doc.Graph.Edges {
+		if doc.Graph.Edges[i].Source == "task1" && doc.Graph.Edges[i].Target == "agent1" {
+			assignedEdge = &doc.Graph.Edges[i]
+		}
+	}
+	if assignedEdge == nil {
+		t.Fatalf("expected to find the task1->agent1 assigned edge")
+	}
+	edgeData := make(map[string]string)
+	for _, d := range assignedEdge.Data {
+		edgeData[d.Key] = d.Value
+	}
+	if edgeData["d7"] != "100" || edgeData["d8"] != "1" {
+		t.Fatalf("expected the assigned edge to carry winning_bid=100 and bid_count=1, got %+v", edgeData)
+	}
+}
+
+func TestHandleGraphMLServesApplicationXML(t *testing.T) {
+	setNetwork(fixtureFilterNetwork())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/graphml", nil)
+	rec := httptest.NewRecorder()
+	handleGraphML(rec, req)
+
+	if ct := rec.Result().Header.Get("Content-Type"); ct != "application/xml" {
+		t.Fatalf("expected Content-Type application/xml, got %q", ct)
+	}
+	var doc graphMLDocument
+	if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("response is not valid XML: %v", err)
+	}
+}
+
+func TestDotIDQuotesIdentifiersWithIllegalCharacters(t *testing.T) {
+	if got := dotID("agent1"); got != "agent1" {
+		t.Fatalf("expected a legal identifier to pass through unquoted, got %q", got)
+	}
+	if got := dotID("agent-1"); got != `"agent-1"` {
+		t.Fatalf("expected a hyphenated ID to be quoted, got %q", got)
+	}
+	if got := dotID(`weird"id`); got != `"weird\"id"` {
+		t.Fatalf("expected an embedded quote to be escaped, got %q", got)
+	}
+}
+
+func TestWriteDOTColorsNodesAndStylesEdgesByType(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"},
+			{ID: "task-1", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task-1", Type: "bid", BidValue: 100},
+			{Source: "task-1", Target: "agent1", Type: "assigned"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDOT(n, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "digraph network {") {
+		t.Fatalf("expected a digraph header, got: %s", out)
+	}
+	if !strings.Contains(out, "agent1 [style=filled, fillcolor=lightblue]") {
+		t.Fatalf("expected agent1 filled lightblue, got: %s", out)
+	}
+	if !strings.Contains(out, `"task-1" [style=filled, fillcolor=plum]`) {
+		t.Fatalf("expected the quoted task-1 node filled plum, got: %s", out)
+	}
+	if !strings.Contains(out, `agent1 -> "task-1" [style=dashed, label="100"]`) {
+		t.Fatalf("expected a dashed, weight-labeled bid edge, got: %s", out)
+	}
+	if !strings.Contains(out, `"task-1" -> agent1 [style=bold, label="0"]`) {
+		t.Fatalf("expected a bold assigned edge, got: %s", out)
+	}
+}
+
+func TestHandleDOTServesGraphvizContentType(t *testing.T) {
+	setNetwork(fixtureFilterNetwork())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/dot", nil)
+	rec := httptest.NewRecorder()
+	handleDOT(rec, req)
+
+	if ct := rec.Result().Header.Get("Content-Type"); ct != "text/vnd.graphviz" {
+		t.Fatalf("expected Content-Type text/vnd.graphviz, got %q", ct)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "digraph network {") {
+		t.Fatalf("expected DOT output, got: %s", rec.Body.String())
+	}
+}
+
+func TestHandleReportRendersFailBadgeForHighGiniAndPassBadgeForHealthyDensity(t *testing.T) {
+	setNetwork(fixtureLopsidedMarketNetwork())
+
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	rec := httptest.NewRecorder()
+	handleReport(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "Gini Coefficient") || !strings.Contains(body, "0.66") {
+		t.Fatalf("expected a Gini row showing 0.66, got: %s", body)
+	}
+	if !strings.Contains(body, "high inequality") {
+		t.Fatalf("expected the Gini row to read \"high inequality\", got: %s", body)
+	}
+	if !strings.Contains(body, "Network Density") || !strings.Contains(body, "0.200") {
+		t.Fatalf("expected a Network Density row showing 0.200, got: %s", body)
+	}
+	if !strings.Contains(body, "healthy market depth") {
+		t.Fatalf("expected the density row to read \"healthy market depth\", got: %s", body)
+	}
+
+	giniRow := body[strings.Index(body, "Gini Coefficient"):strings.Index(body, "Network Density")]
+	if !strings.Contains(giniRow, "badge-fail") {
+		t.Fatalf("expected the Gini row to carry a fail badge, got: %s", giniRow)
+	}
+	densityRow := body[strings.Index(body, "Network Density"):]
+	if !strings.Contains(densityRow, "badge-pass") {
+		t.Fatalf("expected the density row to carry a pass badge, got: %s", densityRow)
+	}
+}
+
+func TestBadgeForRespectsDirectionOfHigherIsWorse(t *testing.T) {
+	if got := badgeFor(0.7, 0.4, 0.6, true); got != "fail" {
+		t.Fatalf("expected fail for a high value when higher is worse, got %q", got)
+	}
+	if got := badgeFor(0.01, 0.1, 0.02, false); got != "fail" {
+		t.Fatalf("expected fail for a low value when lower is worse, got %q", got)
+	}
+	if got := badgeFor(0.5, 0.1, 0.02, false); got != "pass" {
+		t.Fatalf("expected pass for a comfortably high value when lower is worse, got %q", got)
+	}
+}
+
+func TestNormalizePricesZScoreYieldsMeanAroundZero(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "task1", Type: "issue", PriceMin: 100, PriceMax: 200},
+			{ID: "task2", Type: "issue", PriceMin: 500, PriceMax: 1000},
+			{ID: "task3", Type: "issue", PriceMin: 10000, PriceMax: 20000},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 150},
+			{Source: "agent2", Target: "task2", Type: "bid", BidValue: 750},
+			{Source: "agent3", Target: "task3", Type: "bid", BidValue: 15000},
+		},
+	}
+
+	normalizePrices(&n, "zscore")
+
+	var sum float64
+	for _, e := range n.Edges {
+		sum += e.NormalizedBidValue
+	}
+	mean := sum / float64(len(n.Edges))
+	if mean < -1e-9 || mean > 1e-9 {
+		t.Fatalf("expected z-score normalized bid values to have mean ~0, got %v", mean)
+	}
+
+	// Raw values are left untouched alongside the normalized ones.
+	if n.Edges[0].BidValue != 150 {
+		t.Fatalf("expected raw BidValue to survive normalization, got %v", n.Edges[0].BidValue)
+	}
+}
+
+func TestNormalizePricesMinMaxBoundsPricesToUnitInterval(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "task1", Type: "issue", PriceMin: 100, PriceMax: 200},
+			{ID: "task2", Type: "issue", PriceMin: 300, PriceMax: 400},
+		},
+	}
+
+	normalizePrices(&n, "minmax")
+
+	if n.Nodes[0].NormalizedPriceMin != 0 || n.Nodes[1].NormalizedPriceMin != 1 {
+		t.Fatalf("expected min-max normalized PriceMin values of 0 and 1, got %v and %v", n.Nodes[0].NormalizedPriceMin, n.Nodes[1].NormalizedPriceMin)
+	}
+}
+
+func TestEffectiveBidValueUsesRawWhenNormalizationDisabled(t *testing.T) {
+	orig := priceNormalization
+	priceNormalization = "none"
+	defer func() { priceNormalization = orig }()
+
+	e := Edge{BidValue: 42, NormalizedBidValue: -1}
+	if got := effectiveBidValue(e); got != 42 {
+		t.Fatalf("expected raw BidValue when normalization is disabled, got %v", got)
+	}
+}
+
+func TestEffectiveBidValueUsesNormalizedWhenEnabled(t *testing.T) {
+	orig := priceNormalization
+	priceNormalization = "zscore"
+	defer func() { priceNormalization = orig }()
+
+	e := Edge{BidValue: 42, NormalizedBidValue: -1}
+	if got := effectiveBidValue(e); got != -1 {
+		t.Fatalf("expected normalized bid value when normalization is enabled, got %v", got)
+	}
+}
+
+func TestStaticFileSystemPrefersOnDiskDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("custom dev assets"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	fsys, err := staticFileSystem(dir)
+	if err != nil {
+		t.Fatalf("staticFileSystem failed: %v", err)
+	}
+	f, err := fsys.Open("index.html")
+	if err != nil {
+		t.Fatalf("expected on-disk index.html to be served, got error: %v", err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("failed to read on-disk index.html: %v", err)
+	}
+	if string(content) != "custom dev assets" {
+		t.Fatalf("expected on-disk content to take priority, got %q", string(content))
+	}
+}
+
+func fixtureTruthDiffNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent"},
+			{ID: "agent2", Type: "agent"},
+			{ID: "task1", Type: "issue"},
+			{ID: "task2", Type: "issue"},
+			{ID: "task3", Type: "issue"},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "task2", Target: "agent1", Type: "assigned"},
+			{Source: "task3", Target: "agent2", Type: "assigned"},
+		},
+	}
+}
+
+func TestDiffAssignmentsClassifiesCorrectMissedAndSpuriousPerAgent(t *testing.T) {
+	n := fixtureTruthDiffNetwork()
+	truth := map[string]string{
+		"task1": "agent1",
+		"task2": "agent2",
+		"task3": "agent2",
+	}
+
+	rows := diffAssignments(n, truth)
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 agents, got %d", len(rows))
+	}
+
+	byAgent := make(map[string]truthDiffAgent)
+	for _, row := range rows {
+		byAgent[row.AgentID] = row
+	}
+
+	a1 := byAgent["agent1"]
+	if !reflect.DeepEqual(a1.Correct, []string{"task1"}) {
+		t.Fatalf("expected agent1 correct [task1], got %v", a1.Correct)
+	}
+	if !reflect.DeepEqual(a1.Spurious, []string{"task2"}) {
+		t.Fatalf("expected agent1 spurious [task2], got %v", a1.Spurious)
+	}
+	if len(a1.Missed) != 0 {
+		t.Fatalf("expected agent1 to have no missed tasks, got %v", a1.Missed)
+	}
+	if a1.Precision != 0.5 {
+		t.Fatalf("expected agent1 precision 0.5, got %v", a1.Precision)
+	}
+	if a1.Recall != 1 {
+		t.Fatalf("expected agent1 recall 1, got %v", a1.Recall)
+	}
+
+	a2 := byAgent["agent2"]
+	if !reflect.DeepEqual(a2.Correct, []string{"task3"}) {
+		t.Fatalf("expected agent2 correct [task3], got %v", a2.Correct)
+	}
+	if !reflect.DeepEqual(a2.Missed, []string{"task2"}) {
+		t.Fatalf("expected agent2 missed [task2], got %v", a2.Missed)
+	}
+	if len(a2.Spurious) != 0 {
+		t.Fatalf("expected agent2 to have no spurious tasks, got %v", a2.Spurious)
+	}
+	if a2.Precision != 1 {
+		t.Fatalf("expected agent2 precision 1, got %v", a2.Precision)
+	}
+	if a2.Recall != 0.5 {
+		t.Fatalf("expected agent2 recall 0.5, got %v", a2.Recall)
+	}
+}
+
+func TestDiffAssignmentsHandlesEmptyPredictionsAndTruth(t *testing.T) {
+	n := Network{Nodes: []Node{{ID: "agent1", Type: "agent"}}}
+	rows := diffAssignments(n, map[string]string{"task1": "agent1"})
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 agent, got %d", len(rows))
+	}
+	if rows[0].Precision != 0 {
+		t.Fatalf("expected precision 0 with no predictions, got %v", rows[0].Precision)
+	}
+	if rows[0].Recall != 0 {
+		t.Fatalf("expected recall 0, got %v", rows[0].Recall)
+	}
+	if !reflect.DeepEqual(rows[0].Missed, []string{"task1"}) {
+		t.Fatalf("expected missed [task1], got %v", rows[0].Missed)
+	}
+}
+
+func TestHandleTruthDiffServesPerAgentDiffFromFixtureFile(t *testing.T) {
+	setNetwork(fixtureTruthDiffNetwork())
+
+	dir := t.TempDir()
+	truthPath := filepath.Join(dir, "truth.json")
+	truthJSON := `{"task1": "agent1", "task2": "agent2", "task3": "agent2"}`
+	if err := os.WriteFile(truthPath, []byte(truthJSON), 0644); err != nil {
+		t.Fatalf("failed to write fixture truth file: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/truth-diff?truth="+url.QueryEscape(truthPath), nil)
+	rec := httptest.NewRecorder()
+	handleTruthDiff(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var got truthDiffResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got.Agents) != 2 {
+		t.Fatalf("expected 2 agents in response, got %d", len(got.Agents))
+	}
+}
+
+func TestHandleTruthDiffRequiresTruthQueryParam(t *testing.T) {
+	setNetwork(fixtureTruthDiffNetwork())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/truth-diff", nil)
+	rec := httptest.NewRecorder()
+	handleTruthDiff(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 without truth param, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleTruthDiffReturns400ForMissingTruthFile(t *testing.T) {
+	setNetwork(fixtureTruthDiffNetwork())
+
+	missing := filepath.Join(t.TempDir(), "does-not-exist.json")
+	req := httptest.NewRequest(http.MethodGet, "/api/truth-diff?truth="+url.QueryEscape(missing), nil)
+	rec := httptest.NewRecorder()
+	handleTruthDiff(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing truth file, got %d", resp.StatusCode)
+	}
+}
+
+func TestBuildCytoscapeResponseGeneratesStableEdgeIDsAndPreservesFields(t *testing.T) {
+	n := Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent", Label: "Agent One"},
+			{ID: "task1", Type: "issue", Label: "Task One"},
+		},
+		Edges: []Edge{
+			{Source: "task1", Target: "agent1", Type: "assigned", BidValue: 42},
+		},
+	}
+
+	resp, err := buildCytoscapeResponse(n)
+	if err != nil {
+		t.Fatalf("buildCytoscapeResponse failed: %v", err)
+	}
+
+	if len(resp.Elements.Nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(resp.Elements.Nodes))
+	}
+	if resp.Elements.Nodes[0].Data["id"] != "agent1" {
+		t.Fatalf("expected node data to preserve id, got %v", resp.Elements.Nodes[0].Data["id"])
+	}
+	if resp.Elements.Nodes[0].Data["label"] != "Agent One" {
+		t.Fatalf("expected node data to preserve label, got %v", resp.Elements.Nodes[0].Data["label"])
+	}
+
+	if len(resp.Elements.Edges) != 1 {
+		t.Fatalf("expected 1 edge, got %d", len(resp.Elements.Edges))
+	}
+	edge := resp.Elements.Edges[0].Data
+	if edge["id"] != "task1_agent1_assigned" {
+		t.Fatalf("expected synthesized edge id task1_agent1_assigned, got %v", edge["id"])
+	}
+	if edge["source"] != "task1" || edge["target"] != "agent1" {
+		t.Fatalf("expected edge data to preserve source/target, got %v/%v", edge["source"], edge["target"])
+	}
+	if edge["bid_value"] != float64(42) {
+		t.Fatalf("expected edge data to preserve bid_value, got %v", edge["bid_value"])
+	}
+}
+
+func TestHandleCytoscapeServesElementsShape(t *testing.T) {
+	setNetwork(fixtureFilterNetwork())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/cytoscape", nil)
+	rec := httptest.NewRecorder()
+	handleCytoscape(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected application/json content type, got %q", ct)
+	}
+
+	var got cytoscapeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	base := getNetwork()
+	if len(got.Elements.Nodes) != len(base.Nodes) {
+		t.Fatalf("expected %d nodes, got %d", len(base.Nodes), len(got.Elements.Nodes))
+	}
+	if len(got.Elements.Edges) != len(base.Edges) {
+		t.Fatalf("expected %d edges, got %d", len(base.Edges), len(got.Elements.Edges))
+	}
+}
+
+func TestHandleDataStillServesOriginalShape(t *testing.T) {
+	setNetwork(fixtureFilterNetwork())
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	rec := httptest.NewRecorder()
+	handleData(rec, req)
+
+	var got dataResponse
+	if err := json.NewDecoder(rec.Result().Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	base := getNetwork()
+	if len(got.Nodes) != len(base.Nodes) || len(got.Edges) != len(base.Edges) {
+		t.Fatalf("expected /data to keep serving the original flat shape unchanged")
+	}
+}
+
+func fixtureTopEdgesNetwork() Network {
+	return Network{
+		Nodes: []Node{
+			{ID: "agent1", Type: "agent", Label: "Agent One"},
+			{ID: "agent2", Type: "agent", Label: "Agent Two"},
+			{ID: "task1", Type: "issue", Label: "Task One"},
+			{ID: "task2", Type: "issue", Label: "Task Two"},
+			{ID: "task3", Type: "issue", Label: "Task Three"},
+		},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 30},
+			{Source: "task1", Target: "agent1", Type: "assigned", BidValue: 5},
+			{Source: "agent2", Target: "task2", Type: "bid", BidValue: 30},
+			{Source: "task2", Target: "agent2", Type: "assigned", BidValue: 5},
+			{Source: "agent1", Target: "task3", Type: "bid", BidValue: 10},
+		},
+	}
+}
+
+func TestTopEdgesForSortsByWeightDescendingAndTruncates(t *testing.T) {
+	n := fixtureTopEdgesNetwork()
+	edges := topEdgesFor(n, "bid", "weight", 2)
+	if len(edges) != 2 {
+		t.Fatalf("expected 2 edges, got %d", len(edges))
+	}
+	if edges[0].Value != 30 || edges[1].Value != 30 {
+		t.Fatalf("expected the two highest-weight bids first, got %v", edges)
+	}
+	// Tie-break by source, then target.
+	if edges[0].Source != "agent1" || edges[1].Source != "agent2" {
+		t.Fatalf("expected stable tie-break by source, got %v", edges)
+	}
+}
+
+func TestTopEdgesForSortsByWinningBidAndOnlyCountsAssignedEdges(t *testing.T) {
+	n := fixtureTopEdgesNetwork()
+	edges := topEdgesFor(n, "assigned", "winning_bid", -1)
+	if len(edges) != 2 {
+		t.Fatalf("expected 2 assigned edges, got %d", len(edges))
+	}
+	for _, e := range edges {
+		if e.Value != 30 {
+			t.Fatalf("expected winning_bid to resolve to the matching bid's value (30), got %v for %+v", e.Value, e)
+		}
+	}
+}
+
+func TestTopEdgesForResolvesSourceAndTargetNames(t *testing.T) {
+	n := fixtureTopEdgesNetwork()
+	edges := topEdgesFor(n, "", "weight", -1)
+	for _, e := range edges {
+		if e.SourceName == "" || e.TargetName == "" {
+			t.Fatalf("expected edge %+v to have resolved names", e)
+		}
+	}
+}
+
+func TestHandleTopEdgesRejectsInvalidSortBy(t *testing.T) {
+	setNetwork(fixtureTopEdgesNetwork())
+	req := httptest.NewRequest(http.MethodGet, "/api/top-edges?sort_by=bogus", nil)
+	rec := httptest.NewRecorder()
+	handleTopEdges(rec, req)
+	if rec.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid sort_by, got %d", rec.Result().StatusCode)
+	}
+}
+
+func TestHandleTopEdgesServesFilteredAndSortedEdges(t *testing.T) {
+	setNetwork(fixtureTopEdgesNetwork())
+	req := httptest.NewRequest(http.MethodGet, "/api/top-edges?n=1&type=bid&sort_by=weight", nil)
+	rec := httptest.NewRecorder()
+	handleTopEdges(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var got topEdgesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got.Edges) != 1 {
+		t.Fatalf("expected 1 edge, got %d", len(got.Edges))
+	}
+	if got.Edges[0].Value != 30 {
+		t.Fatalf("expected the top bid (30), got %v", got.Edges[0].Value)
+	}
+}
+
+func TestValidateNetworkReferencesFindsDanglingSourceAndTarget(t *testing.T) {
+	n := Network{
+		Nodes: []Node{{ID: "agent1", Type: "agent"}},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task-missing", Type: "bid"},
+			{Source: "agent-missing", Target: "agent1", Type: "bid"},
+		},
+	}
+	dangling := validateNetworkReferences(n)
+	if len(dangling) != 2 {
+		t.Fatalf("expected 2 dangling references, got %d: %v", len(dangling), dangling)
+	}
+}
+
+func TestValidateNetworkReferencesReturnsEmptyForValidNetwork(t *testing.T) {
+	n := Network{
+		Nodes: []Node{{ID: "agent1", Type: "agent"}, {ID: "task1", Type: "issue"}},
+		Edges: []Edge{{Source: "agent1", Target: "task1", Type: "bid"}},
+	}
+	if dangling := validateNetworkReferences(n); len(dangling) != 0 {
+		t.Fatalf("expected no dangling references, got %v", dangling)
+	}
+}
+
+func TestHandleLoadNetworkRejectsNonPostMethod(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/load", nil)
+	rec := httptest.NewRecorder()
+	handleLoadNetwork(rec, req)
+	if rec.Result().StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Result().StatusCode)
+	}
+}
+
+func TestHandleLoadNetworkRejectsDanglingReferencesWithList(t *testing.T) {
+	body := `{"nodes":[{"id":"agent1","type":"agent"}],"edges":[{"source":"agent1","target":"task-missing","type":"bid"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/load", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handleLoadNetwork(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	var got loadNetworkErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode error response: %v", err)
+	}
+	if len(got.DanglingLinks) != 1 {
+		t.Fatalf("expected 1 dangling link listed, got %v", got.DanglingLinks)
+	}
+}
+
+func TestHandleLoadNetworkSwapsNetworkAndReturnsCounts(t *testing.T) {
+	setNetwork(fixtureFilterNetwork())
+
+	body := `{"nodes":[{"id":"a1","type":"agent"},{"id":"t1","type":"issue"}],"edges":[{"source":"a1","target":"t1","type":"bid","bid_value":5}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/load", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handleLoadNetwork(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var got loadNetworkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Nodes != 2 || got.Edges != 1 {
+		t.Fatalf("expected counts {2,1}, got %+v", got)
+	}
+	if live := getNetwork(); len(live.Nodes) != 2 || live.Nodes[0].ID != "a1" {
+		t.Fatalf("expected the live network to be swapped, got %+v", live)
+	}
+}
+
+// TestConcurrentLoadAndReadsDontRace fires POST /api/load and a mix of
+// read-only handlers at the shared network concurrently. It exists to be
+// run with -race: every read handler must go through getNetwork rather
+// than the currentNetwork package var directly, or a concurrent load races
+// it.
+func TestConcurrentLoadAndReadsDontRace(t *testing.T) {
+	setNetwork(fixtureFilterNetwork())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"nodes":[{"id":"a%d","type":"agent"}],"edges":[]}`, i)
+			req := httptest.NewRequest(http.MethodPost, "/api/load", strings.NewReader(body))
+			handleLoadNetwork(httptest.NewRecorder(), req)
+		}(i)
+
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			handleData(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/data", nil))
+		}()
+		go func() {
+			defer wg.Done()
+			handleMarketMetrics(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/market-metrics", nil))
+		}()
+		go func() {
+			defer wg.Done()
+			handleWhatIf(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/what-if", strings.NewReader(`{}`)))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDiffNetworksReportsAddedRemovedAndChangedLinks(t *testing.T) {
+	before := Network{
+		Nodes: []Node{{ID: "agent1", Type: "agent"}, {ID: "task1", Type: "issue"}, {ID: "task2", Type: "issue"}},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 50},
+			{Source: "task2", Target: "agent1", Type: "assigned"},
+			{Source: "agent1", Target: "task2", Type: "bid", BidValue: 30},
+		},
+	}
+	after := Network{
+		Nodes: []Node{{ID: "agent1", Type: "agent"}, {ID: "task1", Type: "issue"}, {ID: "agent2", Type: "agent"}},
+		Edges: []Edge{
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 75},
+			{Source: "agent1", Target: "agent2", Type: "bid", BidValue: 10},
+		},
+	}
+
+	diff := diffNetworks(before, after)
+
+	if len(diff.NodesAdded) != 1 || diff.NodesAdded[0].ID != "agent2" {
+		t.Fatalf("expected agent2 added, got %+v", diff.NodesAdded)
+	}
+	if len(diff.NodesRemoved) != 1 || diff.NodesRemoved[0].ID != "task2" {
+		t.Fatalf("expected task2 removed, got %+v", diff.NodesRemoved)
+	}
+	if len(diff.LinksAdded) != 1 || diff.LinksAdded[0].Target != "agent2" {
+		t.Fatalf("expected the new agent1->agent2 bid added, got %+v", diff.LinksAdded)
+	}
+	if len(diff.LinksRemoved) != 2 {
+		t.Fatalf("expected the agent1->task2 bid and task2->agent1 assignment removed, got %+v", diff.LinksRemoved)
+	}
+	if len(diff.LinksChanged) != 1 {
+		t.Fatalf("expected exactly 1 changed link, got %+v", diff.LinksChanged)
+	}
+	change := diff.LinksChanged[0]
+	if change.Source != "agent1" || change.Target != "task1" || change.WeightBefore != 50 || change.WeightAfter != 75 {
+		t.Fatalf("expected agent1->task1 weight to change 50->75, got %+v", change)
+	}
+}
+
+func TestDiffNetworksReportsWinningBidChangeForAssignedLinks(t *testing.T) {
+	before := Network{
+		Nodes: []Node{{ID: "agent1", Type: "agent"}, {ID: "task1", Type: "issue"}},
+		Edges: []Edge{
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 100},
+		},
+	}
+	after := Network{
+		Nodes: []Node{{ID: "agent1", Type: "agent"}, {ID: "task1", Type: "issue"}},
+		Edges: []Edge{
+			{Source: "task1", Target: "agent1", Type: "assigned"},
+			{Source: "agent1", Target: "task1", Type: "bid", BidValue: 140},
+		},
+	}
+
+	diff := diffNetworks(before, after)
+
+	var assignedChange *networkDiffLinkChange
+	for i, c := range diff.LinksChanged {
+		if c.Type == "assigned" {
+			assignedChange = &diff.LinksChanged[i]
+		}
+	}
+	if assignedChange == nil {
+		t.Fatalf("expected the assigned link's winning_bid change to be reported, got %+v", diff.LinksChanged)
+	}
+	if assignedChange.WinningBidBefore != 100 || assignedChange.WinningBidAfter != 140 {
+		t.Fatalf("expected winning_bid to change 100->140, got %+v", assignedChange)
+	}
+}
+
+func TestHandleDiffRejectsNonPostMethod(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/diff", nil)
+	rec := httptest.NewRecorder()
+	handleDiff(rec, req)
+	if rec.Result().StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Result().StatusCode)
+	}
+}
+
+func TestHandleDiffRejectsBothBeforeAndBaselinePath(t *testing.T) {
+	body := `{"before":{"nodes":[],"edges":[]},"baseline_path":"/tmp/whatever.json"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/diff", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handleDiff(rec, req)
+	if rec.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Result().StatusCode)
+	}
+}
+
+func TestHandleDiffComparesLiveNetworkAgainstBaselinePathByDefault(t *testing.T) {
+	setNetwork(Network{
+		Nodes: []Node{{ID: "agent1", Type: "agent"}, {ID: "task1", Type: "issue"}},
+		Edges: []Edge{{Source: "agent1", Target: "task1", Type: "bid", BidValue: 20}},
+	})
+
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baseline.json")
+	baseline := Network{Nodes: []Node{{ID: "agent1", Type: "agent"}}, Edges: []Edge{}}
+	data, err := json.Marshal(baseline)
+	if err != nil {
+		t.Fatalf("failed to marshal baseline fixture: %v", err)
+	}
+	if err := os.WriteFile(baselinePath, data, 0644); err != nil {
+		t.Fatalf("failed to write baseline fixture: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"baseline_path":%q}`, baselinePath)
+	req := httptest.NewRequest(http.MethodPost, "/api/diff", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handleDiff(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var got networkDiffResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got.NodesAdded) != 1 || got.NodesAdded[0].ID != "task1" {
+		t.Fatalf("expected task1 reported added relative to the baseline, got %+v", got.NodesAdded)
+	}
+	if len(got.LinksAdded) != 1 {
+		t.Fatalf("expected the new bid edge reported added, got %+v", got.LinksAdded)
+	}
+}
+
+func TestHandleDiffReturns400ForUnreadableBaselinePath(t *testing.T) {
+	body := `{"baseline_path":"/nonexistent/baseline.json"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/diff", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handleDiff(rec, req)
+	if rec.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Result().StatusCode)
+	}
+}